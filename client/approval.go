@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultApprovalTimeout = 2 * time.Minute // 默认审批超时时间
+
+// pendingApproval 等待审批的工具调用
+type pendingApproval struct {
+	ID        string    `json:"id"`
+	ToolName  string    `json:"tool_name"`
+	Arguments string    `json:"arguments"`
+	CreatedAt time.Time `json:"created_at"`
+
+	decision chan approvalDecision
+}
+
+// approvalDecision 审批结果
+type approvalDecision struct {
+	approved bool
+	approver string
+}
+
+// ApprovalBroker 管理危险工具调用的人工审批，支持终端输入和webhook两种审批方式
+type ApprovalBroker struct {
+	timeout   time.Duration
+	token     string        // webhook审批令牌，为空时禁用webhook审批
+	userInput <-chan string // 终端输入
+
+	mu      sync.Mutex
+	seq     int
+	pending map[string]*pendingApproval
+}
+
+// NewApprovalBroker 创建审批管理器
+// APPROVAL_TIMEOUT 设置审批超时时间，APPROVAL_TOKEN 设置webhook审批令牌
+func NewApprovalBroker(userInput <-chan string) *ApprovalBroker {
+	broker := &ApprovalBroker{
+		timeout:   defaultApprovalTimeout,
+		token:     os.Getenv("APPROVAL_TOKEN"),
+		userInput: userInput,
+		pending:   make(map[string]*pendingApproval),
+	}
+	if v, err := time.ParseDuration(os.Getenv("APPROVAL_TIMEOUT")); err == nil && v > 0 {
+		broker.timeout = v
+	}
+	return broker
+}
+
+// Waiting 是否有等待审批的调用
+func (b *ApprovalBroker) Waiting() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending) > 0
+}
+
+// Approve 暂停工具调用，等待终端用户或webhook审批
+func (b *ApprovalBroker) Approve(ctx context.Context, toolName, argumentsInJSON string) (bool, string) {
+	b.mu.Lock()
+	b.seq++
+	req := &pendingApproval{
+		ID:        strconv.Itoa(b.seq),
+		ToolName:  toolName,
+		Arguments: argumentsInJSON,
+		CreatedAt: time.Now(),
+		decision:  make(chan approvalDecision, 1),
+	}
+	b.pending[req.ID] = req
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, req.ID)
+		b.mu.Unlock()
+	}()
+
+	// 展示工具名称和完整参数
+	args := argumentsInJSON
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, []byte(argumentsInJSON), "    ", "  "); err == nil {
+		args = pretty.String()
+	}
+	fmt.Println()
+	fmt.Println("==================== 【操作审批】 ====================")
+	fmt.Printf("AI 请求执行可能修改资源的操作 (审批编号: %s)\n", req.ID)
+	fmt.Printf("  工具: %s\n", toolName)
+	fmt.Printf("  参数: %s\n", args)
+	if b.token != "" {
+		fmt.Println("  也可以通过 webhook 的 /approvals 接口审批")
+	}
+	fmt.Printf("是否批准执行？输入 y 批准，其他输入拒绝 (%v 内未审批将自动拒绝) [y/N]: ", b.timeout)
+
+	timer := time.NewTimer(b.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println("\n[审批] 请求已取消，操作未执行")
+		return false, "请求已取消"
+
+	case <-timer.C:
+		fmt.Println("\n[审批] 审批超时，操作未执行")
+		return false, "审批超时"
+
+	case line, ok := <-b.userInput:
+		if !ok {
+			return false, "终端输入已关闭"
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if answer == "y" || answer == "yes" || answer == "是" {
+			fmt.Println("[审批] 已批准，继续执行")
+			return true, "终端用户批准"
+		}
+		fmt.Println("[审批] 已拒绝，操作未执行")
+		return false, "终端用户拒绝"
+
+	case decision := <-req.decision:
+		if decision.approved {
+			fmt.Printf("\n[审批] %s 已通过webhook批准，继续执行\n", decision.approver)
+			return true, fmt.Sprintf("%s 通过webhook批准", decision.approver)
+		}
+		fmt.Printf("\n[审批] %s 已通过webhook拒绝，操作未执行\n", decision.approver)
+		return false, fmt.Sprintf("%s 通过webhook拒绝", decision.approver)
+	}
+}
+
+// approvalRequest webhook审批请求体
+type approvalRequest struct {
+	ID       string `json:"id"`       // 审批编号
+	Approved bool   `json:"approved"` // 是否批准
+	Approver string `json:"approver"` // 审批人
+}
+
+// HandleApprovals 处理webhook审批请求
+// GET 列出等待审批的调用，POST 提交审批结果，均需携带 Authorization: Bearer <APPROVAL_TOKEN>
+func (b *ApprovalBroker) HandleApprovals(w http.ResponseWriter, r *http.Request) {
+	if b.token == "" {
+		http.Error(w, "未配置 APPROVAL_TOKEN，webhook审批已禁用", http.StatusForbidden)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(b.token)) != 1 {
+		http.Error(w, "审批令牌无效", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		b.mu.Lock()
+		list := make([]*pendingApproval, 0, len(b.pending))
+		for _, req := range b.pending {
+			list = append(list, req)
+		}
+		b.mu.Unlock()
+		sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		var body approvalRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, fmt.Sprintf("解码请求体失败: %v", err), http.StatusBadRequest)
+			return
+		}
+		if body.Approver == "" {
+			body.Approver = "webhook"
+		}
+
+		b.mu.Lock()
+		req, ok := b.pending[body.ID]
+		b.mu.Unlock()
+		if !ok {
+			http.Error(w, fmt.Sprintf("审批编号 %s 不存在或已处理", body.ID), http.StatusNotFound)
+			return
+		}
+
+		select {
+		case req.decision <- approvalDecision{approved: body.Approved, approver: body.Approver}:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintln(w, "审批结果已提交")
+		default:
+			http.Error(w, fmt.Sprintf("审批编号 %s 已处理", body.ID), http.StatusConflict)
+		}
+
+	default:
+		http.Error(w, "无效的请求方法", http.StatusMethodNotAllowed)
+	}
+}
@@ -19,10 +19,12 @@ import (
 	"github.com/cloudwego/eino/flow/agent/react"
 	"github.com/cloudwego/eino/schema"
 	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel/attribute"
 
 	// 本地包导入
 	"mcp-devops/client/pkg/mcp"
 	"mcp-devops/client/pkg/model"
+	"mcp-devops/client/pkg/tracing"
 
 	modelEino "github.com/cloudwego/eino/components/model"
 )
@@ -78,13 +80,18 @@ type Application struct {
 	lastCommand    string
 	pendingRetry   bool
 	lastUpdateTime time.Time
-	webhookPrompts chan string // 用于从 webhook 传递 prompt 到主循环的通道
+	webhookPrompts chan string     // 用于从 webhook 传递 prompt 到主循环的通道
+	userInput      chan string     // 标准输入，主循环和操作审批共用
+	approvals      *ApprovalBroker // 危险工具调用的人工审批
+
+	shutdownTracing func(context.Context) error // 刷新并关闭追踪导出器
 }
 
 // NewApplication 创建新的应用程序实例
 func NewApplication() *Application {
 	// 创建根上下文
 	ctx, cancel := context.WithCancel(context.Background())
+	userInput := make(chan string)
 
 	return &Application{
 		ctx:            ctx,
@@ -92,6 +99,8 @@ func NewApplication() *Application {
 		dialog:         make([]*schema.Message, 0),
 		lastUpdateTime: time.Now().Add(-toolUpdateTime * time.Minute), // 强制首次更新
 		webhookPrompts: make(chan string, 10),                         // 初始化 webhook prompt 通道，带缓冲
+		userInput:      userInput,
+		approvals:      NewApprovalBroker(userInput),
 	}
 }
 
@@ -198,6 +207,18 @@ func (app *Application) Initialize() error {
 
 	fmt.Printf("使用服务器URL: %s\n", serverURL)
 
+	// 初始化链路追踪，重试初始化时不重复创建导出器
+	if app.shutdownTracing == nil {
+		shutdown, err := tracing.Setup(context.Background(), "mcp-devops-client")
+		if err != nil {
+			return fmt.Errorf("初始化链路追踪失败: %w", err)
+		}
+		app.shutdownTracing = shutdown
+		if tracing.Enabled() {
+			fmt.Println("链路追踪已启用，span通过OTLP导出")
+		}
+	}
+
 	// 清除之前可能存在的工具缓存
 	mcp.ResetToolsCache()
 
@@ -208,6 +229,7 @@ func (app *Application) Initialize() error {
 		mcp.WithMaxRetries(maxRetries),
 		mcp.WithRetryInterval(time.Duration(3)*time.Second),  // 增加重试间隔
 		mcp.WithConnectTimeout(time.Duration(8)*time.Second), // 增加连接超时
+		mcp.WithTransport(os.Getenv("MCP_SERVER_TRANSPORT")),
 	)
 
 	fmt.Println("正在连接MCP服务器...")
@@ -220,9 +242,11 @@ func (app *Application) Initialize() error {
 		return fmt.Errorf("启动MCP客户端失败: %w", err)
 	}
 
-	// 等待更长时间确保连接稳定
-	fmt.Println("MCP连接已建立，等待连接稳定...")
-	time.Sleep(5 * time.Second)
+	// SSE长连接需要等待更长时间确保连接稳定
+	if app.clientManager.Transport() == mcp.TransportSSE {
+		fmt.Println("MCP连接已建立，等待连接稳定...")
+		time.Sleep(5 * time.Second)
+	}
 
 	// 初始化系统提示
 	app.dialog = append(app.dialog, &schema.Message{
@@ -267,12 +291,16 @@ func (app *Application) Initialize() error {
 	  - 检查危险操作（删除、清理等）
 	  - 确认命令格式符合Windows要求，不要使用|或者grep等unix才有的命令
 	  - 危险命令必须按此格式确认："【安全提示】即将执行：xxx，是否继续？(Y/N)"
+	  - 调用删除、修改类工具时，客户端会暂停并向用户展示工具名称和参数请求审批，这是执行前的强制确认
+	  - 如果工具返回【操作未获批准】，说明用户拒绝或审批超时，不要重试该操作，向用户说明情况
+	  - 创建、修改、删除Kubernetes资源前，可以先传入dry_run=true演练，把返回的差异展示给用户，演练调用无需审批
 
 	2. Kubernetes命令规则：
 	  - 不要手动构造kubectl命令，使用提供的MCP工具，只有在真正无法实现的时候可以执行
 	  - 操作前先检查相关资源是否存在
 	  - 命名空间敏感操作需要先确认命名空间
 	  - 删除资源操作需要二次确认
+	  - 存在多个集群时，先使用list_clusters查看可用集群，通过cluster参数指定要操作的集群，比较不同集群时分别指定cluster调用
 
 	3. 错误处理原则：
 	  - 当命令执行失败时，用普通用户能理解的方式解释错误
@@ -354,11 +382,14 @@ func (app *Application) updateToolsWithContext(ctx context.Context, verbose bool
 	var err error
 
 	// 获取工具列表
-	app.tools, err = mcp.GetMCPTools(ctx, app.clientManager, verbose, true)
+	tools, err := mcp.GetMCPTools(ctx, app.clientManager, verbose, true)
 	if err != nil {
 		return fmt.Errorf("获取MCP工具失败: %w", err)
 	}
 
+	// 危险工具调用前需要人工审批
+	app.tools = mcp.WithApproval(ctx, tools, mcp.ApprovalPatternsFromEnv(), app.approvals.Approve)
+
 	app.lastUpdateTime = time.Now()
 
 	modelType := os.Getenv("MODEL_TYPE")
@@ -512,11 +543,17 @@ func (app *Application) processCommand(message string) bool {
 	})
 
 	// 创建独立上下文进行命令执行，避免共享app.ctx导致的上下文取消问题
-	cmdCtx := context.Background()
+	// 每条命令是一个根span，大模型调用、工具调用和服务器端的处理都挂在它下面
+	cmdCtx, span := tracing.Start(context.Background(), "agent.process_command",
+		attribute.Int("message.length", len(message)),
+	)
+	var commandErr error
+	defer func() { tracing.End(span, commandErr) }()
 
-	// 设置超时上下文，增加时间为90秒，给复杂命令更多时间
-	generateCtx, generateCancel := context.WithTimeout(cmdCtx, time.Duration(90)*time.Second)
+	// 设置超时时间为90秒，给复杂命令更多时间；等待人工审批的时间不计入超时
+	generateCtx, generateCancel := context.WithCancel(cmdCtx)
 	defer generateCancel()
+	deadline := time.Now().Add(time.Duration(90) * time.Second)
 
 	// 在执行命令前先刷新会话
 	if app.clientManager != nil {
@@ -536,7 +573,8 @@ func (app *Application) processCommand(message string) bool {
 	// 创建工作协程
 	go func() {
 		// 使用更长的超时时间进行生成
-		out, generateErr = app.runner.Generate(generateCtx, app.dialog, agent.WithComposeOptions())
+		out, generateErr = app.runner.Generate(generateCtx, app.dialog,
+			agent.WithComposeOptions(compose.WithCallbacks(tracing.Callbacks())))
 		close(done)
 	}()
 
@@ -554,6 +592,7 @@ func (app *Application) processCommand(message string) bool {
 			}
 
 			if generateErr != nil {
+				commandErr = generateErr
 				// 检查是否是连接或超时问题
 				if isConnectionError(generateErr) {
 					fmt.Printf("\n[系统] 检测到连接问题: %v\n尝试重新连接MCP服务器...\n", generateErr)
@@ -593,10 +632,18 @@ func (app *Application) processCommand(message string) bool {
 			})
 
 		case <-waitIndicator.C:
-			// 显示等待动画
-			fmt.Print(".")
+			// 等待审批期间暂停等待动画和超时计时
+			if app.approvals.Waiting() {
+				deadline = deadline.Add(time.Second)
+				continue
+			}
+
+			if time.Now().Before(deadline) {
+				// 显示等待动画
+				fmt.Print(".")
+				continue
+			}
 
-		case <-time.After(time.Duration(100) * time.Second):
 			// 超时
 			waiting = false
 			fmt.Println() // 换行，结束进度指示
@@ -605,7 +652,8 @@ func (app *Application) processCommand(message string) bool {
 			fmt.Println("AI: 处理您的请求时间过长，可能是服务器响应缓慢或命令过于复杂。请尝试更简单的命令或稍后再试。")
 
 			// 标记连接可能有问题
-			app.clientManager.MarkConnectionFailed(fmt.Errorf("命令执行超时"))
+			commandErr = fmt.Errorf("命令执行超时")
+			app.clientManager.MarkConnectionFailed(commandErr)
 			return false
 		}
 	}
@@ -656,17 +704,16 @@ func (app *Application) Start() {
 
 	fmt.Println("客户端准备就绪，请输入您的命令或等待 Webhook 告警 (输入'exit'退出):")
 
-	// 使用 buffered channel 读取标准输入，避免阻塞 select
-	userInputChan := make(chan string)
+	// 使用 channel 读取标准输入，避免阻塞 select；处理命令期间由操作审批读取
 	go func() {
 		scanner := bufio.NewScanner(os.Stdin)
 		for scanner.Scan() {
-			userInputChan <- scanner.Text()
+			app.userInput <- scanner.Text()
 		}
 		if err := scanner.Err(); err != nil {
 			fmt.Printf("[系统] 读取标准输入错误: %v\n", err)
 		}
-		close(userInputChan) // 输入结束或出错时关闭通道
+		close(app.userInput) // 输入结束或出错时关闭通道
 	}()
 
 	for {
@@ -677,7 +724,7 @@ func (app *Application) Start() {
 			fmt.Println("\n[系统] 应用上下文关闭，退出主循环。")
 			return
 
-		case message, ok := <-userInputChan: // 从标准输入读取
+		case message, ok := <-app.userInput: // 从标准输入读取
 			if !ok { // 通道关闭，意味着标准输入结束
 				fmt.Println("\n[系统] 标准输入流结束，准备退出。")
 				app.Shutdown() // 触发正常关闭流程
@@ -727,6 +774,13 @@ func (app *Application) Shutdown() {
 	if app.clientManager != nil {
 		app.clientManager.Close()
 	}
+	if app.shutdownTracing != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := app.shutdownTracing(shutdownCtx); err != nil {
+			log.Printf("[错误] 关闭链路追踪失败: %v", err)
+		}
+		cancel()
+	}
 	app.cancel()
 }
 
@@ -779,6 +833,8 @@ func main() {
 	go func() {
 		// 使用 app.handleWebhook 作为处理器
 		http.HandleFunc("/webhook", app.handleWebhook)
+		// 危险操作的webhook审批接口
+		http.HandleFunc("/approvals", app.approvals.HandleApprovals)
 		webhookAddr := ":9094" // 定义 webhook 监听地址和端口
 		fmt.Printf("[系统] Webhook 监听器启动于 %s\n", webhookAddr)
 		// 启动 HTTP 服务器
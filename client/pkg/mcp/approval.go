@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// WriteToolMarker 服务器在写工具描述前添加的标记，与服务器的 sse.WriteToolMarker 一致
+// 写工具的分类只在服务器维护，客户端据此识别会修改资源的工具
+const WriteToolMarker = "【写操作】"
+
+// WriteToolsPattern 特殊的审批模式，匹配服务器标记为写操作的全部工具
+const WriteToolsPattern = "@write"
+
+// DefaultApprovalPatterns 默认需要人工审批的工具名称模式
+var DefaultApprovalPatterns = []string{WriteToolsPattern}
+
+// ApprovalFunc 审批函数，返回是否批准以及审批说明
+type ApprovalFunc func(ctx context.Context, toolName, argumentsInJSON string) (bool, string)
+
+// ApprovalPatternsFromEnv 读取需要审批的工具名称模式
+// MCP_APPROVAL_TOOLS 为逗号分隔的工具名或通配模式（如 @write,get_secret），设置为 none 时关闭审批
+func ApprovalPatternsFromEnv() []string {
+	value := strings.TrimSpace(os.Getenv("MCP_APPROVAL_TOOLS"))
+	if value == "" {
+		return DefaultApprovalPatterns
+	}
+	if strings.EqualFold(value, "none") {
+		return nil
+	}
+
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// MatchToolPattern 检查工具名称是否匹配任一模式
+func MatchToolPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// needsApproval 检查工具是否匹配审批模式，@write匹配描述带有WriteToolMarker的工具
+func needsApproval(patterns []string, info *schema.ToolInfo) bool {
+	if MatchToolPattern(patterns, info.Name) {
+		return true
+	}
+	for _, pattern := range patterns {
+		if pattern == WriteToolsPattern && strings.HasPrefix(info.Desc, WriteToolMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDryRunArgument 工具的参数中是否声明了dry_run
+func hasDryRunArgument(info *schema.ToolInfo) bool {
+	params, err := info.ParamsOneOf.ToOpenAPIV3()
+	if err != nil || params == nil {
+		return false
+	}
+	_, ok := params.Properties["dry_run"]
+	return ok
+}
+
+// approvalTool 在调用前请求人工审批的工具包装
+type approvalTool struct {
+	tool.InvokableTool
+	name    string
+	approve ApprovalFunc
+	dryRun  bool // 工具是否声明了dry_run参数
+}
+
+// InvokableRun 审批通过后才把调用转发到MCP服务器
+func (t *approvalTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	// 演练调用不会修改资源，无需审批；只信任声明了dry_run参数的工具
+	if t.dryRun && isDryRunCall(argumentsInJSON) {
+		return t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	}
+
+	approved, reason := t.approve(ctx, t.name, argumentsInJSON)
+	if !approved {
+		// 返回文本而不是错误，让AI知道操作被拒绝并向用户说明，而不是中断整个对话
+		return fmt.Sprintf("【操作未获批准】工具 %s 的调用已被拒绝（%s），未执行任何修改。请不要重试该操作，向用户说明情况。", t.name, reason), nil
+	}
+	return t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+// isDryRunCall 检查调用参数是否指定了dry_run=true
+func isDryRunCall(argumentsInJSON string) bool {
+	var args struct {
+		DryRun bool `json:"dry_run"`
+	}
+	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+		return false
+	}
+	return args.DryRun
+}
+
+// WithApproval 为匹配模式的工具加上人工审批，其余工具原样返回
+func WithApproval(ctx context.Context, tools []tool.BaseTool, patterns []string, approve ApprovalFunc) []tool.BaseTool {
+	if len(patterns) == 0 || approve == nil {
+		return tools
+	}
+
+	wrapped := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		invokable, ok := t.(tool.InvokableTool)
+		if !ok {
+			wrapped = append(wrapped, t)
+			continue
+		}
+
+		// 无法获取工具信息时同样要求审批，避免危险工具绕过审批
+		name := "未知工具"
+		dryRun := false
+		if info, err := t.Info(ctx); err == nil {
+			if !needsApproval(patterns, info) {
+				wrapped = append(wrapped, t)
+				continue
+			}
+			name = info.Name
+			dryRun = hasDryRunArgument(info)
+		}
+
+		if Debug {
+			fmt.Printf("[调试] 工具 %s 需要人工审批\n", name)
+		}
+		wrapped = append(wrapped, &approvalTool{
+			InvokableTool: invokable,
+			name:          name,
+			approve:       approve,
+			dryRun:        dryRun,
+		})
+	}
+	return wrapped
+}
@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestMatchToolPattern(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		name     string
+		want     bool
+	}{
+		{[]string{"delete_*"}, "delete_pod", true},
+		{[]string{"delete_*"}, "list_pods", false},
+		{[]string{"list_pods", "scale_*"}, "scale_deployment", true},
+		{[]string{"rollout_pause"}, "rollout_resume", false},
+		{[]string{WriteToolsPattern}, "delete_pod", false},
+		{[]string{"[invalid"}, "delete_pod", false},
+		{nil, "delete_pod", false},
+	}
+	for _, tt := range tests {
+		if got := MatchToolPattern(tt.patterns, tt.name); got != tt.want {
+			t.Errorf("MatchToolPattern(%v, %q) = %v, want %v", tt.patterns, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsDryRunCall(t *testing.T) {
+	tests := []struct {
+		args string
+		want bool
+	}{
+		{`{"dry_run":true}`, true},
+		{`{"name":"web","dry_run":true}`, true},
+		{`{"dry_run":false}`, false},
+		{`{"name":"web"}`, false},
+		{`{"dry_run":"true"}`, false},
+		{`not json`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := isDryRunCall(tt.args); got != tt.want {
+			t.Errorf("isDryRunCall(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsApproval(t *testing.T) {
+	write := &schema.ToolInfo{Name: "rollout_resume", Desc: WriteToolMarker + "恢复滚动更新"}
+	read := &schema.ToolInfo{Name: "list_pods", Desc: "列出Pod"}
+
+	tests := []struct {
+		patterns []string
+		info     *schema.ToolInfo
+		want     bool
+	}{
+		{DefaultApprovalPatterns, write, true},
+		{DefaultApprovalPatterns, read, false},
+		{[]string{"list_*"}, read, true},
+		{[]string{"delete_*"}, write, false},
+	}
+	for _, tt := range tests {
+		if got := needsApproval(tt.patterns, tt.info); got != tt.want {
+			t.Errorf("needsApproval(%v, %s) = %v, want %v", tt.patterns, tt.info.Name, got, tt.want)
+		}
+	}
+}
+
+// fakeTool 记录是否被调用的测试工具
+type fakeTool struct {
+	info   *schema.ToolInfo
+	called bool
+}
+
+func (f *fakeTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return f.info, nil
+}
+
+func (f *fakeTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	f.called = true
+	return "ok", nil
+}
+
+func TestWithApprovalDryRun(t *testing.T) {
+	withDryRun := schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+		"dry_run": {Type: schema.Boolean},
+	})
+	withoutDryRun := schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+		"name": {Type: schema.String},
+	})
+
+	tests := []struct {
+		name        string
+		params      *schema.ParamsOneOf
+		args        string
+		wantApprove bool
+	}{
+		{"声明了dry_run的演练调用无需审批", withDryRun, `{"dry_run":true}`, false},
+		{"声明了dry_run的正常调用需要审批", withDryRun, `{"dry_run":false}`, true},
+		{"未声明dry_run时不信任演练参数", withoutDryRun, `{"dry_run":true}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTool{info: &schema.ToolInfo{Name: "delete_pod", Desc: WriteToolMarker + "删除Pod", ParamsOneOf: tt.params}}
+			asked := false
+			approve := func(ctx context.Context, toolName, argumentsInJSON string) (bool, string) {
+				asked = true
+				return false, "测试拒绝"
+			}
+
+			tools := WithApproval(context.Background(), []tool.BaseTool{fake}, DefaultApprovalPatterns, approve)
+			if _, err := tools[0].(tool.InvokableTool).InvokableRun(context.Background(), tt.args); err != nil {
+				t.Fatalf("InvokableRun: %v", err)
+			}
+			if asked != tt.wantApprove {
+				t.Errorf("请求审批 = %v, want %v", asked, tt.wantApprove)
+			}
+			if fake.called == tt.wantApprove {
+				t.Errorf("工具被调用 = %v, want %v", fake.called, !tt.wantApprove)
+			}
+		})
+	}
+}
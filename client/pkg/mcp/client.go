@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,12 @@ var (
 	ErrInitFail       = errors.New("初始化MCP客户端失败")
 )
 
+// 支持的传输方式
+const (
+	TransportSSE            = "sse"             // 长连接SSE流，会话随连接断开失效
+	TransportStreamableHTTP = "streamable-http" // 每次调用独立的HTTP请求，会话可在服务器重启后自动恢复
+)
+
 // 连接状态
 type connectionState int
 
@@ -30,8 +37,9 @@ const (
 
 // ClientManager 管理MCP客户端连接的结构体
 type ClientManager struct {
-	client               *client.SSEMCPClient
+	client               client.MCPClient
 	serverURL            string
+	transport            string
 	mutex                sync.RWMutex
 	state                connectionState
 	lastError            error
@@ -81,10 +89,20 @@ func WithConnectTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithTransport 设置传输方式，未设置时根据服务器URL推断：以 /sse 结尾使用SSE，否则使用streamable HTTP
+func WithTransport(transport string) ClientOption {
+	return func(cm *ClientManager) {
+		if transport != "" {
+			cm.transport = transport
+		}
+	}
+}
+
 // NewClientManager 创建新的MCP客户端管理器
 func NewClientManager(serverURL, apiToken string, options ...ClientOption) *ClientManager {
 	cm := &ClientManager{
 		serverURL:      serverURL,
+		transport:      transportFromURL(serverURL),
 		apiToken:       apiToken,
 		reconnect:      make(chan struct{}, 1),
 		stateChange:    make(chan struct{}, 5),
@@ -102,6 +120,19 @@ func NewClientManager(serverURL, apiToken string, options ...ClientOption) *Clie
 	return cm
 }
 
+// transportFromURL 根据服务器URL推断传输方式
+func transportFromURL(serverURL string) string {
+	if strings.HasSuffix(strings.TrimRight(serverURL, "/"), "/sse") {
+		return TransportSSE
+	}
+	return TransportStreamableHTTP
+}
+
+// Transport 返回使用的传输方式
+func (m *ClientManager) Transport() string {
+	return m.transport
+}
+
 // Start 启动客户端并开始监听重连信号
 func (m *ClientManager) Start(ctx context.Context) error {
 	// 首次连接
@@ -147,7 +178,7 @@ func (m *ClientManager) reconnectLoop(ctx context.Context) {
 }
 
 // GetClient 获取客户端，如果连接异常则尝试重新连接
-func (m *ClientManager) GetClient(ctx context.Context) (*client.SSEMCPClient, error) {
+func (m *ClientManager) GetClient(ctx context.Context) (client.MCPClient, error) {
 	m.connectLock.Lock()
 	defer m.connectLock.Unlock()
 
@@ -212,22 +243,56 @@ func (m *ClientManager) connect(ctx context.Context) error {
 	// 重置会话ID
 	m.sessionID = ""
 
+	if m.transport == TransportStreamableHTTP {
+		return m.connectStreamableHTTP(ctx)
+	}
+	return m.connectSSE(ctx)
+}
+
+// connectStreamableHTTP 通过streamable HTTP初始化会话
+// 不需要等待长连接稳定，也不需要会话过期计时器，会话失效时客户端会自动重新初始化
+func (m *ClientManager) connectStreamableHTTP(ctx context.Context) error {
+	fmt.Println("[连接] 正在通过streamable HTTP连接到MCP服务器...")
+	streamClient := NewStreamableHTTPClient(m.serverURL, m.authHeaders())
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "docker-cli",
+		Version: "1.0.0",
+	}
+	if _, err := streamClient.Initialize(ctx, initRequest); err != nil {
+		if Debug {
+			fmt.Printf("[连接] 初始化失败: %v\n", err)
+		}
+		return fmt.Errorf("MCP客户端初始化失败: %w", err)
+	}
+
+	m.client = streamClient
+	m.sessionID = streamClient.SessionID()
+	m.lastConnectTime = time.Now()
+
+	// 清除连接失败标志
+	m.connectionFailedLock.Lock()
+	m.connectionFailed = false
+	m.lastConnectionError = nil
+	m.connectionFailedLock.Unlock()
+
+	fmt.Printf("[连接] 建立新会话成功，会话ID: %s\n", m.sessionID)
+	return nil
+}
+
+// connectSSE 通过SSE长连接连接到MCP服务器
+func (m *ClientManager) connectSSE(ctx context.Context) error {
 	// 创建新客户端
-	var err error
-	m.client, err = client.NewSSEMCPClient(m.serverURL)
+	sseClient, err := client.NewSSEMCPClient(m.serverURL, m.clientOptions()...)
 	if err != nil {
 		if Debug {
 			fmt.Printf("[连接] 创建MCP客户端失败: %v\n", err)
 		}
 		return fmt.Errorf("创建MCP客户端失败: %w", err)
 	}
-
-	// 配置客户端
-	// 注意: 由于SSEMCPClient没有SetToken方法，将apiToken通过其他方式处理
-	// 如果需要设置token，可能需要在NewSSEMCPClient前后处理
-	if m.apiToken != "" && Debug {
-		fmt.Println("[连接] API令牌已配置")
-	}
+	m.client = sseClient
 
 	// 使用完全独立的上下文进行连接，避免外部上下文取消导致SSE流关闭
 	connectCtx := context.Background()
@@ -236,7 +301,7 @@ func (m *ClientManager) connect(ctx context.Context) error {
 	fmt.Println("[连接] 正在连接到MCP服务器...")
 
 	// 使用Start方法代替Connect方法
-	err = m.client.Start(connectCtx)
+	err = sseClient.Start(connectCtx)
 	if err != nil {
 		m.client.Close()
 		m.client = nil
@@ -325,6 +390,26 @@ func (m *ClientManager) connect(ctx context.Context) error {
 	return nil
 }
 
+// clientOptions 构建SSE客户端选项，配置了API令牌时在SSE连接和消息请求上携带Bearer认证头
+func (m *ClientManager) clientOptions() []client.ClientOption {
+	headers := m.authHeaders()
+	if headers == nil {
+		return nil
+	}
+	return []client.ClientOption{client.WithHeaders(headers)}
+}
+
+// authHeaders 返回认证请求头，未配置API令牌时返回nil
+func (m *ClientManager) authHeaders() map[string]string {
+	if m.apiToken == "" {
+		return nil
+	}
+	if Debug {
+		fmt.Println("[连接] API令牌已配置，请求将携带Authorization头")
+	}
+	return map[string]string{"Authorization": "Bearer " + m.apiToken}
+}
+
 // initializeClient 初始化MCP客户端
 func (m *ClientManager) initializeClient(ctx context.Context) error {
 	// 初始化客户端
@@ -451,8 +536,8 @@ func (m *ClientManager) needsReconnect() bool {
 		return true
 	}
 
-	// 检查会话是否过期（超过25分钟）
-	if time.Since(m.lastConnectTime) > 25*time.Minute {
+	// 检查SSE会话是否过期（超过25分钟），streamable HTTP会话由客户端自动恢复
+	if m.transport == TransportSSE && time.Since(m.lastConnectTime) > 25*time.Minute {
 		return true
 	}
 
@@ -482,14 +567,16 @@ func (m *ClientManager) RefreshSession() {
 		return
 	}
 
-	// 重置会话过期计时器，延长会话有效期
-	if m.sessionExpiryTimer != nil {
-		m.sessionExpiryTimer.Reset(30 * time.Minute)
-	} else {
-		// 如果定时器不存在，创建一个新的
-		m.sessionExpiryTimer = time.AfterFunc(30*time.Minute, func() {
-			m.MarkConnectionFailed(fmt.Errorf("会话超时自动标记为过期"))
-		})
+	// 重置SSE会话过期计时器，延长会话有效期；streamable HTTP会话不需要计时器
+	if m.transport == TransportSSE {
+		if m.sessionExpiryTimer != nil {
+			m.sessionExpiryTimer.Reset(30 * time.Minute)
+		} else {
+			// 如果定时器不存在，创建一个新的
+			m.sessionExpiryTimer = time.AfterFunc(30*time.Minute, func() {
+				m.MarkConnectionFailed(fmt.Errorf("会话超时自动标记为过期"))
+			})
+		}
 	}
 
 	// 更新最后连接时间，减少不必要的重连
@@ -536,8 +623,8 @@ func (m *ClientManager) HealthCheck() bool {
 		return false
 	}
 
-	// 检查最后连接时间，如果超过20分钟，认为会话可能不健康
-	if time.Since(m.lastConnectTime) > 20*time.Minute {
+	// 检查SSE连接时间，如果超过20分钟，认为会话可能不健康
+	if m.transport == TransportSSE && time.Since(m.lastConnectTime) > 20*time.Minute {
 		if Debug {
 			fmt.Printf("[健康] 会话时间过长 (%v)，需要刷新\n", time.Since(m.lastConnectTime))
 		}
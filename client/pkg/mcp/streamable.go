@@ -0,0 +1,466 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mcp-devops/client/pkg/tracing"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// sessionHeader streamable HTTP传输中保存会话ID的HTTP头
+const sessionHeader = "Mcp-Session-Id"
+
+// errSessionExpired 服务器不认识当前会话（会话过期或服务器重启）
+var errSessionExpired = errors.New("MCP会话已失效")
+
+// StreamableHTTPClient 基于MCP streamable HTTP传输的客户端
+// 每次调用都是独立的POST请求，不需要维持长连接；服务器返回404时自动重新初始化会话并重试，
+// 因此代理超时和服务器重启都不会中断后续的工具调用
+type StreamableHTTPClient struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+	requestID  atomic.Int64
+
+	mu          sync.Mutex
+	sessionID   string
+	initRequest *mcp.InitializeRequest // 用于会话失效后重新初始化
+
+	handlersMu sync.RWMutex
+	handlers   []func(notification mcp.JSONRPCNotification)
+}
+
+var _ client.MCPClient = (*StreamableHTTPClient)(nil)
+
+// NewStreamableHTTPClient 创建streamable HTTP客户端，headers会附加到每个请求上
+func NewStreamableHTTPClient(url string, headers map[string]string) *StreamableHTTPClient {
+	return &StreamableHTTPClient{
+		url:        url,
+		headers:    headers,
+		httpClient: &http.Client{},
+	}
+}
+
+// SessionID 返回服务器分配的会话ID
+func (c *StreamableHTTPClient) SessionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionID
+}
+
+// rpcResponse JSON-RPC响应
+type rpcResponse struct {
+	ID     *int64          `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Params json.RawMessage `json:"params"`
+}
+
+// Initialize 初始化会话并记录初始化参数，供会话失效后重新初始化
+func (c *StreamableHTTPClient) Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error) {
+	c.mu.Lock()
+	c.initRequest = &request
+	c.mu.Unlock()
+	return c.initialize(ctx)
+}
+
+// initialize 发送initialize请求和initialized通知，建立新会话
+func (c *StreamableHTTPClient) initialize(ctx context.Context) (*mcp.InitializeResult, error) {
+	c.mu.Lock()
+	request := c.initRequest
+	c.sessionID = ""
+	c.mu.Unlock()
+	if request == nil {
+		return nil, fmt.Errorf("客户端尚未初始化")
+	}
+
+	var result mcp.InitializeResult
+	if err := c.send(ctx, string(mcp.MethodInitialize), request.Params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.notify(ctx, "notifications/initialized"); err != nil {
+		return nil, fmt.Errorf("发送initialized通知失败: %w", err)
+	}
+	return &result, nil
+}
+
+// call 发送请求，会话失效时重新初始化并重试一次
+func (c *StreamableHTTPClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	err := c.send(ctx, method, params, result)
+	if !errors.Is(err, errSessionExpired) {
+		return err
+	}
+
+	if Debug {
+		fmt.Printf("[连接] 会话已失效，重新初始化后重试 %s\n", method)
+	}
+	if _, err := c.initialize(ctx); err != nil {
+		return fmt.Errorf("重新初始化会话失败: %w", err)
+	}
+	return c.send(ctx, method, params, result)
+}
+
+// send 发送一次JSON-RPC请求并解析结果
+// 每次请求创建一个客户端span，map类型的参数会在 _meta 中携带该span的追踪上下文
+func (c *StreamableHTTPClient) send(ctx context.Context, method string, params interface{}, result interface{}) (err error) {
+	ctx, span := tracing.StartClient(ctx, "mcp "+method, attribute.String("rpc.method", method))
+	defer func() { tracing.End(span, err) }()
+	if p, ok := params.(map[string]interface{}); ok {
+		if meta := tracing.Meta(ctx); meta != nil {
+			p["_meta"] = meta
+		}
+	}
+
+	id := c.requestID.Add(1)
+	message := map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  method,
+	}
+	if params != nil {
+		message["params"] = params
+	}
+
+	resp, err := c.post(ctx, message)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	response, err := c.readResponse(resp, id)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s 失败: %s (code %d)", method, response.Error.Message, response.Error.Code)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("解析 %s 的响应失败: %w", method, err)
+	}
+	return nil
+}
+
+// notify 发送没有返回值的通知
+func (c *StreamableHTTPClient) notify(ctx context.Context, method string) error {
+	resp, err := c.post(ctx, map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"method":  method,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// post 发送HTTP请求，记录服务器返回的会话ID，并把非成功状态码转换为错误
+func (c *StreamableHTTPClient) post(ctx context.Context, message interface{}) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	if sessionID := c.SessionID(); sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+	tracing.InjectHeaders(ctx, req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && req.Header.Get(sessionHeader) != "":
+		resp.Body.Close()
+		return nil, errSessionExpired
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("服务器返回 %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if sessionID := resp.Header.Get(sessionHeader); sessionID != "" {
+		c.mu.Lock()
+		c.sessionID = sessionID
+		c.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// readResponse 读取请求对应的响应，支持JSON响应体和SSE流两种格式
+func (c *StreamableHTTPClient) readResponse(resp *http.Response, id int64) (*rpcResponse, error) {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var response rpcResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+		return &response, nil
+	}
+
+	// SSE流中可能先收到通知，直到收到对应ID的响应为止
+	reader := bufio.NewReader(resp.Body)
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		} else if line == "" && data.Len() > 0 {
+			var response rpcResponse
+			if jsonErr := json.Unmarshal([]byte(data.String()), &response); jsonErr == nil {
+				if response.ID != nil && *response.ID == id {
+					return &response, nil
+				}
+				if response.ID == nil && response.Method != "" {
+					c.dispatch(response)
+				}
+			}
+			data.Reset()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取响应流失败: %w", err)
+		}
+	}
+}
+
+// dispatch 把服务器通知转发给已注册的处理函数
+func (c *StreamableHTTPClient) dispatch(response rpcResponse) {
+	notification := mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION}
+	notification.Method = response.Method
+	if len(response.Params) > 0 {
+		json.Unmarshal(response.Params, &notification.Params)
+	}
+
+	c.handlersMu.RLock()
+	defer c.handlersMu.RUnlock()
+	for _, handler := range c.handlers {
+		handler(notification)
+	}
+}
+
+// Ping 检查服务器是否可用
+func (c *StreamableHTTPClient) Ping(ctx context.Context) error {
+	return c.call(ctx, string(mcp.MethodPing), nil, nil)
+}
+
+// ListResourcesByPage 列出一页资源
+func (c *StreamableHTTPClient) ListResourcesByPage(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
+	var result mcp.ListResourcesResult
+	if err := c.call(ctx, string(mcp.MethodResourcesList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListResources 按游标依次获取并合并全部资源
+func (c *StreamableHTTPClient) ListResources(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
+	result, err := c.ListResourcesByPage(ctx, request)
+	for err == nil && result.NextCursor != "" {
+		var page *mcp.ListResourcesResult
+		request.Params.Cursor = result.NextCursor
+		if page, err = c.ListResourcesByPage(ctx, request); err == nil {
+			result.Resources = append(result.Resources, page.Resources...)
+			result.NextCursor = page.NextCursor
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListResourceTemplatesByPage 列出一页资源模板
+func (c *StreamableHTTPClient) ListResourceTemplatesByPage(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
+	var result mcp.ListResourceTemplatesResult
+	if err := c.call(ctx, string(mcp.MethodResourcesTemplatesList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListResourceTemplates 按游标依次获取并合并全部资源模板
+func (c *StreamableHTTPClient) ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
+	result, err := c.ListResourceTemplatesByPage(ctx, request)
+	for err == nil && result.NextCursor != "" {
+		var page *mcp.ListResourceTemplatesResult
+		request.Params.Cursor = result.NextCursor
+		if page, err = c.ListResourceTemplatesByPage(ctx, request); err == nil {
+			result.ResourceTemplates = append(result.ResourceTemplates, page.ResourceTemplates...)
+			result.NextCursor = page.NextCursor
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReadResource 读取资源
+func (c *StreamableHTTPClient) ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, string(mcp.MethodResourcesRead), request.Params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseReadResourceResult(&raw)
+}
+
+// Subscribe 订阅资源变更
+func (c *StreamableHTTPClient) Subscribe(ctx context.Context, request mcp.SubscribeRequest) error {
+	return c.call(ctx, "resources/subscribe", request.Params, nil)
+}
+
+// Unsubscribe 取消订阅资源变更
+func (c *StreamableHTTPClient) Unsubscribe(ctx context.Context, request mcp.UnsubscribeRequest) error {
+	return c.call(ctx, "resources/unsubscribe", request.Params, nil)
+}
+
+// ListPromptsByPage 列出一页提示词
+func (c *StreamableHTTPClient) ListPromptsByPage(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error) {
+	var result mcp.ListPromptsResult
+	if err := c.call(ctx, string(mcp.MethodPromptsList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPrompts 按游标依次获取并合并全部提示词
+func (c *StreamableHTTPClient) ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error) {
+	result, err := c.ListPromptsByPage(ctx, request)
+	for err == nil && result.NextCursor != "" {
+		var page *mcp.ListPromptsResult
+		request.Params.Cursor = result.NextCursor
+		if page, err = c.ListPromptsByPage(ctx, request); err == nil {
+			result.Prompts = append(result.Prompts, page.Prompts...)
+			result.NextCursor = page.NextCursor
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPrompt 获取提示词
+func (c *StreamableHTTPClient) GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, string(mcp.MethodPromptsGet), request.Params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseGetPromptResult(&raw)
+}
+
+// ListToolsByPage 列出一页工具
+func (c *StreamableHTTPClient) ListToolsByPage(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	var result mcp.ListToolsResult
+	if err := c.call(ctx, string(mcp.MethodToolsList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTools 按游标依次获取并合并全部工具
+func (c *StreamableHTTPClient) ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	result, err := c.ListToolsByPage(ctx, request)
+	for err == nil && result.NextCursor != "" {
+		var page *mcp.ListToolsResult
+		request.Params.Cursor = result.NextCursor
+		if page, err = c.ListToolsByPage(ctx, request); err == nil {
+			result.Tools = append(result.Tools, page.Tools...)
+			result.NextCursor = page.NextCursor
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CallTool 调用工具，参数以map发送，以便在 _meta 中附加追踪上下文
+func (c *StreamableHTTPClient) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	params := map[string]interface{}{
+		"name":      request.Params.Name,
+		"arguments": request.Params.Arguments,
+	}
+	var raw json.RawMessage
+	if err := c.call(ctx, string(mcp.MethodToolsCall), params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseCallToolResult(&raw)
+}
+
+// SetLevel 设置服务器日志级别
+func (c *StreamableHTTPClient) SetLevel(ctx context.Context, request mcp.SetLevelRequest) error {
+	return c.call(ctx, "logging/setLevel", request.Params, nil)
+}
+
+// Complete 请求参数补全
+func (c *StreamableHTTPClient) Complete(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	var result mcp.CompleteResult
+	if err := c.call(ctx, "completion/complete", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// OnNotification 注册服务器通知的处理函数
+func (c *StreamableHTTPClient) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.handlers = append(c.handlers, handler)
+}
+
+// Close 通知服务器结束会话
+func (c *StreamableHTTPClient) Close() error {
+	sessionID := c.SessionID()
+	if sessionID == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, c.url, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set(sessionHeader, sessionID)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	c.mu.Lock()
+	c.sessionID = ""
+	c.mu.Unlock()
+	return nil
+}
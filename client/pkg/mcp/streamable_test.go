@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"mcp-devops/server/streamable"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// testMCPServer 创建注册了若干echo工具的MCP服务器
+func testMCPServer(opts ...server.ServerOption) *server.MCPServer {
+	svr := server.NewMCPServer("test", "1.0", opts...)
+	for _, name := range []string{"echo", "echo_again", "echo_more"} {
+		svr.AddTool(mcp.NewTool(name, mcp.WithString("text")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text, _ := request.Params.Arguments["text"].(string)
+			return mcp.NewToolResultText(text), nil
+		})
+	}
+	return svr
+}
+
+// restartableServer 可以替换处理器的测试服务器，用于模拟服务器重启后会话丢失
+type restartableServer struct {
+	mu          sync.Mutex
+	handler     http.Handler
+	initializes atomic.Int32
+	deletes     atomic.Int32
+}
+
+func (s *restartableServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		s.deletes.Add(1)
+	}
+	body, _ := io.ReadAll(r.Body)
+	if strings.Contains(string(body), `"method":"initialize"`) {
+		s.initializes.Add(1)
+	}
+	r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+	s.mu.Lock()
+	handler := s.handler
+	s.mu.Unlock()
+	handler.ServeHTTP(w, r)
+}
+
+// restart 换成新的streamable服务器，之前的会话全部失效
+func (s *restartableServer) restart(handler http.Handler) {
+	s.mu.Lock()
+	s.handler = handler
+	s.mu.Unlock()
+}
+
+// newInitializedClient 创建并初始化连接到url的客户端
+func newInitializedClient(t *testing.T, url string) *StreamableHTTPClient {
+	t.Helper()
+	c := NewStreamableHTTPClient(url, nil)
+	var request mcp.InitializeRequest
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0"}
+	if _, err := c.Initialize(context.Background(), request); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	return c
+}
+
+// callEcho 调用echo工具并返回结果文本
+func callEcho(c *StreamableHTTPClient, text string) (string, error) {
+	var request mcp.CallToolRequest
+	request.Params.Name = "echo"
+	request.Params.Arguments = map[string]interface{}{"text": text}
+	result, err := c.CallTool(context.Background(), request)
+	if err != nil {
+		return "", err
+	}
+	content, _ := mcp.AsTextContent(result.Content[0])
+	return content.Text, nil
+}
+
+func TestStreamableClientReinitializesAfterRestart(t *testing.T) {
+	svr := testMCPServer()
+	backend := &restartableServer{handler: streamable.NewServer(svr)}
+	ts := httptest.NewServer(backend)
+	defer ts.Close()
+
+	c := newInitializedClient(t, ts.URL)
+	first := c.SessionID()
+	if text, err := callEcho(c, "before"); err != nil || text != "before" {
+		t.Fatalf("callEcho = %q, %v", text, err)
+	}
+
+	// 服务器重启后旧会话返回404，客户端重新初始化并重试一次
+	backend.restart(streamable.NewServer(svr))
+	if text, err := callEcho(c, "after"); err != nil || text != "after" {
+		t.Fatalf("重启后 callEcho = %q, %v", text, err)
+	}
+	if n := backend.initializes.Load(); n != 2 {
+		t.Errorf("initialize 次数 = %d, want 2", n)
+	}
+	if c.SessionID() == first || c.SessionID() == "" {
+		t.Errorf("重新初始化后会话ID = %q, 旧会话ID = %q", c.SessionID(), first)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if backend.deletes.Load() != 1 || c.SessionID() != "" {
+		t.Errorf("Close 应该发送DELETE并清空会话ID, deletes = %d", backend.deletes.Load())
+	}
+}
+
+func TestStreamableClientRetriesOnlyOnce(t *testing.T) {
+	svr := testMCPServer()
+	backend := streamable.NewServer(svr)
+	var initializes atomic.Int32
+	// 服务器在工具调用时总是不认识会话，客户端只重新初始化并重试一次后返回错误
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"method":"initialize"`) {
+			initializes.Add(1)
+		}
+		if strings.Contains(string(body), `"method":"tools/call"`) {
+			http.Error(w, "会话不存在", http.StatusNotFound)
+			return
+		}
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		backend.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	c := NewStreamableHTTPClient(ts.URL, nil)
+	var request mcp.InitializeRequest
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := c.Initialize(context.Background(), request); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	_, err := callEcho(c, "hi")
+	if !errors.Is(err, errSessionExpired) {
+		t.Errorf("callEcho 错误 = %v, want errSessionExpired", err)
+	}
+	if n := initializes.Load(); n != 2 {
+		t.Errorf("initialize 次数 = %d, want 2", n)
+	}
+}
+
+func TestStreamableClientListToolsPages(t *testing.T) {
+	ts := httptest.NewServer(streamable.NewServer(testMCPServer(server.WithPaginationLimit(1))))
+	defer ts.Close()
+
+	c := newInitializedClient(t, ts.URL)
+	page, err := c.ListToolsByPage(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListToolsByPage: %v", err)
+	}
+	if len(page.Tools) != 1 || page.NextCursor == "" {
+		t.Errorf("第一页 = %d 个工具, NextCursor = %q", len(page.Tools), page.NextCursor)
+	}
+
+	all, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	if len(all.Tools) != 3 || all.NextCursor != "" {
+		t.Errorf("ListTools = %d 个工具, NextCursor = %q, want 3个工具", len(all.Tools), all.NextCursor)
+	}
+}
@@ -0,0 +1,156 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 客户端创建的span所属的instrumentation名称
+const instrumentationName = "mcp-devops/client"
+
+// Enabled 是否配置了OTLP导出地址，未配置时不创建导出器，span全部为空操作
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup 按OTEL_*环境变量创建OTLP/HTTP导出器并注册全局TracerProvider，返回的函数在退出时刷新并关闭导出器
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	if !Enabled() {
+		otel.SetTextMapPropagator(propagator())
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("创建OTLP导出器失败: %v", err)
+	}
+	return SetupWithExporter(ctx, serviceName, exporter)
+}
+
+// SetupWithExporter 使用指定的导出器注册全局TracerProvider，测试时可传入内存导出器
+func SetupWithExporter(ctx context.Context, serviceName string, exporter sdktrace.SpanExporter) (func(context.Context) error, error) {
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建追踪资源失败: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator())
+	return provider.Shutdown, nil
+}
+
+// propagator W3C trace context和baggage传播器，与服务器保持一致
+func propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Start 创建子span，上下文中没有span时创建根span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartClient 创建调用外部系统的子span
+func StartClient(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// End 记录错误并结束span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectHeaders 把上下文中的追踪信息写入HTTP头
+func InjectHeaders(ctx context.Context, header map[string][]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Meta 返回携带追踪上下文的MCP请求 _meta 字段，上下文中没有有效span时返回nil
+func Meta(ctx context.Context) map[string]interface{} {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	meta := make(map[string]interface{}, len(carrier))
+	for key, value := range carrier {
+		meta[key] = value
+	}
+	return meta
+}
+
+// Callbacks 返回为代理中的大模型调用和工具调用创建span的eino回调
+// 图和节点本身的回调不创建span，这样大模型和工具span直接挂在命令的根span下
+func Callbacks() callbacks.Handler {
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, _ callbacks.CallbackInput) context.Context {
+			name, ok := spanName(info)
+			if !ok {
+				return ctx
+			}
+			ctx, _ = Start(ctx, name,
+				attribute.String("eino.component", string(info.Component)),
+				attribute.String("eino.type", info.Type),
+			)
+			return ctx
+		}).
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, _ callbacks.CallbackOutput) context.Context {
+			if _, ok := spanName(info); ok {
+				trace.SpanFromContext(ctx).End()
+			}
+			return ctx
+		}).
+		OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			if _, ok := spanName(info); ok {
+				End(trace.SpanFromContext(ctx), err)
+			}
+			return ctx
+		}).
+		OnEndWithStreamOutputFn(func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+			// 回调收到的是流的副本，必须关闭，否则会阻塞原始流
+			output.Close()
+			if _, ok := spanName(info); ok {
+				trace.SpanFromContext(ctx).End()
+			}
+			return ctx
+		}).
+		Build()
+}
+
+// spanName 返回组件对应的span名称，只追踪大模型和工具
+func spanName(info *callbacks.RunInfo) (string, bool) {
+	if info == nil {
+		return "", false
+	}
+	switch info.Component {
+	case components.ComponentOfChatModel:
+		return "llm " + info.Type, true
+	case components.ComponentOfTool:
+		return "tool " + info.Name, true
+	}
+	return "", false
+}
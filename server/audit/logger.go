@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultMaxSizeMB 未配置大小上限时单个文件的最大大小(MB)
+const defaultMaxSizeMB = 100
+
+// Config 审计日志配置
+type Config struct {
+	Path       string // 审计日志文件路径，为空时关闭审计
+	MaxSize    int64  // 单个文件最大字节数，超过后轮转
+	MaxBackups int    // 保留的轮转文件个数
+}
+
+// Entry 一次工具调用的审计记录
+type Entry struct {
+	Time       time.Time              `json:"time"`
+	Session    string                 `json:"session,omitempty"`
+	Caller     string                 `json:"caller,omitempty"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+	ResultSize int                    `json:"result_size"`
+	IsError    bool                   `json:"is_error,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Logger 以JSON Lines格式写入审计记录，文件超过大小上限后轮转
+type Logger struct {
+	cfg Config
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewLogger 打开审计日志文件，配置的路径为空时返回nil
+func NewLogger(cfg Config) (*Logger, error) {
+	if cfg.Path == "" {
+		return nil, nil
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxSizeMB << 20
+	}
+
+	l := &Logger{cfg: cfg}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Path 返回审计日志文件路径
+func (l *Logger) Path() string {
+	return l.cfg.Path
+}
+
+// open 以追加方式打开日志文件
+func (l *Logger) open() error {
+	if err := os.MkdirAll(filepath.Dir(l.cfg.Path), 0o750); err != nil {
+		return fmt.Errorf("创建审计日志目录失败: %v", err)
+	}
+	file, err := os.OpenFile(l.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("打开审计日志文件失败: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("读取审计日志文件信息失败: %v", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Write 写入一条审计记录
+func (l *Logger) Write(entry Entry) error {
+	data, err := marshalJSON(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("审计日志已关闭")
+	}
+	if l.size > 0 && l.size+int64(len(data)) > l.cfg.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	return err
+}
+
+// rotate 轮转日志文件: audit.jsonl -> audit.jsonl.1 -> audit.jsonl.2 ...，超出保留个数的文件被删除
+func (l *Logger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("关闭审计日志文件失败: %v", err)
+	}
+	l.file = nil
+
+	if l.cfg.MaxBackups == 0 {
+		os.Remove(l.cfg.Path)
+	} else {
+		os.Remove(backupName(l.cfg.Path, l.cfg.MaxBackups))
+		for i := l.cfg.MaxBackups - 1; i >= 1; i-- {
+			os.Rename(backupName(l.cfg.Path, i), backupName(l.cfg.Path, i+1))
+		}
+		if err := os.Rename(l.cfg.Path, backupName(l.cfg.Path, 1)); err != nil {
+			// 轮转失败时继续写入原文件，避免丢失审计记录
+			fmt.Println("轮转审计日志失败:", err)
+		}
+	}
+
+	return l.open()
+}
+
+// files 按时间从旧到新返回所有审计日志文件
+func (l *Logger) files() []string {
+	var files []string
+	for i := l.cfg.MaxBackups; i >= 1; i-- {
+		name := backupName(l.cfg.Path, i)
+		if _, err := os.Stat(name); err == nil {
+			files = append(files, name)
+		}
+	}
+	return append(files, l.cfg.Path)
+}
+
+// Close 关闭审计日志文件
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// backupName 返回第n个轮转文件的名称
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// marshalJSON 序列化为以换行结尾的JSON，不转义HTML字符，便于直接阅读和grep
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"mcp-devops/server/auth"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// redactedValue 被隐藏的参数值
+const redactedValue = "<已隐藏>"
+
+// sensitiveArgs 值需要隐藏的参数名片段
+var sensitiveArgs = []string{"password", "passwd", "token", "secret_key", "api_key", "credential", "private_key", "webhook_url"}
+
+// secretDataArgs Secret工具中保存明文值的参数，对象只保留键名，字符串整体隐藏
+var secretDataArgs = map[string]bool{"data": true, "string_data": true, "value": true, "from_literal": true}
+
+// valueMapArgs 其他工具中保存明文值的对象参数，只保留键名，如set_env的环境变量
+var valueMapArgs = map[string]string{"set_env": "env"}
+
+// manifestArg 清单类工具中保存YAML/JSON清单的参数
+const manifestArg = "manifest"
+
+// secretKindPattern 匹配清单中的 kind: Secret，YAML和JSON两种写法
+var secretKindPattern = regexp.MustCompile(`"?kind"?\s*:\s*"?Secret\b"?`)
+
+// Middleware 记录每次工具调用的审计日志
+func (l *Logger) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		entry := Entry{
+			Time:       start,
+			Tool:       request.Params.Name,
+			Arguments:  RedactArguments(request.Params.Name, request.Params.Arguments),
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			entry.Session = session.SessionID()
+		}
+		if identity, ok := auth.IdentityFromContext(ctx); ok {
+			entry.Caller = identity.Name
+		}
+		if result != nil {
+			entry.ResultSize = resultSize(result)
+			entry.IsError = result.IsError
+			if result.IsError {
+				entry.Error = resultText(result)
+			}
+		}
+		if err != nil {
+			entry.IsError = true
+			entry.Error = err.Error()
+		}
+
+		// 审计失败不影响工具调用本身
+		if werr := l.Write(entry); werr != nil {
+			fmt.Println("写入审计日志失败:", werr)
+		}
+		return result, err
+	}
+}
+
+// RedactArguments 复制参数并隐藏其中的敏感值
+func RedactArguments(toolName string, args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	isSecretTool := strings.HasSuffix(toolName, "_secret")
+
+	redacted := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		switch {
+		case isSensitiveArg(key):
+			redacted[key] = redactedValue
+		case isSecretTool && secretDataArgs[key]:
+			redacted[key] = redactMapValues(value)
+		case valueMapArgs[toolName] == key:
+			redacted[key] = redactMapValues(value)
+		case key == manifestArg:
+			redacted[key] = redactManifest(value)
+		default:
+			redacted[key] = redactNested(value)
+		}
+	}
+	return redacted
+}
+
+// isSensitiveArg 参数名是否表示凭据
+func isSensitiveArg(key string) bool {
+	key = strings.ToLower(key)
+	for _, name := range sensitiveArgs {
+		if strings.Contains(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactNested 递归隐藏嵌套对象中的敏感字段
+func redactNested(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return RedactArguments("", v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactNested(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// redactManifest 清单中包含Secret时隐藏整个清单，其余清单原样保留便于还原操作
+func redactManifest(value interface{}) interface{} {
+	manifest, ok := value.(string)
+	if ok && secretKindPattern.MatchString(manifest) {
+		return "<已隐藏: 清单中包含Secret>"
+	}
+	return value
+}
+
+// redactMapValues 保留对象的键名，隐藏所有值
+func redactMapValues(value interface{}) interface{} {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return redactedValue
+	}
+	redacted := make(map[string]interface{}, len(values))
+	for key := range values {
+		redacted[key] = redactedValue
+	}
+	return redacted
+}
+
+// resultSize 计算工具返回的文本内容大小
+func resultSize(result *mcp.CallToolResult) int {
+	size := 0
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			size += len(text.Text)
+		}
+	}
+	return size
+}
+
+// resultText 返回工具结果中的第一段文本，用于记录错误信息
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
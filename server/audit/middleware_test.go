@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "update_secret隐藏value",
+			tool: "update_secret",
+			args: map[string]interface{}{"name": "db", "key": "password", "value": "s3cr3t"},
+			want: map[string]interface{}{"name": "db", "key": "password", "value": redactedValue},
+		},
+		{
+			name: "create_secret隐藏from_literal",
+			tool: "create_secret",
+			args: map[string]interface{}{"name": "db", "from_literal": "user=admin,pass=s3cr3t"},
+			want: map[string]interface{}{"name": "db", "from_literal": redactedValue},
+		},
+		{
+			name: "create_secret的data只保留键名",
+			tool: "create_secret",
+			args: map[string]interface{}{"data": map[string]interface{}{"pass": "s3cr3t"}},
+			want: map[string]interface{}{"data": map[string]interface{}{"pass": redactedValue}},
+		},
+		{
+			name: "set_env的env只保留键名",
+			tool: "set_env",
+			args: map[string]interface{}{
+				"name": "api",
+				"env": map[string]interface{}{
+					"DATABASE_URL":          "postgres://user:pass@db/app",
+					"AWS_SECRET_ACCESS_KEY": "abc",
+				},
+				"remove": []interface{}{"DEBUG"},
+			},
+			want: map[string]interface{}{
+				"name": "api",
+				"env": map[string]interface{}{
+					"DATABASE_URL":          redactedValue,
+					"AWS_SECRET_ACCESS_KEY": redactedValue,
+				},
+				"remove": []interface{}{"DEBUG"},
+			},
+		},
+		{
+			name: "其他工具的value保持原样",
+			tool: "update_configmap",
+			args: map[string]interface{}{"key": "mode", "value": "debug"},
+			want: map[string]interface{}{"key": "mode", "value": "debug"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RedactArguments(tt.tool, tt.args)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedactArguments(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	defaultQueryLimit = 50  // 默认返回的记录数
+	maxQueryLimit     = 500 // 最多返回的记录数
+)
+
+// Query 审计日志查询条件
+type Query struct {
+	Tool       string    // 工具名称，支持通配符
+	Session    string    // 客户端会话ID
+	Contains   string    // 参数中包含的文本
+	Since      time.Time // 起始时间
+	Until      time.Time // 截止时间
+	ErrorsOnly bool      // 只返回失败的调用
+	Limit      int       // 最多返回的记录数
+}
+
+// match 检查记录是否满足查询条件
+func (q Query) match(entry Entry) bool {
+	if q.Tool != "" {
+		if matched, err := path.Match(q.Tool, entry.Tool); err != nil || !matched {
+			return false
+		}
+	}
+	if q.Session != "" && entry.Session != q.Session {
+		return false
+	}
+	if q.Contains != "" {
+		args, _ := marshalJSON(entry.Arguments)
+		if !strings.Contains(string(args), q.Contains) {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && entry.Time.After(q.Until) {
+		return false
+	}
+	if q.ErrorsOnly && !entry.IsError {
+		return false
+	}
+	return true
+}
+
+// Search 按条件搜索审计日志，返回最近的记录（从新到旧）和匹配的总数
+func (l *Logger) Search(q Query) ([]Entry, int, error) {
+	if q.Limit <= 0 {
+		q.Limit = defaultQueryLimit
+	}
+
+	files, sizes, err := l.snapshot()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	var entries []Entry
+	total := 0
+	for i, file := range files {
+		reader := bufio.NewReader(io.NewSectionReader(file, 0, sizes[i]))
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				var entry Entry
+				if json.Unmarshal(line, &entry) == nil && q.match(entry) {
+					total++
+					// 只保留最近的limit条
+					entries = append(entries, entry)
+					if len(entries) > q.Limit {
+						entries = entries[1:]
+					}
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, total, nil
+}
+
+// snapshot 在锁内打开所有审计日志文件并记录各自当前的大小，读取时不再持有锁，避免长时间查询阻塞写入
+// 已打开的文件在轮转改名或删除后仍然可以读取，只读取快照时的大小也不会读到写了一半的行
+func (l *Logger) snapshot() ([]*os.File, []int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var files []*os.File
+	var sizes []int64
+	for _, name := range l.files() {
+		file, err := os.Open(name)
+		if err == nil {
+			var info os.FileInfo
+			if info, err = file.Stat(); err == nil {
+				files = append(files, file)
+				sizes = append(sizes, info.Size())
+				continue
+			}
+			file.Close()
+		}
+		if os.IsNotExist(err) {
+			continue
+		}
+		for _, file := range files {
+			file.Close()
+		}
+		return nil, nil, err
+	}
+	return files, sizes, nil
+}
+
+// parseTime 解析RFC3339时间或相对时长（如 30m、2h，表示多久之前）
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("时间 %q 格式无效，请使用RFC3339格式（如 2024-01-02T15:04:05+08:00）或时长（如 30m、2h）", value)
+}
+
+// QueryTool 搜索审计日志
+func (l *Logger) QueryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	toolName, _ := request.Params.Arguments["tool"].(string)
+	session, _ := request.Params.Arguments["session"].(string)
+	contains, _ := request.Params.Arguments["contains"].(string)
+	sinceStr, _ := request.Params.Arguments["since"].(string)
+	untilStr, _ := request.Params.Arguments["until"].(string)
+	errorsOnly, _ := request.Params.Arguments["errors_only"].(bool)
+	limit, _ := request.Params.Arguments["limit"].(float64)
+
+	fmt.Println("ai 正在调用mcp server的tool: query_audit_log, tool=", toolName, ", session=", session, ", since=", sinceStr, ", until=", untilStr)
+
+	since, err := parseTime(sinceStr)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("查询审计日志失败: %v", err)), err
+	}
+	until, err := parseTime(untilStr)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("查询审计日志失败: %v", err)), err
+	}
+
+	q := Query{
+		Tool:       toolName,
+		Session:    session,
+		Contains:   contains,
+		Since:      since,
+		Until:      until,
+		ErrorsOnly: errorsOnly,
+		Limit:      min(int(limit), maxQueryLimit),
+	}
+	entries, total, err := l.Search(q)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("查询审计日志失败: %v", err)), err
+	}
+
+	if total == 0 {
+		return mcp.NewToolResultText("没有找到符合条件的审计记录"), nil
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("共找到 %d 条审计记录，按时间从新到旧显示最近 %d 条:\n\n", total, len(entries)))
+	for _, entry := range entries {
+		status := "成功"
+		if entry.IsError {
+			status = "失败"
+		}
+		out.WriteString(fmt.Sprintf("[%s] %s %s 耗时: %dms 结果大小: %d字节",
+			entry.Time.Format(time.RFC3339), entry.Tool, status, entry.DurationMS, entry.ResultSize))
+		if entry.Session != "" {
+			out.WriteString(fmt.Sprintf(" 会话: %s", entry.Session))
+		}
+		if entry.Caller != "" {
+			out.WriteString(fmt.Sprintf(" 调用方: %s", entry.Caller))
+		}
+		out.WriteString("\n")
+		if len(entry.Arguments) > 0 {
+			args, _ := marshalJSON(entry.Arguments)
+			out.WriteString(fmt.Sprintf("  参数: %s", args))
+		}
+		if entry.Error != "" {
+			out.WriteString(fmt.Sprintf("  错误: %s\n", entry.Error))
+		}
+	}
+
+	return mcp.NewToolResultText(out.String()), nil
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLogger 创建写入临时目录的审计日志，单个文件约容纳两条记录
+func newTestLogger(t *testing.T) *Logger {
+	t.Helper()
+	l, err := NewLogger(Config{Path: filepath.Join(t.TempDir(), "audit.jsonl"), MaxSize: 200, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// writeEntries 写入名为 tool_0..tool_n-1 的记录
+func writeEntries(t *testing.T, l *Logger, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		entry := Entry{Time: time.Unix(int64(i), 0), Tool: fmt.Sprintf("tool_%d", i), IsError: i%2 == 1}
+		if err := l.Write(entry); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+}
+
+func TestSearchAcrossRotation(t *testing.T) {
+	l := newTestLogger(t)
+	writeEntries(t, l, 5)
+
+	entries, total, err := l.Search(Query{Limit: 2})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if total != 5 {
+		t.Errorf("total = %d, want 5", total)
+	}
+	if len(entries) != 2 || entries[0].Tool != "tool_4" || entries[1].Tool != "tool_3" {
+		t.Errorf("entries = %+v, want tool_4, tool_3", entries)
+	}
+
+	entries, total, err = l.Search(Query{ErrorsOnly: true})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if total != 2 || entries[0].Tool != "tool_3" || entries[1].Tool != "tool_1" {
+		t.Errorf("ErrorsOnly: total = %d, entries = %+v", total, entries)
+	}
+}
+
+func TestSnapshotSurvivesRotation(t *testing.T) {
+	l := newTestLogger(t)
+	writeEntries(t, l, 2)
+
+	files, sizes, err := l.snapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	// 读取快照期间继续写入并多次轮转，不会被查询阻塞
+	writeEntries(t, l, 6)
+
+	var content strings.Builder
+	for i, file := range files {
+		data, err := io.ReadAll(io.NewSectionReader(file, 0, sizes[i]))
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		content.Write(data)
+	}
+	if got := strings.Count(content.String(), "\n"); got != 2 {
+		t.Errorf("快照中的记录数 = %d, want 2:\n%s", got, content.String())
+	}
+	if !strings.Contains(content.String(), `"tool":"tool_1"`) {
+		t.Errorf("快照内容 = %s", content.String())
+	}
+}
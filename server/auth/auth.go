@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// 认证方式
+const (
+	MethodToken = "token" // Authorization: Bearer 令牌
+	MethodMTLS  = "mtls"  // 经过校验的客户端证书
+	MethodLocal = "local" // stdio方式下启动服务器进程的本地用户
+)
+
+// Config SSE端点的认证配置
+type Config struct {
+	Token        string  // 客户端需要携带的Bearer令牌，为空时不校验令牌
+	TLSCertFile  string  // 服务端证书，与TLSKeyFile同时配置时启用HTTPS
+	TLSKeyFile   string  // 服务端私钥
+	ClientCAFile string  // 签发客户端证书的CA，配置后要求客户端出示有效证书（mTLS）
+	Policy       *Policy // 授权策略，其中配置的令牌同样可以通过认证
+}
+
+// TLSEnabled 是否以HTTPS方式监听
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// Enabled 是否配置了任意一种客户端认证方式
+func (c Config) Enabled() bool {
+	return c.requireToken() || c.ClientCAFile != ""
+}
+
+// requireToken 是否要求请求携带令牌
+func (c Config) requireToken() bool {
+	return c.Token != "" || c.Policy.HasTokens()
+}
+
+// TLSConfig 构建服务端TLS配置，配置了客户端CA时要求并校验客户端证书
+func (c Config) TLSConfig() (*tls.Config, error) {
+	if !c.TLSEnabled() {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载服务端证书失败: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientCAFile != "" {
+		data, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取客户端CA文件失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("客户端CA文件 %s 中没有有效的PEM证书", c.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// Identity 通过认证的调用方
+type Identity struct {
+	Name   string // 调用方名称，mTLS时为证书CN
+	Method string // 认证方式
+}
+
+// LocalIdentity 返回stdio方式下的调用方身份，MCP_STDIO_IDENTITY 可覆盖默认的系统用户名
+func LocalIdentity() Identity {
+	name := strings.TrimSpace(os.Getenv("MCP_STDIO_IDENTITY"))
+	if name == "" {
+		if current, err := user.Current(); err == nil {
+			name = current.Username
+		}
+	}
+	if name == "" {
+		name = MethodLocal
+	}
+	return Identity{Name: name, Method: MethodLocal}
+}
+
+// identityKey 上下文中保存调用方身份的键
+type identityKey struct{}
+
+// WithIdentity 把调用方身份保存到上下文中
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext 获取本次请求的调用方身份，未认证时返回false
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Middleware 校验SSE端点和消息端点的每个HTTP请求
+// 配置了令牌时要求 Authorization: Bearer <token>；启用mTLS时客户端证书已在TLS握手阶段校验
+func (c Config) Middleware(next http.Handler) http.Handler {
+	if !c.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := c.authenticate(r)
+		if !ok {
+			fmt.Printf("拒绝未认证的请求: %s %s 来自 %s\n", r.Method, r.URL.Path, r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-devops"`)
+			http.Error(w, "未认证: 缺少或无效的访问令牌", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
+
+// authenticate 校验请求携带的凭据，返回调用方身份
+func (c Config) authenticate(r *http.Request) (Identity, bool) {
+	var identity Identity
+
+	if c.ClientCAFile != "" {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			return identity, false
+		}
+		identity = Identity{Name: r.TLS.VerifiedChains[0][0].Subject.CommonName, Method: MethodMTLS}
+	}
+
+	if c.requireToken() {
+		token, ok := BearerToken(r)
+		if !ok {
+			return identity, false
+		}
+		// 策略中的令牌对应具体的调用方，优先于证书CN
+		if principal, found := c.Policy.principalByToken(token); found {
+			return Identity{Name: principal.Name, Method: MethodToken}, true
+		}
+		if c.Token == "" || !tokenEqual(token, c.Token) {
+			return identity, false
+		}
+		if identity.Name == "" {
+			identity = Identity{Name: MethodToken, Method: MethodToken}
+		}
+	}
+	return identity, true
+}
+
+// tokenEqual 以固定时间比较令牌，避免时序攻击
+func tokenEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// BearerToken 从Authorization头中取出Bearer令牌
+func BearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Policy 授权策略文件，把令牌或mTLS身份映射到允许使用的工具、命名空间和SSH主机
+//
+//	principals:
+//	  - name: alert-bot
+//	    token: <令牌>
+//	    read_only: true
+//	    tools: ["*"]
+//	    namespaces: ["*"]
+//	    ssh_hosts: ["*"]
+//	  - name: sre-payments
+//	    token: <令牌>
+//	    tools: ["list_*", "describe_*", "pod_logs", "scale_deployment", "restart_deployment"]
+//	    namespaces: ["payments", "payments-*"]
+type Policy struct {
+	Principals []Principal `json:"principals"`
+	Default    *Principal  `json:"default,omitempty"` // 没有单独配置策略的身份使用的规则，为空时拒绝
+}
+
+// Principal 单个调用方的授权规则，列表中的值支持通配符，空列表表示不允许
+type Principal struct {
+	Name       string   `json:"name"`                  // 调用方名称，使用mTLS时与证书CN匹配
+	Token      string   `json:"token,omitempty"`       // 调用方携带的Bearer令牌
+	ReadOnly   bool     `json:"read_only,omitempty"`   // 是否只允许调用只读工具
+	Tools      []string `json:"tools,omitempty"`       // 允许调用的工具
+	Namespaces []string `json:"namespaces,omitempty"`  // 允许操作的命名空间
+	SSHHosts   []string `json:"ssh_hosts,omitempty"`   // 允许通过SSH访问的主机
+	KubeUser   string   `json:"kube_user,omitempty"`   // 启用模拟时使用的Kubernetes用户，为空时由名称生成
+	KubeGroups []string `json:"kube_groups,omitempty"` // 启用模拟时使用的Kubernetes组，为空时使用全局配置
+}
+
+// LoadPolicy 从YAML或JSON文件加载并校验授权策略
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取授权策略文件失败: %v", err)
+	}
+	var policy Policy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("解析授权策略文件 %s 失败: %v", file, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("授权策略文件 %s 无效: %v", file, err)
+	}
+	return &policy, nil
+}
+
+// validate 检查名称和令牌是否唯一、通配符是否合法
+func (p *Policy) validate() error {
+	names := make(map[string]bool, len(p.Principals))
+	tokens := make(map[string]bool, len(p.Principals))
+	for i, principal := range p.Principals {
+		if principal.Name == "" {
+			return fmt.Errorf("第%d个principal缺少name", i+1)
+		}
+		if names[principal.Name] {
+			return fmt.Errorf("principal名称 %s 重复", principal.Name)
+		}
+		names[principal.Name] = true
+		if principal.Token != "" {
+			if tokens[principal.Token] {
+				return fmt.Errorf("principal %s 的令牌与其他principal重复", principal.Name)
+			}
+			tokens[principal.Token] = true
+		}
+		if err := principal.validatePatterns(); err != nil {
+			return fmt.Errorf("principal %s: %v", principal.Name, err)
+		}
+	}
+	if p.Default != nil {
+		if p.Default.Token != "" {
+			return fmt.Errorf("default规则不能配置令牌")
+		}
+		if err := p.Default.validatePatterns(); err != nil {
+			return fmt.Errorf("default: %v", err)
+		}
+	}
+	return nil
+}
+
+// validatePatterns 检查所有通配符的语法
+func (p Principal) validatePatterns() error {
+	for _, patterns := range [][]string{p.Tools, p.Namespaces, p.SSHHosts} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("通配符 %q 无效: %v", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// HasTokens 策略中是否配置了令牌
+func (p *Policy) HasTokens() bool {
+	if p == nil {
+		return false
+	}
+	for _, principal := range p.Principals {
+		if principal.Token != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// principalByToken 查找令牌对应的调用方
+func (p *Policy) principalByToken(token string) (Principal, bool) {
+	if p == nil {
+		return Principal{}, false
+	}
+	for _, principal := range p.Principals {
+		if principal.Token != "" && tokenEqual(principal.Token, token) {
+			return principal, true
+		}
+	}
+	return Principal{}, false
+}
+
+// Lookup 返回身份对应的授权规则，没有单独配置时使用default规则
+func (p *Policy) Lookup(identity Identity) (Principal, bool) {
+	if p == nil {
+		return Principal{}, false
+	}
+	for _, principal := range p.Principals {
+		if principal.Name == identity.Name {
+			return principal, true
+		}
+	}
+	if p.Default != nil {
+		return *p.Default, true
+	}
+	return Principal{}, false
+}
+
+// AllowTool 是否允许调用工具
+func (p Principal) AllowTool(name string) bool {
+	return matchAny(p.Tools, name)
+}
+
+// AllowNamespace 是否允许操作命名空间
+func (p Principal) AllowNamespace(namespace string) bool {
+	return matchAny(p.Namespaces, namespace)
+}
+
+// AllowAllNamespaces 是否允许操作所有命名空间
+func (p Principal) AllowAllNamespaces() bool {
+	for _, pattern := range p.Namespaces {
+		if pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowSSHHost 是否允许通过SSH访问主机，比较时忽略端口
+func (p Principal) AllowSSHHost(host string) bool {
+	if name, _, found := strings.Cut(host, ":"); found {
+		host = name
+	}
+	return matchAny(p.SSHHosts, host)
+}
+
+// matchAny 检查值是否匹配任一通配符
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
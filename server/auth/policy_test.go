@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{
+		{
+			name: "合法策略",
+			policy: Policy{
+				Principals: []Principal{
+					{Name: "a", Token: "t1", Tools: []string{"list_*"}},
+					{Name: "b", Token: "t2", Namespaces: []string{"payments-*"}},
+				},
+				Default: &Principal{ReadOnly: true, Tools: []string{"*"}},
+			},
+		},
+		{
+			name:    "缺少名称",
+			policy:  Policy{Principals: []Principal{{Token: "t1"}}},
+			wantErr: "缺少name",
+		},
+		{
+			name:    "名称重复",
+			policy:  Policy{Principals: []Principal{{Name: "a"}, {Name: "a"}}},
+			wantErr: "重复",
+		},
+		{
+			name:    "令牌重复",
+			policy:  Policy{Principals: []Principal{{Name: "a", Token: "t"}, {Name: "b", Token: "t"}}},
+			wantErr: "令牌与其他principal重复",
+		},
+		{
+			name:    "default配置令牌",
+			policy:  Policy{Default: &Principal{Token: "t"}},
+			wantErr: "default规则不能配置令牌",
+		},
+		{
+			name:    "通配符无效",
+			policy:  Policy{Principals: []Principal{{Name: "a", SSHHosts: []string{"web-["}}}},
+			wantErr: "通配符",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyRejectsUnknownFields(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte("principals:\n  - name: a\n    namespace: [default]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPolicy(file); err == nil {
+		t.Error("LoadPolicy() 应该拒绝拼写错误的字段")
+	}
+}
+
+func TestPolicyLookup(t *testing.T) {
+	policy := &Policy{Principals: []Principal{{Name: "sre", Tools: []string{"*"}}}}
+
+	if principal, ok := policy.Lookup(Identity{Name: "sre"}); !ok || principal.Name != "sre" {
+		t.Errorf("Lookup(sre) = %+v, %v", principal, ok)
+	}
+	if _, ok := policy.Lookup(Identity{Name: "unknown"}); ok {
+		t.Error("没有default规则时未配置的身份应该被拒绝")
+	}
+
+	policy.Default = &Principal{Name: "default", ReadOnly: true}
+	if principal, ok := policy.Lookup(Identity{Name: "unknown"}); !ok || principal.Name != "default" || !principal.ReadOnly {
+		t.Errorf("Lookup(unknown) = %+v, %v, want default规则", principal, ok)
+	}
+
+	var nilPolicy *Policy
+	if _, ok := nilPolicy.Lookup(Identity{Name: "sre"}); ok {
+		t.Error("nil策略不应该返回任何规则")
+	}
+}
+
+func TestPrincipalNamespaces(t *testing.T) {
+	restricted := Principal{Namespaces: []string{"default", "payments-*"}}
+	all := Principal{Namespaces: []string{"payments", "*"}}
+
+	tests := []struct {
+		principal Principal
+		namespace string
+		want      bool
+	}{
+		{restricted, "default", true},
+		{restricted, "payments-prod", true},
+		{restricted, "payments", false},
+		{restricted, "kube-system", false},
+		{all, "kube-system", true},
+		{Principal{}, "default", false},
+	}
+	for _, tt := range tests {
+		if got := tt.principal.AllowNamespace(tt.namespace); got != tt.want {
+			t.Errorf("%v.AllowNamespace(%q) = %v, want %v", tt.principal.Namespaces, tt.namespace, got, tt.want)
+		}
+	}
+
+	if restricted.AllowAllNamespaces() {
+		t.Error("通配符 payments-* 不代表允许所有命名空间")
+	}
+	if !all.AllowAllNamespaces() {
+		t.Error("包含 * 时应该允许所有命名空间")
+	}
+}
+
+func TestPrincipalAllowSSHHost(t *testing.T) {
+	principal := Principal{SSHHosts: []string{"web-*", "localhost"}}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"web-1", true},
+		{"web-1:2222", true},
+		{"localhost", true},
+		{"db-1", false},
+		{"db-1:22", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := principal.AllowSSHHost(tt.host); got != tt.want {
+			t.Errorf("AllowSSHHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+
+	if (Principal{}).AllowSSHHost("web-1") {
+		t.Error("没有配置ssh_hosts时不应该允许任何主机")
+	}
+}
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// 默认值
+const (
+	DefaultTransport         = "sse"
+	DefaultReadOnlyBehavior  = "hide"
+	DefaultAuditPath         = "logs/mcp-audit.jsonl"
+	DefaultAuditMaxSizeMB    = 100
+	DefaultAuditMaxBackups   = 5
+	DefaultImpersonatePrefix = "mcp:"
+	DefaultImpersonateGroup  = "mcp-devops:clients"
+	DefaultKubeQPS           = 50
+	DefaultKubeBurst         = 100
+	DefaultKubeTimeout       = 30 * time.Second
+	DefaultRedisAddr         = "localhost:6379"
+	DefaultLokiURL           = "http://localhost:3100"
+)
+
+// 工具分组，每组工具可以整体启用或关闭
+const (
+	GroupK8sCore         = "k8s-core"         // Kubernetes资源查看与管理
+	GroupK8sTroubleshoot = "k8s-troubleshoot" // Kubernetes故障诊断与告警分析
+	GroupLinux           = "linux"            // Linux系统与节点组件排查（本地或SSH）
+	GroupRedis           = "redis"            // Redis诊断
+	GroupLoki            = "loki"             // Loki日志查询
+	GroupNotify          = "notify"           // 消息通知
+)
+
+// ToolGroups 所有工具分组
+var ToolGroups = []string{GroupK8sCore, GroupK8sTroubleshoot, GroupLinux, GroupRedis, GroupLoki, GroupNotify}
+
+// Duration 支持在配置文件中以 30s、2m 等字符串表示的时长
+type Duration time.Duration
+
+// UnmarshalJSON 解析时长字符串
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("时长必须是字符串，例如 30s: %v", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON 输出时长字符串
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config 服务器的完整配置
+//
+// 配置来源的优先级从低到高为：默认值、配置文件、环境变量、命令行参数。
+// 收到SIGHUP信号时重新加载，SSH主机清单、Redis实例、Loki地址和通知渠道立即生效，其余配置需要重启。
+type Config struct {
+	Server     ServerConfig     `json:"server"`
+	Auth       AuthConfig       `json:"auth"`
+	Access     AccessConfig     `json:"access"`
+	Audit      AuditConfig      `json:"audit"`
+	Kubernetes KubernetesConfig `json:"kubernetes"`
+	SSH        SSHConfig        `json:"ssh"`
+	Redis      []RedisInstance  `json:"redis,omitempty"`
+	Loki       []LokiEndpoint   `json:"loki,omitempty"`
+	Notifiers  NotifiersConfig  `json:"notifiers"`
+	Tools      ToolsConfig      `json:"tools"`
+}
+
+// ServerConfig 监听配置
+type ServerConfig struct {
+	Address        string `json:"address"`                   // HTTP监听地址
+	Transport      string `json:"transport"`                 // stdio、sse 或 streamable-http
+	MetricsAddress string `json:"metrics_address,omitempty"` // 单独提供/metrics的监听地址，不需要认证；为空时挂载在HTTP监听地址上
+}
+
+// AuthConfig 认证、授权和身份模拟配置
+type AuthConfig struct {
+	Token       string            `json:"token,omitempty"`         // 共享的Bearer令牌
+	TLSCert     string            `json:"tls_cert,omitempty"`      // 服务端证书
+	TLSKey      string            `json:"tls_key,omitempty"`       // 服务端私钥
+	TLSClientCA string            `json:"tls_client_ca,omitempty"` // 客户端CA，配置后启用mTLS
+	PolicyFile  string            `json:"policy_file,omitempty"`   // 授权策略文件
+	Impersonate ImpersonateConfig `json:"impersonate"`
+}
+
+// ImpersonateConfig Kubernetes身份模拟配置
+type ImpersonateConfig struct {
+	Enabled    bool     `json:"enabled"`
+	UserPrefix string   `json:"user_prefix"`
+	Groups     []string `json:"groups"`
+}
+
+// AccessConfig 只读模式配置
+type AccessConfig struct {
+	ReadOnly         bool   `json:"read_only"`
+	ReadOnlyBehavior string `json:"read_only_behavior"` // hide 或 reject
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	Path       string `json:"path"` // 为 off 时关闭审计
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxBackups int    `json:"max_backups"`
+}
+
+// KubernetesConfig 集群访问配置
+type KubernetesConfig struct {
+	Kubeconfig []string `json:"kubeconfig,omitempty"` // kubeconfig文件，所有context均作为可选集群
+	QPS        float32  `json:"qps"`
+	Burst      int      `json:"burst"`
+	Timeout    Duration `json:"timeout"`
+}
+
+// SSHConfig SSH主机清单
+type SSHConfig struct {
+	User    string    `json:"user,omitempty"`     // 默认登录用户
+	KeyPath string    `json:"key_path,omitempty"` // 默认私钥路径
+	Hosts   []SSHHost `json:"hosts,omitempty"`
+}
+
+// SSHHost 单个SSH主机，工具中可以用名称代替地址
+type SSHHost struct {
+	Name    string `json:"name"`
+	Address string `json:"address"` // host或host:port
+	User    string `json:"user,omitempty"`
+	KeyPath string `json:"key_path,omitempty"`
+}
+
+// RedisInstance 单个Redis实例，第一个实例为默认实例
+type RedisInstance struct {
+	Name     string `json:"name"`
+	Addr     string `json:"addr"`
+	Password string `json:"password,omitempty"`
+	DB       int    `json:"db"`
+}
+
+// LokiEndpoint 单个Loki地址，第一个为默认地址
+type LokiEndpoint struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// NotifiersConfig 通知渠道
+type NotifiersConfig struct {
+	WeChat []WeChatChannel `json:"wechat,omitempty"`
+}
+
+// WeChatChannel 企业微信机器人，第一个为默认渠道
+type WeChatChannel struct {
+	Name       string `json:"name"`
+	WebhookURL string `json:"webhook_url"`
+}
+
+// ToolsConfig 工具分组配置
+type ToolsConfig struct {
+	// Groups 显式启用(true)或关闭(false)的分组，未列出的分组在后端可达时启用
+	Groups map[string]bool `json:"groups,omitempty"`
+	// ProbeBackends 启动时探测Kubernetes、Redis和Loki，后端不可达的分组自动关闭，显式启用的分组不受影响
+	ProbeBackends bool `json:"probe_backends"`
+}
+
+// GroupSetting 返回分组的显式配置，explicit为false表示未配置
+func (c ToolsConfig) GroupSetting(group string) (enabled, explicit bool) {
+	enabled, explicit = c.Groups[group]
+	return enabled, explicit
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{Transport: DefaultTransport},
+		Auth: AuthConfig{
+			Impersonate: ImpersonateConfig{
+				UserPrefix: DefaultImpersonatePrefix,
+				Groups:     []string{DefaultImpersonateGroup},
+			},
+		},
+		Access: AccessConfig{ReadOnlyBehavior: DefaultReadOnlyBehavior},
+		Audit: AuditConfig{
+			Path:       DefaultAuditPath,
+			MaxSizeMB:  DefaultAuditMaxSizeMB,
+			MaxBackups: DefaultAuditMaxBackups,
+		},
+		Kubernetes: KubernetesConfig{
+			QPS:     DefaultKubeQPS,
+			Burst:   DefaultKubeBurst,
+			Timeout: Duration(DefaultKubeTimeout),
+		},
+		Tools: ToolsConfig{ProbeBackends: true},
+	}
+}
+
+// Load 依次加载默认值、配置文件（path为空时跳过）、环境变量和overrides（通常来自命令行参数），并校验结果
+func Load(path string, overrides ...func(*Config)) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %v", err)
+		}
+		if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+			return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		override(cfg)
+	}
+	cfg.applyDefaults()
+	if err := cfg.Validate(); err != nil {
+		if path != "" {
+			return nil, fmt.Errorf("配置文件 %s 无效: %v", path, err)
+		}
+		return nil, fmt.Errorf("配置无效: %v", err)
+	}
+	return cfg, nil
+}
+
+// applyDefaults 为未配置的后端填充默认实例，与没有配置文件时的行为保持一致
+func (c *Config) applyDefaults() {
+	if len(c.Redis) == 0 {
+		c.Redis = []RedisInstance{{Name: "default", Addr: DefaultRedisAddr}}
+	}
+	if len(c.Loki) == 0 {
+		c.Loki = []LokiEndpoint{{Name: "default", URL: DefaultLokiURL}}
+	}
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	switch c.Server.Transport {
+	case "stdio", "sse", "streamable-http":
+	default:
+		return fmt.Errorf("server.transport 的值 %q 无效，可选值为 stdio、sse 或 streamable-http", c.Server.Transport)
+	}
+	if c.Server.Transport != "stdio" && c.Server.Address == "" {
+		return fmt.Errorf("HTTP传输方式必须配置 server.address、MCP_SERVER_ADDRESS 或 --address")
+	}
+
+	if (c.Auth.TLSCert == "") != (c.Auth.TLSKey == "") {
+		return fmt.Errorf("auth.tls_cert 和 auth.tls_key 必须同时配置")
+	}
+	if c.Auth.TLSClientCA != "" && c.Auth.TLSCert == "" {
+		return fmt.Errorf("配置 auth.tls_client_ca 时必须同时配置 auth.tls_cert 和 auth.tls_key")
+	}
+
+	if c.Access.ReadOnlyBehavior != "hide" && c.Access.ReadOnlyBehavior != "reject" {
+		return fmt.Errorf("access.read_only_behavior 的值 %q 无效，可选值为 hide 或 reject", c.Access.ReadOnlyBehavior)
+	}
+
+	if c.Audit.MaxSizeMB <= 0 {
+		return fmt.Errorf("audit.max_size_mb 必须大于0")
+	}
+	if c.Audit.MaxBackups < 0 {
+		return fmt.Errorf("audit.max_backups 不能小于0")
+	}
+
+	if c.Kubernetes.QPS <= 0 || c.Kubernetes.Burst <= 0 || c.Kubernetes.Timeout <= 0 {
+		return fmt.Errorf("kubernetes.qps、kubernetes.burst 和 kubernetes.timeout 必须大于0")
+	}
+
+	for group := range c.Tools.Groups {
+		if !knownGroup(group) {
+			return fmt.Errorf("tools.groups 中的分组 %q 不存在，可选值为 %s", group, strings.Join(ToolGroups, "、"))
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, host := range c.SSH.Hosts {
+		if host.Name == "" || host.Address == "" {
+			return fmt.Errorf("ssh.hosts 第%d项必须配置 name 和 address", i+1)
+		}
+		if names[host.Name] {
+			return fmt.Errorf("ssh.hosts 中的名称 %s 重复", host.Name)
+		}
+		names[host.Name] = true
+	}
+
+	names = make(map[string]bool)
+	for i, instance := range c.Redis {
+		if instance.Name == "" || instance.Addr == "" {
+			return fmt.Errorf("redis 第%d项必须配置 name 和 addr", i+1)
+		}
+		if names[instance.Name] {
+			return fmt.Errorf("redis 中的名称 %s 重复", instance.Name)
+		}
+		names[instance.Name] = true
+	}
+
+	names = make(map[string]bool)
+	for i, endpoint := range c.Loki {
+		if endpoint.Name == "" || endpoint.URL == "" {
+			return fmt.Errorf("loki 第%d项必须配置 name 和 url", i+1)
+		}
+		if err := validateURL(endpoint.URL); err != nil {
+			return fmt.Errorf("loki %s 的地址无效: %v", endpoint.Name, err)
+		}
+		if names[endpoint.Name] {
+			return fmt.Errorf("loki 中的名称 %s 重复", endpoint.Name)
+		}
+		names[endpoint.Name] = true
+	}
+
+	names = make(map[string]bool)
+	for i, channel := range c.Notifiers.WeChat {
+		if channel.Name == "" || channel.WebhookURL == "" {
+			return fmt.Errorf("notifiers.wechat 第%d项必须配置 name 和 webhook_url", i+1)
+		}
+		if err := validateURL(channel.WebhookURL); err != nil {
+			return fmt.Errorf("notifiers.wechat %s 的地址无效: %v", channel.Name, err)
+		}
+		if names[channel.Name] {
+			return fmt.Errorf("notifiers.wechat 中的名称 %s 重复", channel.Name)
+		}
+		names[channel.Name] = true
+	}
+	return nil
+}
+
+// runtimeSections 重新加载后立即生效的配置项，对应Config字段的json名称
+var runtimeSections = map[string]bool{"ssh": true, "redis": true, "loki": true, "notifiers": true}
+
+// RestartRequired 返回next相对当前配置有变化、但需要重启才能生效的配置项
+func (c *Config) RestartRequired(next *Config) []string {
+	var changed []string
+	current, updated := reflect.ValueOf(c).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		name := strings.Split(current.Type().Field(i).Tag.Get("json"), ",")[0]
+		if runtimeSections[name] {
+			continue
+		}
+		if !reflect.DeepEqual(current.Field(i).Interface(), updated.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// knownGroup 是否为已知的工具分组
+func knownGroup(group string) bool {
+	for _, g := range ToolGroups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// validateURL 检查是否为http或https地址
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("只支持http或https地址")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("缺少主机名")
+	}
+	return nil
+}
+
+// AuditEnabled 是否启用审计日志
+func (c AuditConfig) AuditEnabled() bool {
+	return c.Path != "" && !strings.EqualFold(c.Path, "off")
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig 写入临时配置文件并返回路径
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// addressFlag 模拟命令行参数 --address
+func addressFlag(address string) func(*Config) {
+	return func(cfg *Config) {
+		if address != "" {
+			cfg.Server.Address = address
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeConfig(t, "server:\n  address: file:1\n  transport: sse\naudit:\n  max_backups: 2\n")
+
+	tests := []struct {
+		name string
+		path string
+		env  string
+		flag string
+		want string
+	}{
+		{"只有配置文件", file, "", "", "file:1"},
+		{"环境变量覆盖配置文件", file, "env:2", "", "env:2"},
+		{"命令行参数覆盖环境变量", file, "env:2", "flag:3", "flag:3"},
+		{"命令行参数覆盖配置文件", file, "", "flag:3", "flag:3"},
+		{"没有配置文件", "", "env:2", "", "env:2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MCP_SERVER_ADDRESS", tt.env)
+			cfg, err := Load(tt.path, addressFlag(tt.flag))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server.Address != tt.want {
+				t.Errorf("server.address = %q, want %q", cfg.Server.Address, tt.want)
+			}
+		})
+	}
+
+	// 配置文件中未出现的字段保留默认值，出现的字段覆盖默认值
+	t.Setenv("MCP_SERVER_ADDRESS", "")
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Audit.MaxBackups != 2 || cfg.Audit.MaxSizeMB != DefaultAuditMaxSizeMB {
+		t.Errorf("audit = %+v, want max_backups 2 和默认 max_size_mb", cfg.Audit)
+	}
+	if len(cfg.Redis) != 1 || cfg.Redis[0].Addr != DefaultRedisAddr || len(cfg.Loki) != 1 || cfg.Loki[0].URL != DefaultLokiURL {
+		t.Errorf("未配置的后端应该使用默认实例: redis = %+v, loki = %+v", cfg.Redis, cfg.Loki)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{"未知字段", "server:\n  adress: :8080\n", nil, "解析配置文件"},
+		{"传输方式无效", "server:\n  address: :8080\n  transport: grpc\n", nil, "server.transport"},
+		{"HTTP缺少地址", "server:\n  transport: sse\n", nil, "server.address"},
+		{"证书缺少私钥", "server:\n  address: :8080\nauth:\n  tls_cert: cert.pem\n", nil, "tls_key"},
+		{"工具分组不存在", "server:\n  address: :8080\ntools:\n  groups:\n    mysql: true\n", nil, "mysql"},
+		{"SSH主机名称重复", "server:\n  address: :8080\nssh:\n  hosts:\n    - {name: web, address: 10.0.0.1}\n    - {name: web, address: 10.0.0.2}\n", nil, "名称 web 重复"},
+		{"Loki地址无效", "server:\n  address: :8080\nloki:\n  - {name: default, url: 'localhost:3100'}\n", nil, "loki default"},
+		{"环境变量无效", "server:\n  address: :8080\n", map[string]string{"MCP_AUDIT_MAX_BACKUPS": "many"}, "MCP_AUDIT_MAX_BACKUPS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	base := func() *Config {
+		cfg := Default()
+		cfg.Server.Address = ":8080"
+		cfg.applyDefaults()
+		return cfg
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{"没有变化", func(*Config) {}, nil},
+		{"SSH主机清单立即生效", func(c *Config) { c.SSH.Hosts = []SSHHost{{Name: "web", Address: "10.0.0.1"}} }, nil},
+		{"Redis实例立即生效", func(c *Config) { c.Redis[0].Addr = "redis:6379" }, nil},
+		{"Loki地址立即生效", func(c *Config) { c.Loki = append(c.Loki, LokiEndpoint{Name: "prod", URL: "http://loki"}) }, nil},
+		{"通知渠道立即生效", func(c *Config) { c.Notifiers.WeChat = []WeChatChannel{{Name: "ops", WebhookURL: "http://hook"}} }, nil},
+		{"监听地址需要重启", func(c *Config) { c.Server.Address = ":9090" }, []string{"server"}},
+		{"只读模式需要重启", func(c *Config) { c.Access.ReadOnly = true }, []string{"access"}},
+		{"认证和审计需要重启", func(c *Config) { c.Auth.PolicyFile = "policy.yaml"; c.Audit.Path = "off" }, []string{"auth", "audit"}},
+		{"集群访问和工具分组需要重启", func(c *Config) { c.Kubernetes.QPS = 10; c.Tools.ProbeBackends = false }, []string{"kubernetes", "tools"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := base()
+			tt.modify(next)
+			if got := base().RestartRequired(next); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RestartRequired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuntimeSectionsExist(t *testing.T) {
+	// 防止字段改名后runtimeSections中的名称失效，导致配置被误判为需要重启
+	fields := make(map[string]bool)
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		fields[strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	for name := range runtimeSections {
+		if !fields[name] {
+			t.Errorf("runtimeSections 中的 %s 不是Config的字段", name)
+		}
+	}
+}
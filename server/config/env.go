@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv 用环境变量覆盖配置文件中的值，环境变量名与之前的版本保持一致
+func (c *Config) applyEnv() error {
+	if v := os.Getenv("MCP_SERVER_ADDRESS"); v != "" {
+		c.Server.Address = v
+	}
+	if v := os.Getenv("MCP_TRANSPORT"); v != "" {
+		c.Server.Transport = v
+	}
+	if v := os.Getenv("MCP_METRICS_ADDRESS"); v != "" {
+		c.Server.MetricsAddress = v
+	}
+
+	// 认证与授权
+	if v := strings.TrimSpace(os.Getenv("MCP_API_TOKEN")); v != "" {
+		c.Auth.Token = v
+	}
+	if v := os.Getenv("MCP_TLS_CERT"); v != "" {
+		c.Auth.TLSCert = v
+	}
+	if v := os.Getenv("MCP_TLS_KEY"); v != "" {
+		c.Auth.TLSKey = v
+	}
+	if v := os.Getenv("MCP_TLS_CLIENT_CA"); v != "" {
+		c.Auth.TLSClientCA = v
+	}
+	if v := strings.TrimSpace(os.Getenv("MCP_AUTH_POLICY")); v != "" {
+		c.Auth.PolicyFile = v
+	}
+	if err := envBool("MCP_IMPERSONATE", &c.Auth.Impersonate.Enabled); err != nil {
+		return err
+	}
+	if v, ok := os.LookupEnv("MCP_IMPERSONATE_USER_PREFIX"); ok {
+		c.Auth.Impersonate.UserPrefix = v
+	}
+	if v, ok := os.LookupEnv("MCP_IMPERSONATE_GROUPS"); ok {
+		c.Auth.Impersonate.Groups = splitList(v, ",")
+	}
+
+	// 只读模式
+	if err := envBool("MCP_READ_ONLY", &c.Access.ReadOnly); err != nil {
+		return err
+	}
+	if v := strings.ToLower(os.Getenv("MCP_READ_ONLY_BEHAVIOR")); v != "" {
+		c.Access.ReadOnlyBehavior = v
+	}
+
+	// 审计日志
+	if v := strings.TrimSpace(os.Getenv("MCP_AUDIT_LOG")); v != "" {
+		c.Audit.Path = v
+	}
+	if err := envInt("MCP_AUDIT_MAX_SIZE_MB", &c.Audit.MaxSizeMB); err != nil {
+		return err
+	}
+	if err := envInt("MCP_AUDIT_MAX_BACKUPS", &c.Audit.MaxBackups); err != nil {
+		return err
+	}
+
+	// Kubernetes
+	if v := os.Getenv("KUBECONFIG"); v != "" {
+		c.Kubernetes.Kubeconfig = splitList(v, string(filepath.ListSeparator))
+	}
+	if v := os.Getenv("K8S_CLIENT_QPS"); v != "" {
+		qps, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return fmt.Errorf("K8S_CLIENT_QPS 的值 %q 无效: %v", v, err)
+		}
+		c.Kubernetes.QPS = float32(qps)
+	}
+	if err := envInt("K8S_CLIENT_BURST", &c.Kubernetes.Burst); err != nil {
+		return err
+	}
+	if v := os.Getenv("K8S_CLIENT_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("K8S_CLIENT_TIMEOUT 的值 %q 无效: %v", v, err)
+		}
+		c.Kubernetes.Timeout = Duration(timeout)
+	}
+
+	// SSH
+	if v := os.Getenv("SSH_USER"); v != "" {
+		c.SSH.User = v
+	}
+	if v := os.Getenv("SSH_KEY_PATH"); v != "" {
+		c.SSH.KeyPath = v
+	}
+
+	// Redis 环境变量覆盖默认实例
+	if addr, password, db := os.Getenv("REDIS_ADDR"), os.Getenv("REDIS_PASSWORD"), os.Getenv("REDIS_DB"); addr != "" || password != "" || db != "" {
+		if len(c.Redis) == 0 {
+			c.Redis = []RedisInstance{{Name: "default", Addr: DefaultRedisAddr}}
+		}
+		if addr != "" {
+			c.Redis[0].Addr = addr
+		}
+		if password != "" {
+			c.Redis[0].Password = password
+		}
+		if err := envInt("REDIS_DB", &c.Redis[0].DB); err != nil {
+			return err
+		}
+	}
+
+	// Loki 环境变量覆盖默认地址
+	if v := os.Getenv("LOKI_URL"); v != "" {
+		if len(c.Loki) == 0 {
+			c.Loki = []LokiEndpoint{{Name: "default"}}
+		}
+		c.Loki[0].URL = v
+	}
+
+	// 企业微信 环境变量覆盖默认渠道
+	if v := os.Getenv("WECHAT_WEBHOOK_URL"); v != "" {
+		if len(c.Notifiers.WeChat) == 0 {
+			c.Notifiers.WeChat = []WeChatChannel{{Name: "default"}}
+		}
+		c.Notifiers.WeChat[0].WebhookURL = v
+	}
+
+	// 工具分组
+	if v, ok := os.LookupEnv("MCP_DISABLED_TOOL_GROUPS"); ok {
+		for _, group := range splitList(v, ",") {
+			if c.Tools.Groups == nil {
+				c.Tools.Groups = make(map[string]bool)
+			}
+			c.Tools.Groups[group] = false
+		}
+	}
+	if err := envBool("MCP_PROBE_BACKENDS", &c.Tools.ProbeBackends); err != nil {
+		return err
+	}
+	return nil
+}
+
+// envBool 读取布尔型环境变量，未设置时保持原值
+func envBool(key string, target *bool) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s 的值 %q 无效: %v", key, v, err)
+	}
+	*target = b
+	return nil
+}
+
+// envInt 读取整型环境变量，未设置时保持原值
+func envInt(key string, target *int) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s 的值 %q 无效: %v", key, v, err)
+	}
+	*target = n
+	return nil
+}
+
+// splitList 按分隔符拆分并去掉空白项
+func splitList(s, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,55 +1,685 @@
 package k8s
 
 import (
+	"container/list"
+	"context"
 	"fmt"
+	"mcp-devops/server/metrics"
+	"mcp-devops/server/tracing"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-// CreateK8sClient 创建Kubernetes客户端
-func CreateK8sClient() (*kubernetes.Clientset, error) {
-	// 尝试获取集群内部配置
-	config, err := rest.InClusterConfig()
-	if err == nil {
-		// 成功获取集群内配置
-		clientset, err := kubernetes.NewForConfig(config)
+// 客户端默认参数
+const (
+	defaultClientQPS       = 50
+	defaultClientBurst     = 100
+	defaultClientTimeout   = 30 * time.Second
+	defaultRefreshInterval = 30 * time.Second
+)
+
+// maxImpersonatedClients 每个集群按模拟身份缓存的客户端上限，超过后淘汰最久未使用的
+const maxImpersonatedClients = 64
+
+// InClusterName 使用集群内ServiceAccount配置时的集群名称
+const InClusterName = "in-cluster"
+
+// ClientOptions Kubernetes客户端的构建参数
+type ClientOptions struct {
+	Kubeconfig      string        // kubeconfig路径，可用系统路径分隔符指定多个；为空时优先使用集群内配置，再回退到 ~/.kube/config
+	QPS             float32       // 客户端限流QPS
+	Burst           int           // 客户端限流突发值
+	Timeout         time.Duration // 单个API请求的超时时间
+	RefreshInterval time.Duration // 检查凭据文件是否变化的最小间隔
+}
+
+// ClientOptionsFromEnv 从环境变量读取客户端参数
+// 支持 KUBECONFIG、K8S_CLIENT_QPS、K8S_CLIENT_BURST、K8S_CLIENT_TIMEOUT
+func ClientOptionsFromEnv() ClientOptions {
+	opts := ClientOptions{
+		Kubeconfig:      os.Getenv("KUBECONFIG"),
+		QPS:             defaultClientQPS,
+		Burst:           defaultClientBurst,
+		Timeout:         defaultClientTimeout,
+		RefreshInterval: defaultRefreshInterval,
+	}
+
+	if v, err := strconv.ParseFloat(os.Getenv("K8S_CLIENT_QPS"), 32); err == nil && v > 0 {
+		opts.QPS = float32(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv("K8S_CLIENT_BURST")); err == nil && v > 0 {
+		opts.Burst = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("K8S_CLIENT_TIMEOUT")); err == nil && v > 0 {
+		opts.Timeout = v
+	}
+
+	return opts
+}
+
+// withDefaults 为未设置的参数填充默认值
+func (o ClientOptions) withDefaults() ClientOptions {
+	if o.QPS <= 0 {
+		o.QPS = defaultClientQPS
+	}
+	if o.Burst <= 0 {
+		o.Burst = defaultClientBurst
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = defaultClientTimeout
+	}
+	if o.RefreshInterval <= 0 {
+		o.RefreshInterval = defaultRefreshInterval
+	}
+	return o
+}
+
+// configLoader 加载REST配置，并返回需要监测轮换的凭据文件
+type configLoader func() (*rest.Config, []string, error)
+
+// ClientProvider 为单个集群构建并缓存Kubernetes客户端
+// 客户端只构建一次，当kubeconfig文件或集群内ServiceAccount token发生变化时自动重建
+type ClientProvider struct {
+	name string
+	opts ClientOptions
+	load configLoader
+
+	mu                  sync.RWMutex
+	config              *rest.Config
+	clientset           *kubernetes.Clientset
+	dynamicClient       dynamic.Interface
+	mapper              meta.ResettableRESTMapper           // 基于发现接口的资源映射，支持资源简称，首次使用时加载并缓存
+	impersonated        *clientCache[*kubernetes.Clientset] // 以调用方身份模拟的客户端，凭据变化时随主客户端一起重建
+	impersonatedDynamic *clientCache[dynamic.Interface]     // 以调用方身份模拟的动态客户端
+	credentialMods      map[string]time.Time                // 凭据文件及其最后修改时间
+	lastCheck           time.Time
+}
+
+// newClientProvider 创建单个集群的客户端提供者
+func newClientProvider(name string, opts ClientOptions, load configLoader) *ClientProvider {
+	return &ClientProvider{name: name, opts: opts.withDefaults(), load: load}
+}
+
+// Name 返回集群名称
+func (p *ClientProvider) Name() string {
+	return p.name
+}
+
+// Clientset 返回缓存的客户端，必要时构建或刷新
+func (p *ClientProvider) Clientset() (*kubernetes.Clientset, error) {
+	if err := p.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.clientset, nil
+}
+
+// ImpersonatedClientset 返回以指定用户和组身份模拟访问的客户端，按身份缓存
+func (p *ClientProvider) ImpersonatedClientset(impersonate rest.ImpersonationConfig) (*kubernetes.Clientset, error) {
+	if err := p.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	// 读取缓存会更新使用顺序，需要持有写锁
+	key := impersonationKey(impersonate)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if clientset, ok := p.impersonated.get(key); ok {
+		return clientset, nil
+	}
+	config := rest.CopyConfig(p.config)
+	config.Impersonate = impersonate
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建模拟用户 %s 的客户端失败: %v", impersonate.UserName, err)
+	}
+	p.impersonated.add(key, clientset)
+	return clientset, nil
+}
+
+// DynamicClient 返回缓存的动态客户端，用于访问任意资源类型（包括CRD）
+func (p *ClientProvider) DynamicClient() (dynamic.Interface, error) {
+	if err := p.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.dynamicClient, nil
+}
+
+// ImpersonatedDynamicClient 返回以指定用户和组身份模拟访问的动态客户端，按身份缓存
+func (p *ClientProvider) ImpersonatedDynamicClient(impersonate rest.ImpersonationConfig) (dynamic.Interface, error) {
+	if err := p.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	key := impersonationKey(impersonate)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if client, ok := p.impersonatedDynamic.get(key); ok {
+		return client, nil
+	}
+	config := rest.CopyConfig(p.config)
+	config.Impersonate = impersonate
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建模拟用户 %s 的动态客户端失败: %v", impersonate.UserName, err)
+	}
+	p.impersonatedDynamic.add(key, client)
+	return client, nil
+}
+
+// RESTMapper 返回把资源名称、简称和Kind解析为GroupVersionResource的映射器
+// 发现信息以服务器自身身份获取并缓存，找不到资源时调用方可以Reset后重试以加载新安装的CRD
+func (p *ClientProvider) RESTMapper() (meta.ResettableRESTMapper, error) {
+	if err := p.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.mapper, nil
+}
+
+// impersonationKey 生成模拟身份的缓存键
+func impersonationKey(impersonate rest.ImpersonationConfig) string {
+	groups := append([]string(nil), impersonate.Groups...)
+	sort.Strings(groups)
+	return impersonate.UserName + "\x00" + strings.Join(groups, ",")
+}
+
+// clientCache 按模拟身份缓存客户端，数量超过上限时淘汰最久未使用的，调用方需持有写锁
+type clientCache[T any] struct {
+	limit int
+	order *list.List               // 从最近使用到最久未使用的缓存项
+	items map[string]*list.Element // 缓存键到order中元素的映射
+}
+
+// clientCacheEntry 缓存项
+type clientCacheEntry[T any] struct {
+	key   string
+	value T
+}
+
+// newClientCache 创建容量为limit的客户端缓存
+func newClientCache[T any](limit int) *clientCache[T] {
+	return &clientCache[T]{limit: limit, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get 返回缓存的客户端并标记为最近使用
+func (c *clientCache[T]) get(key string) (T, bool) {
+	element, ok := c.items[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*clientCacheEntry[T]).value, true
+}
+
+// add 缓存客户端，超过上限时淘汰最久未使用的
+func (c *clientCache[T]) add(key string, value T) {
+	if element, ok := c.items[key]; ok {
+		element.Value.(*clientCacheEntry[T]).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.order.PushFront(&clientCacheEntry[T]{key: key, value: value})
+	for c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*clientCacheEntry[T]).key)
+	}
+}
+
+// len 返回缓存的客户端数量
+func (c *clientCache[T]) len() int {
+	return c.order.Len()
+}
+
+// RESTConfig 返回当前使用的REST配置副本
+func (p *ClientProvider) RESTConfig() (*rest.Config, error) {
+	if err := p.ensureFresh(); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return rest.CopyConfig(p.config), nil
+}
+
+// ensureFresh 首次调用时构建客户端，之后按间隔检查凭据文件是否轮换
+func (p *ClientProvider) ensureFresh() error {
+	p.mu.RLock()
+	built := p.clientset != nil
+	checkDue := time.Since(p.lastCheck) >= p.opts.RefreshInterval
+	p.mu.RUnlock()
+
+	if built && !checkDue {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 双重检查，避免并发请求重复构建
+	if p.clientset != nil && time.Since(p.lastCheck) < p.opts.RefreshInterval {
+		return nil
+	}
+	p.lastCheck = time.Now()
+
+	if p.clientset != nil && !p.credentialChanged() {
+		return nil
+	}
+
+	if p.clientset != nil {
+		fmt.Printf("检测到集群 %s 的凭据文件发生变化，重新构建客户端\n", p.name)
+	}
+	return p.build()
+}
+
+// credentialChanged 检查凭据文件的修改时间是否变化，调用方需持有写锁
+func (p *ClientProvider) credentialChanged() bool {
+	for file, mod := range p.credentialMods {
+		info, err := os.Stat(file)
 		if err != nil {
-			return nil, fmt.Errorf("从集群内配置创建客户端失败: %v", err)
+			continue
+		}
+		if !info.ModTime().Equal(mod) {
+			return true
+		}
+	}
+	return false
+}
+
+// build 构建REST配置与客户端，调用方需持有写锁
+func (p *ClientProvider) build() error {
+	config, credentialFiles, err := p.load()
+	if err != nil {
+		return err
+	}
+
+	config.QPS = p.opts.QPS
+	config.Burst = p.opts.Burst
+	config.Timeout = p.opts.Timeout
+	config.Wrap(metrics.KubernetesTransport(p.name))
+	config.Wrap(tracing.KubernetesTransport(p.name))
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("创建客户端失败: %v", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("创建动态客户端失败: %v", err)
+	}
+
+	p.config = config
+	p.clientset = clientset
+	p.dynamicClient = dynamicClient
+	cachedDiscovery := memory.NewMemCacheClient(clientset.Discovery())
+	p.mapper = restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscovery), cachedDiscovery, nil).(meta.ResettableRESTMapper)
+	p.impersonated = newClientCache[*kubernetes.Clientset](maxImpersonatedClients)
+	p.impersonatedDynamic = newClientCache[dynamic.Interface](maxImpersonatedClients)
+	p.credentialMods = make(map[string]time.Time, len(credentialFiles))
+	for _, file := range credentialFiles {
+		if info, err := os.Stat(file); err == nil {
+			p.credentialMods[file] = info.ModTime()
+		}
+	}
+	return nil
+}
+
+// ClusterInfo 集群的基本信息
+type ClusterInfo struct {
+	Name      string // 集群名称，即kubeconfig中的context名称
+	Server    string // API Server地址
+	Namespace string // context中配置的默认命名空间
+	User      string // context使用的用户
+	Default   bool   // 是否为未指定cluster参数时使用的默认集群
+}
+
+// ClusterManager 管理kubeconfig中所有context对应的集群客户端
+// 每个集群的客户端按需构建并缓存
+type ClusterManager struct {
+	opts      ClientOptions
+	paths     []string // kubeconfig文件列表
+	inCluster bool     // 是否可以使用集群内配置
+
+	mu        sync.Mutex
+	providers map[string]*ClientProvider
+}
+
+// NewClusterManager 创建集群管理器
+func NewClusterManager(opts ClientOptions) *ClusterManager {
+	opts = opts.withDefaults()
+	m := &ClusterManager{
+		opts:      opts,
+		providers: make(map[string]*ClientProvider),
+	}
+
+	if opts.Kubeconfig != "" {
+		for _, path := range filepath.SplitList(opts.Kubeconfig) {
+			if path != "" {
+				m.paths = append(m.paths, path)
+			}
+		}
+	} else {
+		// 未显式指定kubeconfig时，优先使用集群内部配置
+		if _, err := rest.InClusterConfig(); err == nil {
+			m.inCluster = true
+		}
+		// 同时加载默认位置的kubeconfig
+		if home, err := os.UserHomeDir(); err == nil {
+			m.paths = append(m.paths, filepath.Join(home, ".kube", "config"))
+		}
+	}
+	return m
+}
+
+// loadingRules 构建kubeconfig加载规则，多个文件按顺序合并
+func (m *ClusterManager) loadingRules() *clientcmd.ClientConfigLoadingRules {
+	return &clientcmd.ClientConfigLoadingRules{Precedence: m.paths}
+}
+
+// existingPaths 返回实际存在的kubeconfig文件
+func (m *ClusterManager) existingPaths() []string {
+	var paths []string
+	for _, path := range m.paths {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// rawConfig 读取并合并所有kubeconfig文件
+func (m *ClusterManager) rawConfig() (*clientcmdapi.Config, error) {
+	if len(m.existingPaths()) == 0 {
+		return clientcmdapi.NewConfig(), nil
+	}
+	config, err := m.loadingRules().Load()
+	if err != nil {
+		return nil, fmt.Errorf("加载kubeconfig失败: %v", err)
+	}
+	return config, nil
+}
+
+// Clusters 列出所有可用的集群
+func (m *ClusterManager) Clusters() ([]ClusterInfo, error) {
+	var clusters []ClusterInfo
+	if m.inCluster {
+		config, err := rest.InClusterConfig()
+		if err == nil {
+			clusters = append(clusters, ClusterInfo{Name: InClusterName, Server: config.Host, Default: true})
 		}
-		return clientset, nil
 	}
 
-	// 如果不在集群内，尝试使用kubeconfig
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		// 使用默认位置
-		home, err := os.UserHomeDir()
+	raw, err := m.rawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(raw.Contexts))
+	for name := range raw.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		kubeContext := raw.Contexts[name]
+		info := ClusterInfo{
+			Name:      name,
+			Namespace: kubeContext.Namespace,
+			User:      kubeContext.AuthInfo,
+			Default:   !m.inCluster && name == raw.CurrentContext,
+		}
+		if cluster, ok := raw.Clusters[kubeContext.Cluster]; ok {
+			info.Server = cluster.Server
+		}
+		clusters = append(clusters, info)
+	}
+
+	if len(clusters) == 0 {
+		if len(m.existingPaths()) == 0 {
+			return nil, fmt.Errorf("kubeconfig文件 %v 不存在，且不在集群内运行", m.paths)
+		}
+		return nil, fmt.Errorf("kubeconfig中没有可用的context")
+	}
+	return clusters, nil
+}
+
+// defaultCluster 返回默认集群名称
+func (m *ClusterManager) defaultCluster() (string, error) {
+	if m.inCluster {
+		return InClusterName, nil
+	}
+	if len(m.existingPaths()) == 0 {
+		return "", fmt.Errorf("kubeconfig文件 %v 不存在，且不在集群内运行", m.paths)
+	}
+	raw, err := m.rawConfig()
+	if err != nil {
+		return "", err
+	}
+	if raw.CurrentContext == "" {
+		return "", fmt.Errorf("kubeconfig未设置current-context，请通过cluster参数指定集群")
+	}
+	return raw.CurrentContext, nil
+}
+
+// Provider 获取指定集群的客户端提供者，name为空时使用默认集群
+func (m *ClusterManager) Provider(name string) (*ClientProvider, error) {
+	if name == "" {
+		defaultName, err := m.defaultCluster()
 		if err != nil {
-			return nil, fmt.Errorf("获取用户home目录失败: %v", err)
+			return nil, err
 		}
-		kubeconfig = filepath.Join(home, ".kube", "config")
+		name = defaultName
 	}
 
-	// 检查kubeconfig文件是否存在
-	if _, err := os.Stat(kubeconfig); os.IsNotExist(err) {
-		return nil, fmt.Errorf("kubeconfig文件 %s 不存在", kubeconfig)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if provider, ok := m.providers[name]; ok {
+		return provider, nil
 	}
 
-	// 使用kubeconfig创建配置
-	config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err := m.checkCluster(name); err != nil {
+		return nil, err
+	}
+
+	provider := newClientProvider(name, m.opts, m.loader(name))
+	m.providers[name] = provider
+	return provider, nil
+}
+
+// Ping 检查默认集群是否可达，用于启动时探测Kubernetes后端
+func (m *ClusterManager) Ping() error {
+	provider, err := m.Provider("")
+	if err != nil {
+		return err
+	}
+	clientset, err := provider.Clientset()
 	if err != nil {
-		return nil, fmt.Errorf("从kubeconfig构建配置失败: %v", err)
+		return err
 	}
+	_, err = clientset.Discovery().ServerVersion()
+	return err
+}
 
-	// 创建客户端
-	clientset, err := kubernetes.NewForConfig(config)
+// checkCluster 检查集群名称是否存在
+func (m *ClusterManager) checkCluster(name string) error {
+	if name == InClusterName && m.inCluster {
+		return nil
+	}
+	raw, err := m.rawConfig()
 	if err != nil {
-		return nil, fmt.Errorf("创建客户端失败: %v", err)
+		return err
+	}
+	if _, ok := raw.Contexts[name]; ok {
+		return nil
 	}
 
-	return clientset, nil
+	available := make([]string, 0, len(raw.Contexts))
+	for contextName := range raw.Contexts {
+		available = append(available, contextName)
+	}
+	if m.inCluster {
+		available = append(available, InClusterName)
+	}
+	sort.Strings(available)
+	return fmt.Errorf("集群 %s 不存在，可用的集群: %v", name, available)
+}
+
+// loader 返回指定集群的配置加载函数
+func (m *ClusterManager) loader(name string) configLoader {
+	if name == InClusterName && m.inCluster {
+		return func() (*rest.Config, []string, error) {
+			config, err := rest.InClusterConfig()
+			if err != nil {
+				return nil, nil, fmt.Errorf("加载集群内配置失败: %v", err)
+			}
+			return config, []string{config.BearerTokenFile}, nil
+		}
+	}
+
+	return func() (*rest.Config, []string, error) {
+		clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			m.loadingRules(),
+			&clientcmd.ConfigOverrides{CurrentContext: name},
+		)
+		config, err := clientConfig.ClientConfig()
+		if err != nil {
+			return nil, nil, fmt.Errorf("从kubeconfig构建集群 %s 的配置失败: %v", name, err)
+		}
+		return config, m.existingPaths(), nil
+	}
+}
+
+// 上下文中保存的键
+type (
+	managerKey       struct{}
+	clusterKey       struct{}
+	impersonationCtx struct{}
+	restrictedCtx    struct{}
+)
+
+// defaultManager 未通过上下文注入时使用的集群管理器
+var (
+	defaultManager     *ClusterManager
+	defaultManagerOnce sync.Once
+)
+
+// WithClusterManager 返回一个工具中间件，把ClusterManager和本次调用指定的cluster参数注入到上下文中
+func WithClusterManager(manager *ClusterManager) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = context.WithValue(ctx, managerKey{}, manager)
+			if cluster, _ := request.Params.Arguments["cluster"].(string); cluster != "" {
+				ctx = context.WithValue(ctx, clusterKey{}, cluster)
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// WithClusterArgument 为Kubernetes工具追加可选的cluster参数
+func WithClusterArgument() mcp.ToolOption {
+	return mcp.WithString("cluster",
+		mcp.Description("要操作的集群名称（kubeconfig中的context名称），可通过list_clusters查看，不提供则使用默认集群"),
+	)
+}
+
+// ManagerFromContext 获取上下文中注入的ClusterManager，未注入时返回基于环境变量的默认管理器
+func ManagerFromContext(ctx context.Context) *ClusterManager {
+	if manager, ok := ctx.Value(managerKey{}).(*ClusterManager); ok && manager != nil {
+		return manager
+	}
+	defaultManagerOnce.Do(func() {
+		defaultManager = NewClusterManager(ClientOptionsFromEnv())
+	})
+	return defaultManager
+}
+
+// ClusterFromContext 获取本次调用指定的集群名称，为空表示默认集群
+func ClusterFromContext(ctx context.Context) string {
+	cluster, _ := ctx.Value(clusterKey{}).(string)
+	return cluster
+}
+
+// WithImpersonation 指定本次调用以哪个Kubernetes用户和组的身份访问API Server
+func WithImpersonation(ctx context.Context, impersonate rest.ImpersonationConfig) context.Context {
+	return context.WithValue(ctx, impersonationCtx{}, impersonate)
+}
+
+// ImpersonationFromContext 获取本次调用模拟的身份，未设置时返回false
+func ImpersonationFromContext(ctx context.Context) (rest.ImpersonationConfig, bool) {
+	impersonate, ok := ctx.Value(impersonationCtx{}).(rest.ImpersonationConfig)
+	return impersonate, ok && impersonate.UserName != ""
+}
+
+// WithNamespaceRestriction 标记本次调用的授权策略只允许部分命名空间，此时不能访问集群级资源
+func WithNamespaceRestriction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, restrictedCtx{}, true)
+}
+
+// NamespaceRestricted 本次调用是否只允许访问部分命名空间
+func NamespaceRestricted(ctx context.Context) bool {
+	restricted, _ := ctx.Value(restrictedCtx{}).(bool)
+	return restricted
+}
+
+// GetClientset 获取当前工具调用使用的Kubernetes客户端，设置了模拟身份时使用模拟客户端
+func GetClientset(ctx context.Context) (*kubernetes.Clientset, error) {
+	provider, err := ManagerFromContext(ctx).Provider(ClusterFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if impersonate, ok := ImpersonationFromContext(ctx); ok {
+		return provider.ImpersonatedClientset(impersonate)
+	}
+	return provider.Clientset()
+}
+
+// GetDynamicClient 获取当前工具调用使用的动态客户端以及对应集群的资源映射器，设置了模拟身份时使用模拟客户端
+func GetDynamicClient(ctx context.Context) (dynamic.Interface, meta.ResettableRESTMapper, error) {
+	provider, err := ManagerFromContext(ctx).Provider(ClusterFromContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	mapper, err := provider.RESTMapper()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var client dynamic.Interface
+	if impersonate, ok := ImpersonationFromContext(ctx); ok {
+		client, err = provider.ImpersonatedDynamicClient(impersonate)
+	} else {
+		client, err = provider.DynamicClient()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, mapper, nil
 }
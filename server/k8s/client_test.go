@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestClientCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newClientCache[int](2)
+	cache.add("a", 1)
+	cache.add("b", 2)
+	// 访问a后b成为最久未使用的
+	if v, ok := cache.get("a"); !ok || v != 1 {
+		t.Fatalf("get(a) = %d, %v", v, ok)
+	}
+	cache.add("c", 3)
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("b 应该被淘汰")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if v, ok := cache.get(key); !ok || v != want {
+			t.Errorf("get(%s) = %d, %v, want %d", key, v, ok, want)
+		}
+	}
+	if cache.len() != 2 {
+		t.Errorf("len() = %d, want 2", cache.len())
+	}
+
+	cache.add("c", 4)
+	if v, _ := cache.get("c"); v != 4 || cache.len() != 2 {
+		t.Errorf("更新已有键后 get(c) = %d, len() = %d", v, cache.len())
+	}
+}
+
+func TestImpersonatedClientsAreBounded(t *testing.T) {
+	provider := newClientProvider("test", ClientOptions{}, func() (*rest.Config, []string, error) {
+		return &rest.Config{Host: "https://127.0.0.1:6443"}, nil, nil
+	})
+
+	first := rest.ImpersonationConfig{UserName: "user-0"}
+	cached, err := provider.ImpersonatedClientset(first)
+	if err != nil {
+		t.Fatalf("ImpersonatedClientset: %v", err)
+	}
+	if again, _ := provider.ImpersonatedClientset(first); again != cached {
+		t.Error("相同身份应该复用缓存的客户端")
+	}
+
+	for i := 1; i <= maxImpersonatedClients; i++ {
+		impersonate := rest.ImpersonationConfig{UserName: fmt.Sprintf("user-%d", i), Groups: []string{"dev"}}
+		if _, err := provider.ImpersonatedClientset(impersonate); err != nil {
+			t.Fatalf("ImpersonatedClientset: %v", err)
+		}
+		if _, err := provider.ImpersonatedDynamicClient(impersonate); err != nil {
+			t.Fatalf("ImpersonatedDynamicClient: %v", err)
+		}
+	}
+
+	if n := provider.impersonated.len(); n != maxImpersonatedClients {
+		t.Errorf("缓存的模拟客户端数量 = %d, want %d", n, maxImpersonatedClients)
+	}
+	if n := provider.impersonatedDynamic.len(); n != maxImpersonatedClients {
+		t.Errorf("缓存的模拟动态客户端数量 = %d, want %d", n, maxImpersonatedClients)
+	}
+	if again, _ := provider.ImpersonatedClientset(first); again == cached {
+		t.Error("最久未使用的身份应该被淘汰并重新创建客户端")
+	}
+}
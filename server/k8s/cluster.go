@@ -0,0 +1,102 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// clusterSummary 集群列表中的一行，version只在检查连通性时填充
+type clusterSummary struct {
+	Name      string `json:"name"`
+	Default   bool   `json:"default"`
+	Server    string `json:"server"`
+	Namespace string `json:"namespace"`
+	User      string `json:"user"`
+	Version   string `json:"version,omitempty"`
+}
+
+// ListClustersTool 列出所有可用集群的工具函数
+func ListClustersTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	checkConnectivity, _ := request.Params.Arguments["check_connectivity"].(bool)
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: list_clusters, check_connectivity=", checkConnectivity)
+
+	manager := ManagerFromContext(ctx)
+	clusters, err := manager.Clusters()
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取集群列表失败: %v", err)), err
+	}
+
+	items := make([]clusterSummary, 0, len(clusters))
+	for _, cluster := range clusters {
+		namespace := cluster.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		item := clusterSummary{
+			Name:      cluster.Name,
+			Default:   cluster.Default,
+			Server:    cluster.Server,
+			Namespace: namespace,
+			User:      cluster.User,
+		}
+		if checkConnectivity {
+			item.Version = clusterVersion(ctx, cluster.Name)
+		}
+		items = append(items, item)
+	}
+	if format != OutputText {
+		return structuredResult(format, listOutput{Kind: "Cluster", Items: items})
+	}
+
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("可用的Kubernetes集群 (共%d个):\n\n", len(clusters)))
+	if checkConnectivity {
+		result.WriteString("NAME\tDEFAULT\tSERVER\tNAMESPACE\tUSER\tVERSION\n")
+	} else {
+		result.WriteString("NAME\tDEFAULT\tSERVER\tNAMESPACE\tUSER\n")
+	}
+
+	for _, item := range items {
+		isDefault := ""
+		if item.Default {
+			isDefault = "*"
+		}
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s",
+			item.Name,
+			isDefault,
+			item.Server,
+			item.Namespace,
+			item.User,
+		)
+		if checkConnectivity {
+			line += "\t" + item.Version
+		}
+		result.WriteString(line + "\n")
+	}
+
+	result.WriteString("\n提示: 在其他Kubernetes工具中通过cluster参数指定集群名称，不指定时使用标记为*的默认集群\n")
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// clusterVersion 获取集群版本，用于检查连通性，启用身份模拟时以调用方身份访问
+func clusterVersion(ctx context.Context, name string) string {
+	clientset, err := GetClientset(context.WithValue(ctx, clusterKey{}, name))
+	if err != nil {
+		return fmt.Sprintf("不可用: %v", err)
+	}
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Sprintf("不可达: %v", err)
+	}
+	return version.GitVersion
+}
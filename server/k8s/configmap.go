@@ -12,42 +12,65 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// configMapSummary ConfigMap列表中的一行
+type configMapSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Data      int    `json:"data"`
+	Age       string `json:"age"`
+}
+
 // ListConfigMapsTool 列出ConfigMap的工具函数
 func ListConfigMapsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_configmaps, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_configmaps,", scope)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取ConfigMap列表
-	configmaps, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	configmaps, err := clientset.CoreV1().ConfigMaps(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取ConfigMap列表失败: %v", err)), err
 	}
 
+	items := make([]configMapSummary, 0, len(configmaps.Items))
+	for _, cm := range configmaps.Items {
+		items = append(items, configMapSummary{
+			Name:      cm.Name,
+			Namespace: cm.Namespace,
+			Data:      len(cm.Data),
+			Age:       formatAge(cm.CreationTimestamp.Time),
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("ConfigMap", items, configmaps))
+	}
+
 	// 格式化输出
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tDATA\tAGE\n")
+	result.WriteString(scope.header("NAME\tDATA\tAGE"))
 
-	for _, cm := range configmaps.Items {
-		// 计算运行时间
-		age := formatAge(cm.CreationTimestamp.Time)
-
-		result.WriteString(fmt.Sprintf("%s\t%d\t%s\n",
-			cm.Name,
-			len(cm.Data),
-			age))
+	for _, item := range items {
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%d\t%s\n",
+			item.Name,
+			item.Data,
+			item.Age))
 	}
 
+	result.WriteString(scope.footer(configmaps))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -59,12 +82,17 @@ func DescribeConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		namespace = "default"
 	}
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_configmap, configmap_name=", configMapName, ", namespace=", namespace)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取ConfigMap详情
@@ -73,6 +101,11 @@ func DescribeConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		return mcp.NewToolResultText(fmt.Sprintf("获取ConfigMap详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		events, _ := getEventsForConfigMap(ctx, clientset, cm)
+		return describeResult(format, cm, events)
+	}
+
 	// 格式化输出
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:         %s\n", cm.Name))
@@ -170,12 +203,14 @@ func CreateConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	fromFile, _ := request.Params.Arguments["from_file"].(string)
 	fromLiteral, _ := request.Params.Arguments["from_literal"].(string)
 
-	fmt.Println("ai 正在调用mcp server的tool: create_configmap, configmap_name=", configMapName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: create_configmap, configmap_name=", configMapName, ", namespace=", namespace, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 创建ConfigMap对象
@@ -219,10 +254,13 @@ func CreateConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	}
 
 	// 创建ConfigMap
-	createdConfigMap, err := clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+	createdConfigMap, err := clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("创建ConfigMap失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("创建ConfigMap %s/%s", namespace, configMapName), nil, createdConfigMap)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("ConfigMap %s 在命名空间 %s 中创建成功", createdConfigMap.Name, createdConfigMap.Namespace)), nil
 }
@@ -272,12 +310,14 @@ func UpdateConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	key, keyProvided := request.Params.Arguments["key"].(string)
 	value, valueProvided := request.Params.Arguments["value"].(string)
 
-	fmt.Println("ai 正在调用mcp server的tool: update_configmap, configmap_name=", configMapName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	fmt.Println("ai 正在调用mcp server的tool: update_configmap, configmap_name=", configMapName, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取现有ConfigMap
@@ -285,6 +325,7 @@ func UpdateConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取ConfigMap %s 失败: %v", configMapName, err)), err
 	}
+	live := configMap.DeepCopy()
 
 	// 更新标签
 	if labelsProvided {
@@ -310,10 +351,13 @@ func UpdateConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	}
 
 	// 更新ConfigMap
-	updatedConfigMap, err := clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	updatedConfigMap, err := clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("更新ConfigMap失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("更新ConfigMap %s/%s", namespace, configMapName), live, updatedConfigMap)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("ConfigMap %s 在命名空间 %s 中更新成功", updatedConfigMap.Name, updatedConfigMap.Namespace)), nil
 }
@@ -326,19 +370,33 @@ func DeleteConfigMapTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		namespace = "default"
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: delete_configmap, configmap_name=", configMapName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	fmt.Println("ai 正在调用mcp server的tool: delete_configmap, configmap_name=", configMapName, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	// 演练模式下先获取现有对象用于对比
+	var live *corev1.ConfigMap
+	if dryRun {
+		live, err = clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取ConfigMap %s 失败: %v", configMapName, err)), err
+		}
 	}
 
 	// 删除ConfigMap
-	err = clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, configMapName, metav1.DeleteOptions{})
+	err = clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, configMapName, metav1.DeleteOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("删除ConfigMap失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("删除ConfigMap %s/%s", namespace, configMapName), live, nil)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("ConfigMap %s 在命名空间 %s 中已删除", configMapName, namespace)), nil
 }
@@ -0,0 +1,406 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// manualJobAnnotation 手动触发的Job上的注解，与kubectl create job --from=cronjob一致
+const manualJobAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// maxJobNameLength Job名称的最大长度，Job名称会作为Pod的标签值
+const maxJobNameLength = 63
+
+// cronJobSummary CronJob列表中的一行
+type cronJobSummary struct {
+	Name           string   `json:"name"`
+	Namespace      string   `json:"namespace"`
+	Schedule       string   `json:"schedule"`
+	TimeZone       string   `json:"timeZone,omitempty"`
+	Suspend        bool     `json:"suspend"`
+	Active         int      `json:"active"`
+	LastSchedule   string   `json:"lastSchedule"`
+	LastSuccessful string   `json:"lastSuccessful"`
+	Age            string   `json:"age"`
+	Containers     []string `json:"containers"`
+	Images         []string `json:"images"`
+}
+
+// 列出CronJob的工具函数
+func ListCronJobsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: list_cronjobs,", scope)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs(scope.namespace).List(ctx, scope.options)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取CronJob列表失败: %v", err)), err
+	}
+
+	items := make([]cronJobSummary, 0, len(cronJobs.Items))
+	for _, cronJob := range cronJobs.Items {
+		containers, images := containerNamesAndImages(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers)
+		summary := cronJobSummary{
+			Name:           cronJob.Name,
+			Namespace:      cronJob.Namespace,
+			Schedule:       cronJob.Spec.Schedule,
+			Suspend:        cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+			Active:         len(cronJob.Status.Active),
+			LastSchedule:   "<none>",
+			LastSuccessful: "<none>",
+			Age:            formatAge(cronJob.CreationTimestamp.Time),
+			Containers:     containers,
+			Images:         images,
+		}
+		if cronJob.Spec.TimeZone != nil {
+			summary.TimeZone = *cronJob.Spec.TimeZone
+		}
+		if cronJob.Status.LastScheduleTime != nil {
+			summary.LastSchedule = formatAge(cronJob.Status.LastScheduleTime.Time)
+		}
+		if cronJob.Status.LastSuccessfulTime != nil {
+			summary.LastSuccessful = formatAge(cronJob.Status.LastSuccessfulTime.Time)
+		}
+		items = append(items, summary)
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("CronJob", items, cronJobs))
+	}
+
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tSCHEDULE\tSUSPEND\tACTIVE\tLAST SCHEDULE\tLAST SUCCESSFUL\tAGE\tIMAGES"))
+	for _, item := range items {
+		schedule := item.Schedule
+		if item.TimeZone != "" {
+			schedule += " (" + item.TimeZone + ")"
+		}
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%t\t%d\t%s\t%s\t%s\t%s\n",
+			item.Name,
+			schedule,
+			item.Suspend,
+			item.Active,
+			item.LastSchedule,
+			item.LastSuccessful,
+			item.Age,
+			strings.Join(item.Images, ",")))
+	}
+	result.WriteString(scope.footer(cronJobs))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 获取CronJob详情的工具函数
+func DescribeCronJobTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cronJobName := request.Params.Arguments["cronjob_name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: describe_cronjob, cronjob_name=", cronJobName, ", namespace=", namespace)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJobName, metav1.GetOptions{})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取CronJob详情失败: %v", err)), err
+	}
+
+	events, _ := getEventsForCronJob(ctx, clientset, cronJob)
+	if format != OutputText {
+		return describeResult(format, cronJob, events)
+	}
+
+	// 格式化输出
+	spec := cronJob.Spec
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Name:                          %s\n", cronJob.Name))
+	result.WriteString(fmt.Sprintf("Namespace:                     %s\n", cronJob.Namespace))
+	result.WriteString(fmt.Sprintf("CreationTimestamp:             %s\n", cronJob.CreationTimestamp.Format(time.RFC3339)))
+	result.WriteString(fmt.Sprintf("Labels:                        %s\n", formatLabels(cronJob.Labels)))
+	result.WriteString(fmt.Sprintf("Annotations:                   %s\n", formatLabels(cronJob.Annotations)))
+	result.WriteString(fmt.Sprintf("Schedule:                      %s\n", spec.Schedule))
+	if spec.TimeZone != nil {
+		result.WriteString(fmt.Sprintf("Time Zone:                     %s\n", *spec.TimeZone))
+	}
+	result.WriteString(fmt.Sprintf("Concurrency Policy:            %s\n", spec.ConcurrencyPolicy))
+	result.WriteString(fmt.Sprintf("Suspend:                       %t\n", spec.Suspend != nil && *spec.Suspend))
+	result.WriteString(fmt.Sprintf("Successful Job History Limit:  %s\n", formatInt32Ptr(spec.SuccessfulJobsHistoryLimit)))
+	result.WriteString(fmt.Sprintf("Failed Job History Limit:      %s\n", formatInt32Ptr(spec.FailedJobsHistoryLimit)))
+	if spec.StartingDeadlineSeconds != nil {
+		result.WriteString(fmt.Sprintf("Starting Deadline Seconds:     %ds\n", *spec.StartingDeadlineSeconds))
+	}
+	result.WriteString(fmt.Sprintf("Backoff Limit:                 %s\n", formatInt32Ptr(spec.JobTemplate.Spec.BackoffLimit)))
+	result.WriteString(fmt.Sprintf("Last Schedule Time:            %s\n", formatTimePtr(cronJob.Status.LastScheduleTime)))
+	result.WriteString(fmt.Sprintf("Last Successful Time:          %s\n", formatTimePtr(cronJob.Status.LastSuccessfulTime)))
+
+	active := make([]string, 0, len(cronJob.Status.Active))
+	for _, ref := range cronJob.Status.Active {
+		active = append(active, ref.Name)
+	}
+	result.WriteString(fmt.Sprintf("Active Jobs:                   %s\n", joinOrNone(active, ", ")))
+
+	// 容器信息
+	result.WriteString("Containers:\n")
+	for _, c := range spec.JobTemplate.Spec.Template.Spec.Containers {
+		result.WriteString(fmt.Sprintf("  Name:     %s\n", c.Name))
+		result.WriteString(fmt.Sprintf("  Image:    %s\n", c.Image))
+		if len(c.Command) > 0 {
+			result.WriteString(fmt.Sprintf("  Command:  %s\n", strings.Join(c.Command, " ")))
+		}
+		if len(c.Args) > 0 {
+			result.WriteString(fmt.Sprintf("  Args:     %s\n", strings.Join(c.Args, " ")))
+		}
+	}
+
+	// 最近的Job
+	if jobs, err := jobsForCronJob(ctx, clientset, cronJob); err == nil && len(jobs) > 0 {
+		result.WriteString("\nRecent Jobs:\n")
+		result.WriteString("NAME\tSTATUS\tCOMPLETIONS\tDURATION\tAGE\n")
+		for _, job := range jobs {
+			result.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n",
+				job.Name, jobStatus(&job), jobCompletions(&job), jobDuration(&job), formatAge(job.CreationTimestamp.Time)))
+		}
+	}
+
+	// 事件
+	if events != nil && len(events.Items) > 0 {
+		result.WriteString("\nEvents:\n")
+		result.WriteString("LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+		for _, event := range events.Items {
+			result.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n",
+				formatAge(event.LastTimestamp.Time),
+				event.Type,
+				event.Reason,
+				event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name,
+				event.Message))
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 删除CronJob的工具函数，CronJob创建的Job和Pod在后台一并删除
+func DeleteCronJobTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cronJobName := request.Params.Arguments["cronjob_name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: delete_cronjob, cronjob_name=", cronJobName, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	// 演练模式下先获取现有对象用于对比
+	var live *batchv1.CronJob
+	if dryRun {
+		live, err = clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJobName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取CronJob失败: %v", err)), err
+		}
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	err = clientset.BatchV1().CronJobs(namespace).Delete(ctx, cronJobName, metav1.DeleteOptions{
+		DryRun:            dryRunOption(dryRun),
+		PropagationPolicy: &propagation,
+	})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("删除CronJob失败: %v", err)), err
+	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("删除CronJob %s/%s", namespace, cronJobName), live, nil)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("CronJob %s 在命名空间 %s 中已删除，其创建的Job将在后台删除", cronJobName, namespace)), nil
+}
+
+// 按CronJob的模板立即创建一个Job的工具函数，等同于 kubectl create job --from=cronjob/<name>
+func TriggerCronJobTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cronJobName := request.Params.Arguments["cronjob_name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	jobName, _ := request.Params.Arguments["job_name"].(string)
+	if jobName == "" {
+		jobName = manualJobName(cronJobName, time.Now())
+	}
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: trigger_cronjob, cronjob_name=", cronJobName, ", namespace=", namespace, ", job_name=", jobName, ", dry_run=", dryRun)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJobName, metav1.GetOptions{})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取CronJob失败: %v", err)), err
+	}
+
+	created, err := clientset.BatchV1().Jobs(namespace).Create(ctx, jobFromCronJob(cronJob, jobName), metav1.CreateOptions{DryRun: dryRunOption(dryRun)})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("创建Job失败: %v", err)), err
+	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("从CronJob %s/%s 创建Job %s", namespace, cronJobName, jobName), nil, created)
+	}
+
+	message := fmt.Sprintf("已从CronJob %s 创建Job %s（命名空间 %s），可通过describe_job或job_diagnostic查看执行结果", cronJobName, created.Name, namespace)
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		message += "；注意该CronJob处于暂停状态，手动触发不受影响，但不会按计划继续创建Job"
+	}
+	return mcp.NewToolResultText(message), nil
+}
+
+// 暂停CronJob的工具函数
+func SuspendCronJobTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return setCronJobSuspend(ctx, request, true)
+}
+
+// 恢复CronJob的工具函数
+func ResumeCronJobTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return setCronJobSuspend(ctx, request, false)
+}
+
+// setCronJobSuspend 设置CronJob的spec.suspend，暂停后不再按计划创建Job，已在运行的Job不受影响
+func setCronJobSuspend(ctx context.Context, request mcp.CallToolRequest, suspend bool) (*mcp.CallToolResult, error) {
+	cronJobName := request.Params.Arguments["cronjob_name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	dryRun := isDryRun(request)
+
+	action, toolName := "恢复", "resume_cronjob"
+	if suspend {
+		action, toolName = "暂停", "suspend_cronjob"
+	}
+	fmt.Println("ai 正在调用mcp server的tool:", toolName+", cronjob_name=", cronJobName, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJobName, metav1.GetOptions{})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取CronJob失败: %v", err)), err
+	}
+	if (cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend) == suspend {
+		return mcp.NewToolResultText(fmt.Sprintf("CronJob %s 在命名空间 %s 中已经处于%s状态，无需修改", cronJobName, namespace, action)), nil
+	}
+
+	live := cronJob.DeepCopy()
+	cronJob.Spec.Suspend = &suspend
+	updated, err := clientset.BatchV1().CronJobs(namespace).Update(ctx, cronJob, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%sCronJob失败: %v", action, err)), err
+	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("%sCronJob %s/%s", action, namespace, cronJobName), live, updated)
+	}
+
+	if suspend {
+		return mcp.NewToolResultText(fmt.Sprintf("CronJob %s 在命名空间 %s 中已暂停，不会再按计划创建Job，正在运行的%d个Job不受影响",
+			cronJobName, namespace, len(cronJob.Status.Active))), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("CronJob %s 在命名空间 %s 中已恢复，将按计划 %s 继续创建Job",
+		cronJobName, namespace, cronJob.Spec.Schedule)), nil
+}
+
+// jobFromCronJob 按CronJob的Job模板构造一个由该CronJob控制的Job
+func jobFromCronJob(cronJob *batchv1.CronJob, name string) *batchv1.Job {
+	annotations := map[string]string{manualJobAnnotation: "manual"}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       cronJob.Namespace,
+			Labels:          cronJob.Spec.JobTemplate.Labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+}
+
+// manualJobName 生成手动触发的Job名称，例如 backup-manual-1718000000，超长时截断CronJob名称
+func manualJobName(cronJobName string, now time.Time) string {
+	suffix := fmt.Sprintf("-manual-%d", now.Unix())
+	if len(cronJobName)+len(suffix) > maxJobNameLength {
+		cronJobName = strings.TrimRight(cronJobName[:maxJobNameLength-len(suffix)], "-.")
+	}
+	return cronJobName + suffix
+}
+
+// jobsForCronJob 返回由CronJob创建的Job，按创建时间从新到旧排序
+func jobsForCronJob(ctx context.Context, clientset *kubernetes.Clientset, cronJob *batchv1.CronJob) ([]batchv1.Job, error) {
+	jobs, err := clientset.BatchV1().Jobs(cronJob.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var owned []batchv1.Job
+	for _, job := range jobs.Items {
+		if owner := metav1.GetControllerOf(&job); owner != nil && owner.UID == cronJob.UID {
+			owned = append(owned, job)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[j].CreationTimestamp.Before(&owned[i].CreationTimestamp)
+	})
+	return owned, nil
+}
+
+// 辅助函数：获取CronJob相关事件
+func getEventsForCronJob(ctx context.Context, clientset *kubernetes.Clientset, cronJob *batchv1.CronJob) (*corev1.EventList, error) {
+	fieldSelector := fmt.Sprintf("involvedObject.kind=CronJob,involvedObject.name=%s,involvedObject.namespace=%s",
+		cronJob.Name, cronJob.Namespace)
+	return clientset.CoreV1().Events(cronJob.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fieldSelector,
+	})
+}
@@ -9,47 +9,75 @@ import (
 	"time"
 )
 
+// daemonSetSummary DaemonSet列表中的一行
+type daemonSetSummary struct {
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace"`
+	Desired    int32    `json:"desired"`
+	Current    int32    `json:"current"`
+	Ready      int32    `json:"ready"`
+	Age        string   `json:"age"`
+	Containers []string `json:"containers"`
+	Images     []string `json:"images"`
+}
+
 // ListDaemonSetsTool 列出DaemonSet的工具函数
 func ListDaemonSetsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_daemonsets, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_daemonsets,", scope)
 
-	clientset, err := CreateK8sClient()
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
-	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	daemonSets, err := clientset.AppsV1().DaemonSets(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取DaemonSet列表失败: %v", err)), err
 	}
 
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tDESIRED\tCURRENT\tREADY\tAGE\tCONTAINERS\tIMAGES\n")
-
+	items := make([]daemonSetSummary, 0, len(daemonSets.Items))
 	for _, ds := range daemonSets.Items {
-		age := formatAge(ds.CreationTimestamp.Time)
-		var containers, images []string
-		for _, c := range ds.Spec.Template.Spec.Containers {
-			containers = append(containers, c.Name)
-			images = append(images, c.Image)
-		}
-
-		result.WriteString(fmt.Sprintf("%s\t%d\t%d\t%d\t%s\t%s\t%s\n",
-			ds.Name,
-			ds.Status.DesiredNumberScheduled,
-			ds.Status.CurrentNumberScheduled,
-			ds.Status.NumberReady,
-			age,
-			strings.Join(containers, ","),
-			strings.Join(images, ",")))
+		containers, images := containerNamesAndImages(ds.Spec.Template.Spec.Containers)
+		items = append(items, daemonSetSummary{
+			Name:       ds.Name,
+			Namespace:  ds.Namespace,
+			Desired:    ds.Status.DesiredNumberScheduled,
+			Current:    ds.Status.CurrentNumberScheduled,
+			Ready:      ds.Status.NumberReady,
+			Age:        formatAge(ds.CreationTimestamp.Time),
+			Containers: containers,
+			Images:     images,
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("DaemonSet", items, daemonSets))
+	}
+
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tDESIRED\tCURRENT\tREADY\tAGE\tCONTAINERS\tIMAGES"))
+
+	for _, item := range items {
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			item.Name,
+			item.Desired,
+			item.Current,
+			item.Ready,
+			item.Age,
+			strings.Join(item.Containers, ","),
+			strings.Join(item.Images, ",")))
 	}
 
+	result.WriteString(scope.footer(daemonSets))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -61,11 +89,16 @@ func DescribeDaemonSetTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		namespace = "default"
 	}
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_daemonset, daemonset_name=", dsName, ", namespace=", namespace)
 
-	clientset, err := CreateK8sClient()
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	ds, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, dsName, metav1.GetOptions{})
@@ -73,6 +106,10 @@ func DescribeDaemonSetTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		return mcp.NewToolResultText(fmt.Sprintf("获取DaemonSet失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		return describeResult(format, ds, nil)
+	}
+
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:               %s\n", ds.Name))
 	result.WriteString(fmt.Sprintf("Namespace:          %s\n", ds.Namespace))
@@ -102,11 +139,13 @@ func RestartDaemonSetTool(ctx context.Context, request mcp.CallToolRequest) (*mc
 		namespace = "default"
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: restart_daemonset, daemonset_name=", dsName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
 
-	clientset, err := CreateK8sClient()
+	fmt.Println("ai 正在调用mcp server的tool: restart_daemonset, daemonset_name=", dsName, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	ds, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, dsName, metav1.GetOptions{})
@@ -114,15 +153,28 @@ func RestartDaemonSetTool(ctx context.Context, request mcp.CallToolRequest) (*mc
 		return mcp.NewToolResultText(fmt.Sprintf("获取DaemonSet失败: %v", err)), err
 	}
 
+	live := ds.DeepCopy()
 	if ds.Spec.Template.Annotations == nil {
 		ds.Spec.Template.Annotations = make(map[string]string)
 	}
 	ds.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = time.Now().Format(time.RFC3339)
 
-	_, err = clientset.AppsV1().DaemonSets(namespace).Update(ctx, ds, metav1.UpdateOptions{})
+	updated, err := clientset.AppsV1().DaemonSets(namespace).Update(ctx, ds, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("重启DaemonSet失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("重启DaemonSet %s/%s", namespace, dsName), live, updated)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("DaemonSet %s 已成功触发重启", dsName)), nil
 }
+
+// DeleteDaemonSetTool 按级联策略删除DaemonSet，先预览将受影响的Pod和ControllerRevision
+func DeleteDaemonSetTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	dsName, _ := request.Params.Arguments["daemonset_name"].(string)
+
+	fmt.Println("ai 正在调用mcp server的tool: delete_daemonset, daemonset_name=", dsName, ", namespace=", request.Params.Arguments["namespace"], ", propagation_policy=", request.Params.Arguments["propagation_policy"], ", dry_run=", isDryRun(request))
+
+	return deleteWorkload(ctx, request, KindDaemonSet, dsName)
+}
@@ -13,55 +13,79 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// deploymentSummary Deployment列表中的一行
+type deploymentSummary struct {
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace"`
+	Ready      string   `json:"ready"`
+	UpToDate   int32    `json:"upToDate"`
+	Available  int32    `json:"available"`
+	Age        string   `json:"age"`
+	Containers []string `json:"containers"`
+	Images     []string `json:"images"`
+}
+
 // 列出Deployment的工具函数
 func ListDeploymentsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_deployments, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_deployments,", scope)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Deployment列表
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	deployments, err := clientset.AppsV1().Deployments(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Deployment列表失败: %v", err)), err
 	}
 
-	// 格式化输出
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE\tCONTAINERS\tIMAGES\n")
-
+	items := make([]deploymentSummary, 0, len(deployments.Items))
 	for _, deployment := range deployments.Items {
-		// 计算运行时间
-		age := formatAge(deployment.CreationTimestamp.Time)
-
 		// 获取容器和镜像信息
-		var containers []string
-		var images []string
-		for _, container := range deployment.Spec.Template.Spec.Containers {
-			containers = append(containers, container.Name)
-			images = append(images, container.Image)
-		}
+		containers, images := containerNamesAndImages(deployment.Spec.Template.Spec.Containers)
+		items = append(items, deploymentSummary{
+			Name:       deployment.Name,
+			Namespace:  deployment.Namespace,
+			Ready:      fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, deployment.Status.Replicas),
+			UpToDate:   deployment.Status.UpdatedReplicas,
+			Available:  deployment.Status.AvailableReplicas,
+			Age:        formatAge(deployment.CreationTimestamp.Time),
+			Containers: containers,
+			Images:     images,
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("Deployment", items, deployments))
+	}
 
-		result.WriteString(fmt.Sprintf("%s\t%d/%d\t%d\t%d\t%s\t%s\t%s\n",
-			deployment.Name,
-			deployment.Status.ReadyReplicas,
-			deployment.Status.Replicas,
-			deployment.Status.UpdatedReplicas,
-			deployment.Status.AvailableReplicas,
-			age,
-			strings.Join(containers, ","),
-			strings.Join(images, ",")))
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE\tCONTAINERS\tIMAGES"))
+
+	for _, item := range items {
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+			item.Name,
+			item.Ready,
+			item.UpToDate,
+			item.Available,
+			item.Age,
+			strings.Join(item.Containers, ","),
+			strings.Join(item.Images, ",")))
 	}
 
+	result.WriteString(scope.footer(deployments))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -73,12 +97,17 @@ func DescribeDeploymentTool(ctx context.Context, request mcp.CallToolRequest) (*
 		namespace = "default"
 	}
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_deployment, deployment_name=", deploymentName, ", namespace=", namespace)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Deployment详情
@@ -87,6 +116,11 @@ func DescribeDeploymentTool(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultText(fmt.Sprintf("获取Deployment详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		events, _ := getEventsForDeployment(ctx, clientset, deployment)
+		return describeResult(format, deployment, events)
+	}
+
 	// 格式化输出
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:               %s\n", deployment.Name))
@@ -218,13 +252,14 @@ func ScaleDeploymentTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	}
 
 	replicasInt := int32(replicas)
+	dryRun := isDryRun(request)
 
-	fmt.Println("ai 正在调用mcp server的tool: scale_deployment, deployment_name=", deploymentName, ", namespace=", namespace, ", replicas=", replicasInt)
+	fmt.Println("ai 正在调用mcp server的tool: scale_deployment, deployment_name=", deploymentName, ", namespace=", namespace, ", replicas=", replicasInt, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取当前Deployment
@@ -234,18 +269,22 @@ func ScaleDeploymentTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	}
 
 	// 记录原副本数
+	live := deployment.DeepCopy()
 	oldReplicas := *deployment.Spec.Replicas
 
 	// 更新副本数
 	deployment.Spec.Replicas = &replicasInt
 
 	// 应用更新
-	_, err = clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+	updated, err := clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("扩缩Deployment失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("将Deployment %s/%s 的副本数从 %d 扩缩到 %d", namespace, deploymentName, oldReplicas, replicasInt), live, updated)
+	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("已将Deployment %s 在命名空间 %s 中的副本数从 %d 扩缩到 %d",
+	return mcp.NewToolResultText(fmt.Sprintf("已将Deployment %s 在命名空间 %s 中的副本数从 %d 扩缩到 %d，可以调用rollout_status等待完成",
 		deploymentName, namespace, oldReplicas, replicasInt)), nil
 }
 
@@ -257,12 +296,14 @@ func RestartDeploymentTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		namespace = "default"
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: restart_deployment, deployment_name=", deploymentName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: restart_deployment, deployment_name=", deploymentName, ", namespace=", namespace, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取当前Deployment
@@ -272,18 +313,31 @@ func RestartDeploymentTool(ctx context.Context, request mcp.CallToolRequest) (*m
 	}
 
 	// 添加或更新重启注解
+	live := deployment.DeepCopy()
 	if deployment.Spec.Template.Annotations == nil {
 		deployment.Spec.Template.Annotations = make(map[string]string)
 	}
 	deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = time.Now().Format(time.RFC3339)
 
 	// 应用更新
-	_, err = clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+	updated, err := clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("重启Deployment失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("重启Deployment %s/%s", namespace, deploymentName), live, updated)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Deployment %s 在命名空间 %s 中已开始重启，可以调用rollout_status等待完成", deploymentName, namespace)), nil
+}
+
+// 删除Deployment的工具函数，按级联策略处理其ReplicaSet和Pod
+func DeleteDeploymentTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	deploymentName, _ := request.Params.Arguments["deployment_name"].(string)
+
+	fmt.Println("ai 正在调用mcp server的tool: delete_deployment, deployment_name=", deploymentName, ", namespace=", request.Params.Arguments["namespace"], ", propagation_policy=", request.Params.Arguments["propagation_policy"], ", dry_run=", isDryRun(request))
 
-	return mcp.NewToolResultText(fmt.Sprintf("Deployment %s 在命名空间 %s 中已开始重启", deploymentName, namespace)), nil
+	return deleteWorkload(ctx, request, KindDeployment, deploymentName)
 }
 
 // 辅助函数：获取Deployment相关事件
@@ -0,0 +1,327 @@
+package k8s
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// diffContextLines 差异输出中变更前后保留的上下文行数
+const diffContextLines = 3
+
+// diffMaxCells 逐行比较时最长公共子序列表的最大单元数，避免超大对象占用过多内存
+const diffMaxCells = 4 << 20
+
+// WithDryRunArgument 为写工具追加dry_run参数
+func WithDryRunArgument() mcp.ToolOption {
+	return mcp.WithBoolean("dry_run",
+		mcp.Description("是否只做演练：以服务端DryRun=All方式提交，返回变更后的对象以及与现有对象的差异，不会真正修改集群"),
+		mcp.DefaultBool(false),
+	)
+}
+
+// isDryRun 读取dry_run参数
+func isDryRun(request mcp.CallToolRequest) bool {
+	dryRun, _ := request.Params.Arguments["dry_run"].(bool)
+	return dryRun
+}
+
+// dryRunOption 返回创建、更新、删除请求的DryRun选项
+func dryRunOption(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// dryRunResult 生成演练结果，包含变更后的对象和与现有对象的差异
+// live为nil表示创建，result为nil表示删除
+func dryRunResult(action string, live, result runtime.Object) (*mcp.CallToolResult, error) {
+	text, err := dryRunText(action, live, result)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// dryRunText 生成演练结果的文本，供需要在前后追加其他内容的工具使用
+func dryRunText(action string, live, result runtime.Object) (string, error) {
+	// 每次演练使用随机密钥对Secret的值做摘要，既能在差异中体现是否变化，又不会泄露原值
+	redactKey := make([]byte, 32)
+	if _, err := rand.Read(redactKey); err != nil {
+		return "", fmt.Errorf("生成演练结果失败: %v", err)
+	}
+
+	liveYAML, err := objectYAML(live, redactKey)
+	if err != nil {
+		return "", fmt.Errorf("格式化现有对象失败: %v", err)
+	}
+	resultYAML, err := objectYAML(result, redactKey)
+	if err != nil {
+		return "", fmt.Errorf("格式化演练结果失败: %v", err)
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("【演练模式】%s 已通过服务端校验（DryRun=All），未对集群做任何修改\n\n", action))
+
+	if result != nil {
+		out.WriteString("变更后的对象:\n")
+		out.WriteString(resultYAML)
+		out.WriteString("\n")
+	} else {
+		out.WriteString("将被删除的对象:\n")
+		out.WriteString(liveYAML)
+		out.WriteString("\n")
+	}
+
+	diff := unifiedDiff("live", "dry-run", liveYAML, resultYAML)
+	if diff == "" {
+		out.WriteString("与现有对象的差异: 无变化\n")
+	} else {
+		out.WriteString("与现有对象的差异:\n")
+		out.WriteString(diff)
+	}
+	return out.String(), nil
+}
+
+// objectYAML 把对象转换为便于比较的YAML，去掉managedFields、resourceVersion和status，并隐藏Secret的值及其上次apply的配置
+func objectYAML(obj runtime.Object, redactKey []byte) (string, error) {
+	if obj == nil || (reflect.ValueOf(obj).Kind() == reflect.Ptr && reflect.ValueOf(obj).IsNil()) {
+		return "", nil
+	}
+
+	content, err := objectContent(obj)
+	if err != nil {
+		return "", err
+	}
+	cleanObject(content)
+	if content["kind"] == "Secret" {
+		redactSecretData(content, redactKey)
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// objectContent 把类型化对象转换为带apiVersion/kind的map，不修改原对象
+func objectContent(obj runtime.Object) (map[string]interface{}, error) {
+	obj = obj.DeepCopyObject()
+	// 类型化客户端返回的对象不带apiVersion/kind，从scheme中补全
+	if gvks, _, err := scheme.Scheme.ObjectKinds(obj); err == nil && len(gvks) > 0 {
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// cleanObject 去掉比较时无意义的字段
+func cleanObject(content map[string]interface{}) {
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+		delete(metadata, "resourceVersion")
+	}
+}
+
+// lastAppliedAnnotation kubectl apply记录的上次配置，Secret中包含明文的data
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// redactSecretData 把Secret的data、stringData以及上次apply的配置注解替换为带摘要的占位符
+func redactSecretData(content map[string]interface{}, key []byte) {
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := content[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k, v := range values {
+			values[k] = redactedDigest(v, key)
+		}
+	}
+
+	metadata, _ := content["metadata"].(map[string]interface{})
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		if v, ok := annotations[lastAppliedAnnotation]; ok {
+			annotations[lastAppliedAnnotation] = redactedDigest(v, key)
+		}
+	}
+}
+
+// redactedDigest 生成隐藏原值后的摘要占位符，相同的值得到相同的占位符
+func redactedDigest(v interface{}, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(fmt.Sprint(v)))
+	return fmt.Sprintf("<已隐藏 摘要:%s>", hex.EncodeToString(mac.Sum(nil))[:12])
+}
+
+// diffOp 差异中的一行，kind为 ' '、'-' 或 '+'
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff 生成两段文本的统一格式差异，没有差异时返回空字符串
+func unifiedDiff(fromName, toName, from, to string) string {
+	fromLines, toLines := splitLines(from), splitLines(to)
+	ops, ok := diffLines(fromLines, toLines)
+	if !ok {
+		return fmt.Sprintf("--- %s\n+++ %s\n对象差异过大（%d行 -> %d行），不再逐行比较，仅提示两者不同\n",
+			fromName, toName, len(fromLines), len(toLines))
+	}
+
+	// 记录每个操作之前两侧已经经过的行数，用于计算hunk的行号
+	fromPos := make([]int, len(ops)+1)
+	toPos := make([]int, len(ops)+1)
+	changed := false
+	for i, op := range ops {
+		fromPos[i+1], toPos[i+1] = fromPos[i], toPos[i]
+		if op.kind != '+' {
+			fromPos[i+1]++
+		}
+		if op.kind != '-' {
+			toPos[i+1]++
+		}
+		if op.kind != ' ' {
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// 向前保留上下文，向后合并间隔不超过两倍上下文的变更
+		start := max(0, i-diffContextLines)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next < len(ops) && next-end <= 2*diffContextLines {
+				end = next
+				continue
+			}
+			end = min(next, end+diffContextLines)
+			break
+		}
+
+		fromCount := fromPos[end] - fromPos[start]
+		toCount := toPos[end] - toPos[start]
+		out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(fromPos[start], fromCount), hunkRange(toPos[start], toCount)))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.text)
+			out.WriteByte('\n')
+		}
+		i = end
+	}
+
+	return out.String()
+}
+
+// hunkRange 格式化hunk头中的行号范围
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", pos+1)
+	}
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+// splitLines 按行拆分文本，忽略末尾换行
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines 基于最长公共子序列计算逐行差异
+// 先去掉两侧相同的开头和结尾，剩余部分的比较表超过diffMaxCells时返回false，由调用方只提示两者不同
+func diffLines(a, b []string) ([]diffOp, bool) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(midA), len(midB)
+	if (n+1)*(m+1) > diffMaxCells {
+		return nil, false
+	}
+
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+m)
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', midA[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', midB[j]})
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops, true
+}
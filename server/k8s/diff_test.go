@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines 生成 prefix1..prefixN 的多行文本
+func numberedLines(prefix string, n int) string {
+	var out strings.Builder
+	for i := 1; i <= n; i++ {
+		out.WriteString(fmt.Sprintf("%s%d\n", prefix, i))
+	}
+	return out.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{
+			name: "内容相同",
+			from: "a\nb\nc\n",
+			to:   "a\nb\nc\n",
+			want: "",
+		},
+		{
+			name: "两侧都为空",
+			want: "",
+		},
+		{
+			name: "只有新增",
+			from: "",
+			to:   "a\nb\n",
+			want: "--- live\n+++ dry-run\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "只有删除",
+			from: "a\nb\n",
+			to:   "",
+			want: "--- live\n+++ dry-run\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "修改一行",
+			from: "a\nb\nc\n",
+			to:   "a\nB\nc\n",
+			want: "--- live\n+++ dry-run\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "上下文只保留三行",
+			from: numberedLines("l", 10),
+			to:   strings.Replace(numberedLines("l", 10), "l5\n", "L5\n", 1),
+			want: "--- live\n+++ dry-run\n@@ -2,7 +2,7 @@\n l2\n l3\n l4\n-l5\n+L5\n l6\n l7\n l8\n",
+		},
+		{
+			name: "相距较远的变更拆分为两个hunk",
+			from: numberedLines("l", 20),
+			to:   strings.Replace(strings.Replace(numberedLines("l", 20), "l2\n", "L2\n", 1), "l18\n", "L18\n", 1),
+			want: "--- live\n+++ dry-run\n" +
+				"@@ -1,5 +1,5 @@\n l1\n-l2\n+L2\n l3\n l4\n l5\n" +
+				"@@ -15,6 +15,6 @@\n l15\n l16\n l17\n-l18\n+L18\n l19\n l20\n",
+		},
+		{
+			name: "末尾追加",
+			from: "a\nb\nc\nd\ne\n",
+			to:   "a\nb\nc\nd\ne\nf\n",
+			want: "--- live\n+++ dry-run\n@@ -3,3 +3,4 @@\n c\n d\n e\n+f\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("live", "dry-run", tt.from, tt.to); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffCap(t *testing.T) {
+	// 完全不同的两段文本无法裁掉公共部分，比较表超过diffMaxCells时只提示两者不同
+	from := numberedLines("a", 3000)
+	to := numberedLines("b", 3000)
+	got := unifiedDiff("live", "dry-run", from, to)
+	want := "--- live\n+++ dry-run\n对象差异过大（3000行 -> 3000行），不再逐行比较，仅提示两者不同\n"
+	if got != want {
+		t.Errorf("unifiedDiff() = %q, want %q", got, want)
+	}
+
+	// 大对象中只有少量变更时，裁掉公共的开头和结尾后仍然逐行比较
+	big := numberedLines("l", 50000)
+	changed := strings.Replace(big, "l25000\n", "L25000\n", 1)
+	got = unifiedDiff("live", "dry-run", big, changed)
+	if !strings.Contains(got, "@@ -24997,7 +24997,7 @@\n") || !strings.Contains(got, "-l25000\n+L25000\n") {
+		t.Errorf("unifiedDiff() = %q", got)
+	}
+
+	if got := unifiedDiff("live", "dry-run", big, big); got != "" {
+		t.Errorf("相同的大对象应该没有差异, got %q", got)
+	}
+}
+
+func TestDiffLinesTrim(t *testing.T) {
+	ops, ok := diffLines([]string{"a", "b", "c", "d"}, []string{"a", "x", "d"})
+	if !ok {
+		t.Fatal("diffLines() 不应该超过上限")
+	}
+	var got strings.Builder
+	for _, op := range ops {
+		got.WriteByte(op.kind)
+		got.WriteString(op.text)
+		got.WriteByte(' ')
+	}
+	if want := " a -b -c +x  d "; got.String() != want {
+		t.Errorf("diffLines() = %q, want %q", got.String(), want)
+	}
+}
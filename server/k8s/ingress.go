@@ -13,47 +13,54 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// ingressSummary Ingress列表中的一行
+type ingressSummary struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Hosts     []string `json:"hosts"`
+	Addresses []string `json:"addresses"`
+	Ports     []string `json:"ports"`
+	Age       string   `json:"age"`
+	Class     string   `json:"class"`
+}
+
 // ListIngressesTool 列出Ingress的工具函数
 func ListIngressesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_ingresses, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_ingresses,", scope)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Ingress列表
-	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	ingresses, err := clientset.NetworkingV1().Ingresses(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Ingress列表失败: %v", err)), err
 	}
 
-	// 格式化输出
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tHOSTS\tADDRESS\tPORTS\tAGE\tCLASS\n")
-
+	items := make([]ingressSummary, 0, len(ingresses.Items))
 	for _, ing := range ingresses.Items {
 		// 获取主机列表
-		var hosts []string
+		hosts := []string{}
 		for _, rule := range ing.Spec.Rules {
 			if rule.Host != "" {
 				hosts = append(hosts, rule.Host)
 			}
 		}
-		hostStr := "<none>"
-		if len(hosts) > 0 {
-			hostStr = strings.Join(hosts, ",")
-		}
 
 		// 获取地址列表
-		var addresses []string
+		addresses := []string{}
 		for _, lbi := range ing.Status.LoadBalancer.Ingress {
 			if lbi.IP != "" {
 				addresses = append(addresses, lbi.IP)
@@ -61,13 +68,9 @@ func ListIngressesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 				addresses = append(addresses, lbi.Hostname)
 			}
 		}
-		addressStr := "<none>"
-		if len(addresses) > 0 {
-			addressStr = strings.Join(addresses, ",")
-		}
 
 		// 获取端口列表
-		var ports []string
+		ports := []string{}
 		if len(ing.Spec.TLS) > 0 {
 			ports = append(ports, "443")
 		}
@@ -78,29 +81,47 @@ func ListIngressesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 				break
 			}
 		}
-		portStr := "<none>"
-		if len(ports) > 0 {
-			portStr = strings.Join(ports, ",")
-		}
 
 		// 获取Ingress Class
-		ingressClass := "<none>"
+		var ingressClass string
 		if ing.Spec.IngressClassName != nil {
 			ingressClass = *ing.Spec.IngressClassName
 		}
 
-		// 计算运行时间
-		age := formatAge(ing.CreationTimestamp.Time)
+		items = append(items, ingressSummary{
+			Name:      ing.Name,
+			Namespace: ing.Namespace,
+			Hosts:     hosts,
+			Addresses: addresses,
+			Ports:     ports,
+			Age:       formatAge(ing.CreationTimestamp.Time),
+			Class:     ingressClass,
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("Ingress", items, ingresses))
+	}
+
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tHOSTS\tADDRESS\tPORTS\tAGE\tCLASS"))
 
-		result.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\n",
-			ing.Name,
-			hostStr,
-			addressStr,
-			portStr,
-			age,
+	for _, item := range items {
+		ingressClass := item.Class
+		if ingressClass == "" {
+			ingressClass = "<none>"
+		}
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.Name,
+			joinOrNone(item.Hosts, ","),
+			joinOrNone(item.Addresses, ","),
+			joinOrNone(item.Ports, ","),
+			item.Age,
 			ingressClass))
 	}
 
+	result.WriteString(scope.footer(ingresses))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -112,12 +133,17 @@ func DescribeIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		namespace = "default"
 	}
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_ingress, ingress_name=", ingressName, ", namespace=", namespace)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Ingress详情
@@ -126,6 +152,11 @@ func DescribeIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultText(fmt.Sprintf("获取Ingress详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		events, _ := getEventsForIngress(ctx, clientset, ing)
+		return describeResult(format, ing, events)
+	}
+
 	// 格式化输出
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:               %s\n", ing.Name))
@@ -180,7 +211,7 @@ func DescribeIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 					}
 					if path.Backend.Resource != nil {
 						result.WriteString(fmt.Sprintf("          Resource: %s/%s\n", 
-							path.Backend.Resource.APIGroup, 
+							formatAPIGroup(path.Backend.Resource.APIGroup),
 							path.Backend.Resource.Kind))
 						result.WriteString(fmt.Sprintf("          Resource Name: %s\n", path.Backend.Resource.Name))
 					}
@@ -202,7 +233,7 @@ func DescribeIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		}
 		if ing.Spec.DefaultBackend.Resource != nil {
 			result.WriteString(fmt.Sprintf("  Resource: %s/%s\n", 
-				ing.Spec.DefaultBackend.Resource.APIGroup, 
+				formatAPIGroup(ing.Spec.DefaultBackend.Resource.APIGroup),
 				ing.Spec.DefaultBackend.Resource.Kind))
 			result.WriteString(fmt.Sprintf("  Resource Name: %s\n", ing.Spec.DefaultBackend.Resource.Name))
 		}
@@ -273,17 +304,19 @@ func CreateIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	
 	tlsEnabled, _ := request.Params.Arguments["tls_enabled"].(bool)
 	tlsSecretName, _ := request.Params.Arguments["tls_secret_name"].(string)
+	dryRun := isDryRun(request)
 
 	fmt.Println("ai 正在调用mcp server的tool: create_ingress, ingress_name=", ingressName, 
 		", namespace=", namespace, 
 		", host=", host, 
 		", service_name=", serviceName, 
-		", service_port=", servicePort)
+		", service_port=", servicePort,
+		", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 检查服务是否存在
@@ -348,10 +381,13 @@ func CreateIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	}
 
 	// 创建Ingress
-	createdIngress, err := clientset.NetworkingV1().Ingresses(namespace).Create(ctx, ingress, metav1.CreateOptions{})
+	createdIngress, err := clientset.NetworkingV1().Ingresses(namespace).Create(ctx, ingress, metav1.CreateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("创建Ingress失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("创建Ingress %s/%s", namespace, ingressName), nil, createdIngress)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Ingress %s 在命名空间 %s 中创建成功", createdIngress.Name, createdIngress.Namespace)), nil
 }
@@ -375,13 +411,14 @@ func UpdateIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	
 	tlsEnabled, tlsEnabledProvided := request.Params.Arguments["tls_enabled"].(bool)
 	tlsSecretName, tlsSecretNameProvided := request.Params.Arguments["tls_secret_name"].(string)
+	dryRun := isDryRun(request)
 
-	fmt.Println("ai 正在调用mcp server的tool: update_ingress, ingress_name=", ingressName, ", namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: update_ingress, ingress_name=", ingressName, ", namespace=", namespace, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取现有Ingress
@@ -389,6 +426,7 @@ func UpdateIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Ingress %s 失败: %v", ingressName, err)), err
 	}
+	live := ingress.DeepCopy()
 
 	// 检查是否需要更新服务
 	if serviceNameProvided && serviceName != "" {
@@ -469,10 +507,13 @@ func UpdateIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	}
 
 	// 更新Ingress
-	updatedIngress, err := clientset.NetworkingV1().Ingresses(namespace).Update(ctx, ingress, metav1.UpdateOptions{})
+	updatedIngress, err := clientset.NetworkingV1().Ingresses(namespace).Update(ctx, ingress, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("更新Ingress失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("更新Ingress %s/%s", namespace, ingressName), live, updatedIngress)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Ingress %s 在命名空间 %s 中更新成功", updatedIngress.Name, updatedIngress.Namespace)), nil
 }
@@ -485,19 +526,33 @@ func DeleteIngressTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 		namespace = "default"
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: delete_ingress, ingress_name=", ingressName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	fmt.Println("ai 正在调用mcp server的tool: delete_ingress, ingress_name=", ingressName, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	// 演练模式下先获取现有对象用于对比
+	var live *networkingv1.Ingress
+	if dryRun {
+		live, err = clientset.NetworkingV1().Ingresses(namespace).Get(ctx, ingressName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取Ingress %s 失败: %v", ingressName, err)), err
+		}
 	}
 
 	// 删除Ingress
-	err = clientset.NetworkingV1().Ingresses(namespace).Delete(ctx, ingressName, metav1.DeleteOptions{})
+	err = clientset.NetworkingV1().Ingresses(namespace).Delete(ctx, ingressName, metav1.DeleteOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("删除Ingress失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("删除Ingress %s/%s", namespace, ingressName), live, nil)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Ingress %s 在命名空间 %s 中已删除", ingressName, namespace)), nil
 }
@@ -510,3 +565,11 @@ func getEventsForIngress(ctx context.Context, clientset *kubernetes.Clientset, i
 		FieldSelector: fieldSelector,
 	})
 }
+
+// formatAPIGroup 格式化资源后端的APIGroup，核心组为空
+func formatAPIGroup(group *string) string {
+	if group == nil {
+		return ""
+	}
+	return *group
+}
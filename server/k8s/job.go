@@ -0,0 +1,326 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Job的状态，与kubectl get jobs的STATUS列一致
+const (
+	jobComplete  = "Complete"
+	jobFailed    = "Failed"
+	jobRunning   = "Running"
+	jobSuspended = "Suspended"
+)
+
+// jobSummary Job列表中的一行
+type jobSummary struct {
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace"`
+	Status      string   `json:"status"`
+	Completions string   `json:"completions"`
+	Duration    string   `json:"duration"`
+	Age         string   `json:"age"`
+	Owner       string   `json:"owner,omitempty"` // 创建该Job的CronJob
+	Containers  []string `json:"containers"`
+	Images      []string `json:"images"`
+}
+
+// summarizeJob 提取Job列表中展示的字段
+func summarizeJob(job batchv1.Job) jobSummary {
+	containers, images := containerNamesAndImages(job.Spec.Template.Spec.Containers)
+	return jobSummary{
+		Name:        job.Name,
+		Namespace:   job.Namespace,
+		Status:      jobStatus(&job),
+		Completions: jobCompletions(&job),
+		Duration:    jobDuration(&job),
+		Age:         formatAge(job.CreationTimestamp.Time),
+		Owner:       jobOwner(&job),
+		Containers:  containers,
+		Images:      images,
+	}
+}
+
+// 列出Job的工具函数
+func ListJobsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: list_jobs,", scope)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(scope.namespace).List(ctx, scope.options)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Job列表失败: %v", err)), err
+	}
+
+	items := make([]jobSummary, 0, len(jobs.Items))
+	for _, job := range jobs.Items {
+		items = append(items, summarizeJob(job))
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("Job", items, jobs))
+	}
+
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tSTATUS\tCOMPLETIONS\tDURATION\tAGE\tOWNER\tIMAGES"))
+	for _, item := range items {
+		owner := item.Owner
+		if owner == "" {
+			owner = "<none>"
+		}
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.Name,
+			item.Status,
+			item.Completions,
+			item.Duration,
+			item.Age,
+			owner,
+			strings.Join(item.Images, ",")))
+	}
+	result.WriteString(scope.footer(jobs))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 获取Job详情的工具函数
+func DescribeJobTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jobName := request.Params.Arguments["job_name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: describe_job, job_name=", jobName, ", namespace=", namespace)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	job, err := clientset.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Job详情失败: %v", err)), err
+	}
+
+	events, _ := getEventsForJob(ctx, clientset, job)
+	if format != OutputText {
+		return describeResult(format, job, events)
+	}
+
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Name:               %s\n", job.Name))
+	result.WriteString(fmt.Sprintf("Namespace:          %s\n", job.Namespace))
+	result.WriteString(fmt.Sprintf("CreationTimestamp:  %s\n", job.CreationTimestamp.Format(time.RFC3339)))
+	result.WriteString(fmt.Sprintf("Labels:             %s\n", formatLabels(job.Labels)))
+	result.WriteString(fmt.Sprintf("Annotations:        %s\n", formatLabels(job.Annotations)))
+	if owner := jobOwner(job); owner != "" {
+		result.WriteString(fmt.Sprintf("Controlled By:      CronJob/%s\n", owner))
+	}
+	result.WriteString(fmt.Sprintf("Status:             %s\n", jobStatus(job)))
+	result.WriteString(fmt.Sprintf("Completions:        %s\n", jobCompletions(job)))
+	result.WriteString(fmt.Sprintf("Parallelism:        %s\n", formatInt32Ptr(job.Spec.Parallelism)))
+	result.WriteString(fmt.Sprintf("Backoff Limit:      %s\n", formatInt32Ptr(job.Spec.BackoffLimit)))
+	if job.Spec.ActiveDeadlineSeconds != nil {
+		result.WriteString(fmt.Sprintf("Active Deadline:    %ds\n", *job.Spec.ActiveDeadlineSeconds))
+	}
+	if job.Spec.TTLSecondsAfterFinished != nil {
+		result.WriteString(fmt.Sprintf("TTL After Finished: %ds\n", *job.Spec.TTLSecondsAfterFinished))
+	}
+	result.WriteString(fmt.Sprintf("Start Time:         %s\n", formatTimePtr(job.Status.StartTime)))
+	result.WriteString(fmt.Sprintf("Completed At:       %s\n", formatTimePtr(job.Status.CompletionTime)))
+	result.WriteString(fmt.Sprintf("Duration:           %s\n", jobDuration(job)))
+	result.WriteString(fmt.Sprintf("Pods Statuses:      %d Active / %d Succeeded / %d Failed\n",
+		job.Status.Active, job.Status.Succeeded, job.Status.Failed))
+
+	// 条件
+	if len(job.Status.Conditions) > 0 {
+		result.WriteString("Conditions:\n")
+		result.WriteString("  TYPE\tSTATUS\tREASON\tMESSAGE\n")
+		for _, condition := range job.Status.Conditions {
+			result.WriteString(fmt.Sprintf("  %s\t%s\t%s\t%s\n",
+				condition.Type, condition.Status, condition.Reason, condition.Message))
+		}
+	}
+
+	// 容器信息
+	result.WriteString("Containers:\n")
+	for _, c := range job.Spec.Template.Spec.Containers {
+		result.WriteString(fmt.Sprintf("  Name:     %s\n", c.Name))
+		result.WriteString(fmt.Sprintf("  Image:    %s\n", c.Image))
+		if len(c.Command) > 0 {
+			result.WriteString(fmt.Sprintf("  Command:  %s\n", strings.Join(c.Command, " ")))
+		}
+		if len(c.Args) > 0 {
+			result.WriteString(fmt.Sprintf("  Args:     %s\n", strings.Join(c.Args, " ")))
+		}
+	}
+	result.WriteString(fmt.Sprintf("Restart Policy:     %s\n", job.Spec.Template.Spec.RestartPolicy))
+
+	// 事件
+	if events != nil && len(events.Items) > 0 {
+		result.WriteString("\nEvents:\n")
+		result.WriteString("LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+		for _, event := range events.Items {
+			result.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n",
+				formatAge(event.LastTimestamp.Time),
+				event.Type,
+				event.Reason,
+				event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name,
+				event.Message))
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 删除Job的工具函数，Job创建的Pod在后台一并删除
+func DeleteJobTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jobName := request.Params.Arguments["job_name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: delete_job, job_name=", jobName, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	// 演练模式下先获取现有对象用于对比
+	var live *batchv1.Job
+	if dryRun {
+		live, err = clientset.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取Job失败: %v", err)), err
+		}
+	}
+
+	// Job的删除默认会保留Pod，与kubectl一样使用后台级联删除
+	propagation := metav1.DeletePropagationBackground
+	err = clientset.BatchV1().Jobs(namespace).Delete(ctx, jobName, metav1.DeleteOptions{
+		DryRun:            dryRunOption(dryRun),
+		PropagationPolicy: &propagation,
+	})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("删除Job失败: %v", err)), err
+	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("删除Job %s/%s", namespace, jobName), live, nil)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Job %s 在命名空间 %s 中已删除，其创建的Pod将在后台删除", jobName, namespace)), nil
+}
+
+// jobStatus 返回Job的状态
+func jobStatus(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return jobComplete
+		case batchv1.JobFailed:
+			return jobFailed
+		}
+	}
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return jobSuspended
+	}
+	return jobRunning
+}
+
+// jobFailedCondition 返回Job失败的条件，未失败时返回nil
+func jobFailedCondition(job *batchv1.Job) *batchv1.JobCondition {
+	for i, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// jobCompletions 返回成功数与期望完成数，例如 1/1；未设置completions时只要求一个Pod成功
+func jobCompletions(job *batchv1.Job) string {
+	if job.Spec.Completions == nil {
+		return fmt.Sprintf("%d/1 of %s", job.Status.Succeeded, formatInt32Ptr(job.Spec.Parallelism))
+	}
+	return fmt.Sprintf("%d/%d", job.Status.Succeeded, *job.Spec.Completions)
+}
+
+// jobDuration 返回Job的运行时长，未结束的Job计算到当前时间
+func jobDuration(job *batchv1.Job) string {
+	if job.Status.StartTime == nil {
+		return "<none>"
+	}
+	end := time.Now()
+	if job.Status.CompletionTime != nil {
+		end = job.Status.CompletionTime.Time
+	}
+	return end.Sub(job.Status.StartTime.Time).Round(time.Second).String()
+}
+
+// jobOwner 返回创建该Job的CronJob名称
+func jobOwner(job *batchv1.Job) string {
+	if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+		return owner.Name
+	}
+	return ""
+}
+
+// formatInt32Ptr 格式化可选的整数字段，未设置时返回<unset>
+func formatInt32Ptr(v *int32) string {
+	if v == nil {
+		return "<unset>"
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+// formatTimePtr 格式化可选的时间字段，未设置时返回<none>
+func formatTimePtr(t *metav1.Time) string {
+	if t == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s (%s前)", t.Format(time.RFC3339), formatAge(t.Time))
+}
+
+// 辅助函数：获取Job相关事件
+func getEventsForJob(ctx context.Context, clientset *kubernetes.Clientset, job *batchv1.Job) (*corev1.EventList, error) {
+	fieldSelector := fmt.Sprintf("involvedObject.kind=Job,involvedObject.name=%s,involvedObject.namespace=%s",
+		job.Name, job.Namespace)
+	return clientset.CoreV1().Events(job.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fieldSelector,
+	})
+}
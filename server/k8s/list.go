@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// AllNamespacesArg 列表工具中表示跨所有命名空间查询的参数名，授权检查也使用该参数
+const AllNamespacesArg = "all_namespaces"
+
+// WithListArguments 为列表工具追加标签选择器、字段选择器和分页参数
+func WithListArguments() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithString("label_selector",
+			mcp.Description("标签选择器，语法与kubectl -l相同，例如: app=checkout,tier!=cache 或 env in (prod,staging)"),
+		)(tool)
+		mcp.WithString("field_selector",
+			mcp.Description("字段选择器，语法与kubectl --field-selector相同，例如: status.phase=Running 或 spec.nodeName=node-1"),
+		)(tool)
+		mcp.WithNumber("limit",
+			mcp.Description("每页最多返回的条数，0表示不分页"),
+			mcp.DefaultNumber(0),
+		)(tool)
+		mcp.WithString("continue",
+			mcp.Description("上一页结果中返回的continue令牌，用于获取下一页，查询条件需与上一页一致"),
+		)(tool)
+	}
+}
+
+// WithAllNamespacesArgument 为命名空间级资源的列表工具追加all_namespaces参数
+func WithAllNamespacesArgument() mcp.ToolOption {
+	return mcp.WithBoolean(AllNamespacesArg,
+		mcp.Description("是否查询所有命名空间，为true时忽略namespace参数"),
+		mcp.DefaultBool(false),
+	)
+}
+
+// listScope 列表工具的查询范围和分页参数
+type listScope struct {
+	namespace     string // 查询的命名空间，查询所有命名空间或集群级资源时为空
+	allNamespaces bool
+	clusterScoped bool // 集群级资源，没有命名空间
+	options       metav1.ListOptions
+}
+
+// parseListScope 读取并校验namespace、all_namespaces、选择器和分页参数
+func parseListScope(request mcp.CallToolRequest) (listScope, error) {
+	args := request.Params.Arguments
+	scope := listScope{}
+	scope.allNamespaces, _ = args[AllNamespacesArg].(bool)
+	if !scope.allNamespaces {
+		scope.namespace, _ = args["namespace"].(string)
+		if scope.namespace == "" {
+			scope.namespace = "default"
+		}
+	}
+
+	if selector, _ := args["label_selector"].(string); selector != "" {
+		if _, err := labels.Parse(selector); err != nil {
+			return scope, fmt.Errorf("标签选择器 %q 无效: %v", selector, err)
+		}
+		scope.options.LabelSelector = selector
+	}
+	if selector, _ := args["field_selector"].(string); selector != "" {
+		if _, err := fields.ParseSelector(selector); err != nil {
+			return scope, fmt.Errorf("字段选择器 %q 无效: %v", selector, err)
+		}
+		scope.options.FieldSelector = selector
+	}
+	if limit, ok := args["limit"].(float64); ok {
+		if limit < 0 {
+			return scope, fmt.Errorf("limit不能为负数: %v", limit)
+		}
+		scope.options.Limit = int64(limit)
+	}
+	scope.options.Continue, _ = args["continue"].(string)
+	return scope, nil
+}
+
+// parseClusterListScope 读取集群级资源列表工具的选择器和分页参数
+func parseClusterListScope(request mcp.CallToolRequest) (listScope, error) {
+	scope, err := parseListScope(request)
+	scope.namespace, scope.allNamespaces, scope.clusterScoped = "", false, true
+	return scope, err
+}
+
+// String 返回用于日志的查询范围描述
+func (s listScope) String() string {
+	var parts []string
+	if !s.clusterScoped {
+		parts = append(parts, "namespace="+s.displayNamespace())
+	}
+	if s.options.LabelSelector != "" {
+		parts = append(parts, "label_selector="+s.options.LabelSelector)
+	}
+	if s.options.FieldSelector != "" {
+		parts = append(parts, "field_selector="+s.options.FieldSelector)
+	}
+	if s.options.Limit > 0 {
+		parts = append(parts, fmt.Sprintf("limit=%d", s.options.Limit))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// displayNamespace 返回文本输出中显示的命名空间
+func (s listScope) displayNamespace() string {
+	if s.allNamespaces {
+		return "<所有命名空间>"
+	}
+	return s.namespace
+}
+
+// header 返回文本表格的标题，查询所有命名空间时增加NAMESPACE列
+func (s listScope) header(columns string) string {
+	var header strings.Builder
+	if !s.clusterScoped {
+		header.WriteString(fmt.Sprintf("命名空间: %s\n", s.displayNamespace()))
+	}
+	if s.options.LabelSelector != "" {
+		header.WriteString(fmt.Sprintf("标签选择器: %s\n", s.options.LabelSelector))
+	}
+	if s.options.FieldSelector != "" {
+		header.WriteString(fmt.Sprintf("字段选择器: %s\n", s.options.FieldSelector))
+	}
+	if header.Len() > 0 {
+		header.WriteString("\n")
+	}
+	if s.allNamespaces {
+		header.WriteString("NAMESPACE\t")
+	}
+	header.WriteString(columns + "\n")
+	return header.String()
+}
+
+// row 返回表格行的前缀，查询所有命名空间时为对象所在的命名空间
+func (s listScope) row(namespace string) string {
+	if s.allNamespaces {
+		return namespace + "\t"
+	}
+	return ""
+}
+
+// footer 返回分页提示，没有下一页时返回空字符串
+func (s listScope) footer(list metav1.ListInterface) string {
+	token := list.GetContinue()
+	if token == "" {
+		return ""
+	}
+	hint := fmt.Sprintf("\n还有更多结果，使用相同的查询条件并设置 continue=%s 获取下一页", token)
+	if remaining := list.GetRemainingItemCount(); remaining != nil {
+		hint += fmt.Sprintf("（剩余约%d条）", *remaining)
+	}
+	return hint + "\n"
+}
+
+// output 生成列表工具的结构化结果
+func (s listScope) output(kind string, items interface{}, list metav1.ListInterface) listOutput {
+	return listOutput{
+		Kind:               kind,
+		Namespace:          s.namespace,
+		AllNamespaces:      s.allNamespaces,
+		Items:              items,
+		Continue:           list.GetContinue(),
+		RemainingItemCount: list.GetRemainingItemCount(),
+	}
+}
@@ -0,0 +1,319 @@
+package k8s
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// ApplyFieldManager 服务端应用使用的字段管理器名称，用于在managedFields中识别本服务写入的字段
+const ApplyFieldManager = "mcp-devops"
+
+// 服务端应用后每个对象的结果，与kubectl apply的输出一致
+const (
+	applyCreated    = "created"
+	applyConfigured = "configured"
+	applyUnchanged  = "unchanged"
+	applyFailed     = "failed"
+)
+
+// manifestObject 清单中的一个对象及其应用结果
+type manifestObject struct {
+	object  *unstructured.Unstructured
+	mapping *meta.RESTMapping
+	live    *unstructured.Unstructured // 应用前的对象，不存在时为nil
+	result  *unstructured.Unstructured // 服务端应用返回的对象
+	status  string
+	err     error
+}
+
+// ref 返回kubectl风格的对象引用，例如 deployment.apps/web
+func (o *manifestObject) ref() string {
+	gvk := o.object.GroupVersionKind()
+	kind := strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		kind += "." + gvk.Group
+	}
+	return kind + "/" + o.object.GetName()
+}
+
+// fail 记录对象处理失败的原因
+func (o *manifestObject) fail(err error) {
+	o.status, o.err = applyFailed, err
+}
+
+// parseManifest 解析多文档YAML或JSON清单，kind为List的文档展开为其中的对象
+func parseManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	var objects []*unstructured.Unstructured
+	for doc := 1; ; doc++ {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("解析清单的第%d个文档失败: %v", doc, err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("解析清单的第%d个文档中的List失败: %v", doc, err)
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	for i, obj := range objects {
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("清单中的第%d个对象缺少apiVersion或kind", i+1)
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("清单中的第%d个对象 %s 缺少metadata.name，服务端应用不支持generateName", i+1, obj.GetKind())
+		}
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("清单中没有任何对象")
+	}
+	return objects, nil
+}
+
+// applyManifest 解析manifest参数并逐个以服务端应用方式提交，单个对象失败不影响其他对象
+// 对象中没有命名空间时使用namespace参数，与namespace参数不一致时拒绝，保证授权策略对namespace参数的检查覆盖所有命名空间级对象
+// 集群级对象没有命名空间可供检查，调用方只允许部分命名空间时整个清单都不会提交，包括演练
+func applyManifest(ctx context.Context, request mcp.CallToolRequest, dryRun bool) ([]*manifestObject, error) {
+	manifest, _ := request.Params.Arguments["manifest"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	force, _ := request.Params.Arguments["force_conflicts"].(bool)
+
+	objects, err := parseManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	client, mapper, err := GetDynamicClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取Kubernetes客户端失败: %v", err)
+	}
+
+	// 先解析所有对象的资源类型和命名空间，确认都有权限后再提交
+	items := make([]*manifestObject, 0, len(objects))
+	for _, obj := range objects {
+		item := &manifestObject{object: obj}
+		items = append(items, item)
+
+		gvk := obj.GroupVersionKind()
+		item.mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			// 清单中可能包含刚安装的CRD对应的对象，刷新发现缓存后再试一次
+			mapper.Reset()
+			item.mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+		if err != nil {
+			item.fail(fmt.Errorf("集群中不存在资源类型 %s: %v", gvk.GroupVersion().WithKind(gvk.Kind), err))
+			continue
+		}
+		if err := checkResourceScope(ctx, item.mapping); err != nil {
+			return nil, fmt.Errorf("清单中的 %s 无法提交: %v", item.ref(), err)
+		}
+
+		if item.mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace(namespace)
+			} else if obj.GetNamespace() != namespace {
+				item.fail(fmt.Errorf("对象的命名空间 %s 与namespace参数 %s 不一致", obj.GetNamespace(), namespace))
+			}
+		} else {
+			obj.SetNamespace("")
+		}
+	}
+
+	for _, item := range items {
+		if item.status != applyFailed {
+			item.apply(ctx, client, force, dryRun)
+		}
+	}
+	return items, nil
+}
+
+// apply 以服务端应用方式提交对象，并与应用前的对象比较得出结果
+func (o *manifestObject) apply(ctx context.Context, client dynamic.Interface, force, dryRun bool) {
+	var resource dynamic.ResourceInterface = client.Resource(o.mapping.Resource)
+	if o.object.GetNamespace() != "" {
+		resource = client.Resource(o.mapping.Resource).Namespace(o.object.GetNamespace())
+	}
+
+	live, err := resource.Get(ctx, o.object.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		o.fail(fmt.Errorf("获取现有对象失败: %v", err))
+		return
+	}
+	if err == nil {
+		o.live = live
+	}
+
+	o.result, err = resource.Apply(ctx, o.object.GetName(), o.object, metav1.ApplyOptions{
+		FieldManager: ApplyFieldManager,
+		Force:        force,
+		DryRun:       dryRunOption(dryRun),
+	})
+	if err != nil {
+		if apierrors.IsConflict(err) && !force {
+			err = fmt.Errorf("%v（字段由其他管理器持有，确认需要覆盖时设置force_conflicts=true）", err)
+		}
+		o.fail(err)
+		return
+	}
+
+	switch {
+	case o.live == nil:
+		o.status = applyCreated
+	case sameObject(o.live, o.result):
+		o.status = applyUnchanged
+	default:
+		o.status = applyConfigured
+	}
+}
+
+// sameObject 忽略managedFields、resourceVersion和status比较两个对象
+func sameObject(a, b *unstructured.Unstructured) bool {
+	aYAML, aErr := objectYAML(a, nil)
+	bYAML, bErr := objectYAML(b, nil)
+	return aErr == nil && bErr == nil && aYAML == bYAML
+}
+
+// 以服务端应用方式提交YAML/JSON清单的工具函数
+func ApplyManifestTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	dryRun := isDryRun(request)
+	force, _ := request.Params.Arguments["force_conflicts"].(bool)
+
+	fmt.Println("ai 正在调用mcp server的tool: apply_manifest, namespace=", request.Params.Arguments["namespace"], ", force_conflicts=", force, ", dry_run=", dryRun)
+
+	items, err := applyManifest(ctx, request, dryRun)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	var result strings.Builder
+	if dryRun {
+		result.WriteString("【演练模式】清单已通过服务端校验（DryRun=All），未对集群做任何修改\n\n")
+	}
+	result.WriteString(fmt.Sprintf("服务端应用（字段管理器 %s）:\n", ApplyFieldManager))
+	failed := writeApplyTable(&result, items, dryRun)
+
+	// 演练模式下给出每个有变化的对象与现有对象的差异
+	if dryRun {
+		diff, err := manifestDiff(items)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("生成差异失败: %v", err)), err
+		}
+		if diff != "" {
+			result.WriteString("\n与现有对象的差异:\n")
+			result.WriteString(diff)
+		}
+	}
+
+	if failed > 0 {
+		result.WriteString(fmt.Sprintf("\n%d/%d 个对象应用失败，其余对象的结果见上表\n", failed, len(items)))
+		return mcp.NewToolResultError(result.String()), nil
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 比较清单与集群中现有对象差异的工具函数
+func DiffManifestTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	fmt.Println("ai 正在调用mcp server的tool: diff_manifest, namespace=", request.Params.Arguments["namespace"])
+
+	// 以服务端应用演练得到应用后的对象，差异中包含默认值、准入控制器和其他字段管理器的影响
+	items, err := applyManifest(ctx, request, true)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	diff, err := manifestDiff(items)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("生成差异失败: %v", err)), err
+	}
+
+	var result strings.Builder
+	result.WriteString("清单与集群中现有对象的比较（通过服务端应用演练计算，未对集群做任何修改）:\n")
+	failed := writeApplyTable(&result, items, false)
+	if diff == "" {
+		result.WriteString("\n差异: 无变化\n")
+	} else {
+		result.WriteString("\n差异（已去掉managedFields、resourceVersion和status，Secret的值以摘要代替）:\n")
+		result.WriteString(diff)
+	}
+
+	if failed > 0 {
+		result.WriteString(fmt.Sprintf("\n%d/%d 个对象无法比较，原因见上表\n", failed, len(items)))
+		return mcp.NewToolResultError(result.String()), nil
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// writeApplyTable 输出每个对象的应用结果，返回失败的对象数
+func writeApplyTable(result *strings.Builder, items []*manifestObject, dryRun bool) int {
+	result.WriteString("RESOURCE\tNAMESPACE\tRESULT\n")
+	failed := 0
+	for _, item := range items {
+		namespace := item.object.GetNamespace()
+		if namespace == "" {
+			namespace = "<none>"
+		}
+		status := item.status
+		if item.err != nil {
+			failed++
+			status = fmt.Sprintf("%s: %v", applyFailed, item.err)
+		} else if dryRun {
+			status += " (server dry run)"
+		}
+		result.WriteString(fmt.Sprintf("%s\t%s\t%s\n", item.ref(), namespace, status))
+	}
+	return failed
+}
+
+// manifestDiff 生成每个已成功提交对象的应用前后差异，没有任何变化时返回空字符串
+func manifestDiff(items []*manifestObject) (string, error) {
+	// 与dryRunResult一样使用随机密钥对Secret的值做摘要
+	redactKey := make([]byte, 32)
+	if _, err := rand.Read(redactKey); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for _, item := range items {
+		if item.err != nil || item.status == applyUnchanged {
+			continue
+		}
+		liveYAML, err := objectYAML(item.live, redactKey)
+		if err != nil {
+			return "", err
+		}
+		resultYAML, err := objectYAML(item.result, redactKey)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(unifiedDiff("live/"+item.ref(), "merged/"+item.ref(), liveYAML, resultYAML))
+	}
+	return out.String(), nil
+}
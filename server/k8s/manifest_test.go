@@ -0,0 +1,147 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+)
+
+// rbacManifest 同时包含命名空间级和集群级对象的清单
+const rbacManifest = `apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: payments-edit
+roleRef: {apiGroup: rbac.authorization.k8s.io, kind: ClusterRole, name: edit}
+subjects: [{kind: ServiceAccount, name: deployer, namespace: default}]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: deployer-admin
+roleRef: {apiGroup: rbac.authorization.k8s.io, kind: ClusterRole, name: cluster-admin}
+subjects: [{kind: ServiceAccount, name: deployer, namespace: default}]
+`
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []string // 解析出的对象，格式为 kind/name
+		wantErr  string
+	}{
+		{
+			name:     "单个YAML文档",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+			want:     []string{"ConfigMap/app"},
+		},
+		{
+			name: "多文档YAML",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n" +
+				"---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+			want: []string{"ConfigMap/app", "Deployment/web"},
+		},
+		{
+			name: "跳过空文档和只有注释的文档",
+			manifest: "---\n# 注释\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n---\n\n---\n" +
+				"apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n---\n",
+			want: []string{"ConfigMap/app", "Secret/db"},
+		},
+		{
+			name:     "JSON对象",
+			manifest: `{"apiVersion":"v1","kind":"Service","metadata":{"name":"web"}}`,
+			want:     []string{"Service/web"},
+		},
+		{
+			name: "JSON List展开为其中的对象",
+			manifest: `{"apiVersion":"v1","kind":"List","items":[` +
+				`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}},` +
+				`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}]}`,
+			want: []string{"ConfigMap/a", "ConfigMap/b"},
+		},
+		{
+			name: "YAML中的List与普通文档混合",
+			manifest: "apiVersion: v1\nkind: ConfigMapList\nitems:\n- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: a\n" +
+				"---\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+			want: []string{"ConfigMap/a", "Service/web"},
+		},
+		{
+			name:     "只有空文档",
+			manifest: "---\n---\n",
+			wantErr:  "清单中没有任何对象",
+		},
+		{
+			name:     "空字符串",
+			manifest: "",
+			wantErr:  "清单中没有任何对象",
+		},
+		{
+			name:     "缺少kind",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nmetadata:\n  name: b\n",
+			wantErr:  "第2个对象缺少apiVersion或kind",
+		},
+		{
+			name:     "缺少名称",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  generateName: app-\n",
+			wantErr:  "缺少metadata.name",
+		},
+		{
+			name:     "YAML格式错误",
+			manifest: "apiVersion: v1\nkind: ConfigMap\n---\nmetadata: [\n",
+			wantErr:  "第2个文档",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := parseManifest(tt.manifest)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseManifest() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseManifest() = %v", err)
+			}
+			var got []string
+			for _, obj := range objects {
+				got = append(got, obj.GetKind()+"/"+obj.GetName())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("parseManifest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyManifestClusterScope(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		api := newFakeAPIServer(t, nil)
+		request := toolRequest(map[string]interface{}{"manifest": rbacManifest, "namespace": "default"})
+
+		// 命名空间受限的调用方不能提交集群级对象，同一清单中的命名空间级对象也不提交
+		_, err := applyManifest(WithNamespaceRestriction(api.context()), request, dryRun)
+		if err == nil || !strings.Contains(err.Error(), "clusterrolebinding.rbac.authorization.k8s.io/deployer-admin") {
+			t.Errorf("dryRun=%v: applyManifest() = %v, want 拒绝ClusterRoleBinding", dryRun, err)
+		}
+		if requests := api.recorded(); len(requests) != 0 {
+			t.Errorf("dryRun=%v: 拒绝前不应请求API Server, got %v", dryRun, requests)
+		}
+	}
+
+	// 允许所有命名空间时两个对象都会提交
+	api := newFakeAPIServer(t, nil)
+	request := toolRequest(map[string]interface{}{"manifest": rbacManifest, "namespace": "default"})
+	items, err := applyManifest(api.context(), request, true)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("applyManifest() = %d 个对象, %v", len(items), err)
+	}
+	requests := strings.Join(api.recorded(), "\n")
+	for _, want := range []string{
+		"PATCH /apis/rbac.authorization.k8s.io/v1/namespaces/default/rolebindings/payments-edit",
+		"PATCH /apis/rbac.authorization.k8s.io/v1/clusterrolebindings/deployer-admin",
+	} {
+		if !strings.Contains(requests, want) {
+			t.Errorf("请求中缺少 %s:\n%s", want, requests)
+		}
+	}
+}
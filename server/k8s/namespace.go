@@ -12,36 +12,63 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// namespaceSummary Namespace列表中的一行
+type namespaceSummary struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Age    string `json:"age"`
+}
+
 // 列出Namespace的工具函数
 func ListNamespacesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	fmt.Println("ai 正在调用mcp server的tool: list_namespaces")
+	scope, err := parseClusterListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: list_namespaces,", scope)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Namespace列表
-	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Namespace列表失败: %v", err)), err
 	}
 
+	items := make([]namespaceSummary, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		items = append(items, namespaceSummary{
+			Name:   ns.Name,
+			Status: string(ns.Status.Phase),
+			Age:    formatAge(ns.CreationTimestamp.Time),
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("Namespace", items, namespaces))
+	}
+
 	// 格式化输出
 	var result strings.Builder
-	result.WriteString("NAME\tSTATUS\tAGE\n")
-
-	for _, ns := range namespaces.Items {
-		// 计算运行时间
-		age := formatAge(ns.CreationTimestamp.Time)
+	result.WriteString(scope.header("NAME\tSTATUS\tAGE"))
 
+	for _, item := range items {
 		result.WriteString(fmt.Sprintf("%s\t%s\t%s\n",
-			ns.Name,
-			string(ns.Status.Phase),
-			age))
+			item.Name,
+			item.Status,
+			item.Age))
 	}
 
+	result.WriteString(scope.footer(namespaces))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -49,12 +76,17 @@ func ListNamespacesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 func DescribeNamespaceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	namespaceName := request.Params.Arguments["namespace_name"].(string)
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_namespace, namespace_name=", namespaceName)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Namespace详情
@@ -63,6 +95,11 @@ func DescribeNamespaceTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		return mcp.NewToolResultText(fmt.Sprintf("获取Namespace详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		events, _ := getEventsForNamespace(ctx, clientset, namespace)
+		return describeResult(format, namespace, events)
+	}
+
 	// 格式化输出
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:              %s\n", namespace.Name))
@@ -169,12 +206,14 @@ func DescribeNamespaceTool(ctx context.Context, request mcp.CallToolRequest) (*m
 func CreateNamespaceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	namespaceName := request.Params.Arguments["namespace_name"].(string)
 
-	fmt.Println("ai 正在调用mcp server的tool: create_namespace, namespace_name=", namespaceName)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: create_namespace, namespace_name=", namespaceName, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 创建Namespace对象
@@ -185,10 +224,13 @@ func CreateNamespaceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	}
 
 	// 创建Namespace
-	_, err = clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+	created, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("创建Namespace失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("创建Namespace %s", namespaceName), nil, created)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Namespace %s 创建成功", namespaceName)), nil
 }
@@ -197,19 +239,33 @@ func CreateNamespaceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 func DeleteNamespaceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	namespaceName := request.Params.Arguments["namespace_name"].(string)
 
-	fmt.Println("ai 正在调用mcp server的tool: delete_namespace, namespace_name=", namespaceName)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: delete_namespace, namespace_name=", namespaceName, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	// 演练模式下先获取现有对象用于对比
+	var live *corev1.Namespace
+	if dryRun {
+		live, err = clientset.CoreV1().Namespaces().Get(ctx, namespaceName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取Namespace失败: %v", err)), err
+		}
 	}
 
 	// 删除Namespace
-	err = clientset.CoreV1().Namespaces().Delete(ctx, namespaceName, metav1.DeleteOptions{})
+	err = clientset.CoreV1().Namespaces().Delete(ctx, namespaceName, metav1.DeleteOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("删除Namespace失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("删除Namespace %s（其中的所有资源都会被一并删除）", namespaceName), live, nil)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Namespace %s 删除成功（删除过程可能需要一些时间才能完成）", namespaceName)), nil
 }
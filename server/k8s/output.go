@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// 列表和详情工具支持的输出格式
+const (
+	OutputText = "text" // 面向人阅读的表格和文本，默认
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// WithOutputArgument 为列表和详情工具追加output参数
+func WithOutputArgument() mcp.ToolOption {
+	return mcp.WithString("output",
+		mcp.Description("输出格式: text（默认，表格/文本）、json 或 yaml。json/yaml 返回字段固定的结构化结果，便于程序解析"),
+		mcp.Enum(OutputText, OutputJSON, OutputYAML),
+		mcp.DefaultString(OutputText),
+	)
+}
+
+// outputFormat 读取并校验output参数
+func outputFormat(request mcp.CallToolRequest) (string, error) {
+	format, _ := request.Params.Arguments["output"].(string)
+	switch format {
+	case "":
+		return OutputText, nil
+	case OutputText, OutputJSON, OutputYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("不支持的输出格式: %s（仅支持 text、json 或 yaml）", format)
+}
+
+// listOutput 列表工具的结构化结果，items中每一项的字段与文本表格的列一一对应
+// continue不为空表示还有下一页
+type listOutput struct {
+	Kind               string      `json:"kind"`
+	Namespace          string      `json:"namespace,omitempty"`
+	AllNamespaces      bool        `json:"allNamespaces,omitempty"`
+	Items              interface{} `json:"items"`
+	Continue           string      `json:"continue,omitempty"`
+	RemainingItemCount *int64      `json:"remainingItemCount,omitempty"`
+}
+
+// describeOutput 详情工具的结构化结果：去掉managedFields的完整对象以及相关事件
+type describeOutput struct {
+	Object map[string]interface{} `json:"object"`
+	Events []eventSummary         `json:"events"`
+}
+
+// eventSummary 事件的结构化表示
+type eventSummary struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Object   string    `json:"object"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// structuredResult 把结果按json或yaml格式序列化
+func structuredResult(format string, v interface{}) (*mcp.CallToolResult, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if format == OutputYAML {
+		data, err = yaml.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("序列化结果失败: %v", err)), err
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// describeResult 生成详情工具的结构化结果，events可以为nil
+func describeResult(format string, obj runtime.Object, events *corev1.EventList) (*mcp.CallToolResult, error) {
+	content, err := describeContent(obj)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("转换对象失败: %v", err)), err
+	}
+	return structuredResult(format, describeOutput{Object: content, Events: summarizeEvents(events)})
+}
+
+// describeContent 把对象转换为结构化结果中的object字段，保留status，只去掉managedFields
+func describeContent(obj runtime.Object) (map[string]interface{}, error) {
+	content, err := objectContent(obj)
+	if err != nil {
+		return nil, err
+	}
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+	}
+	return content, nil
+}
+
+// summarizeEvents 把事件列表转换为结构化表示
+func summarizeEvents(events *corev1.EventList) []eventSummary {
+	summaries := []eventSummary{}
+	if events == nil {
+		return summaries
+	}
+	for _, event := range events.Items {
+		summaries = append(summaries, eventSummary{
+			Type:     event.Type,
+			Reason:   event.Reason,
+			Object:   event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			Message:  event.Message,
+			Count:    event.Count,
+			LastSeen: event.LastTimestamp.Time,
+		})
+	}
+	return summaries
+}
+
+// joinOrNone 拼接列表，列表为空时返回<none>
+func joinOrNone(items []string, sep string) string {
+	if len(items) == 0 {
+		return "<none>"
+	}
+	return strings.Join(items, sep)
+}
+
+// containerNamesAndImages 返回容器名称和镜像列表
+func containerNamesAndImages(containers []corev1.Container) ([]string, []string) {
+	names := make([]string, 0, len(containers))
+	images := make([]string, 0, len(containers))
+	for _, container := range containers {
+		names = append(names, container.Name)
+		images = append(images, container.Image)
+	}
+	return names, images
+}
@@ -12,61 +12,92 @@ import (
 	"time"
 )
 
+// podSummary Pod列表中的一行
+type podSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Ready     string `json:"ready"`
+	Status    string `json:"status"`
+	Restarts  int32  `json:"restarts"`
+	Age       string `json:"age"`
+	IP        string `json:"ip"`
+	Node      string `json:"node"`
+}
+
+// summarizePod 计算Pod列表中展示的字段
+func summarizePod(pod corev1.Pod) podSummary {
+	// 计算容器就绪数和重启次数
+	var readyContainers int
+	var restarts int32
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Ready {
+			readyContainers++
+		}
+		restarts += containerStatus.RestartCount
+	}
+
+	return podSummary{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Ready:     fmt.Sprintf("%d/%d", readyContainers, len(pod.Spec.Containers)),
+		Status:    string(pod.Status.Phase),
+		Restarts:  restarts,
+		Age:       formatAge(pod.CreationTimestamp.Time),
+		IP:        pod.Status.PodIP,
+		Node:      pod.Spec.NodeName,
+	}
+}
+
 // 列出Pod的工具函数
 func ListPodsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_pods, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_pods,", scope)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Pod列表
-	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	pods, err := clientset.CoreV1().Pods(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Pod列表失败: %v", err)), err
 	}
 
-	// 格式化输出
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tREADY\tSTATUS\tRESTARTS\tAGE\tIP\tNODE\n")
-
+	items := make([]podSummary, 0, len(pods.Items))
 	for _, pod := range pods.Items {
-		// 计算容器就绪数
-		var readyContainers int
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			if containerStatus.Ready {
-				readyContainers++
-			}
-		}
-
-		// 计算重启次数
-		var restarts int32
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			restarts += containerStatus.RestartCount
-		}
+		items = append(items, summarizePod(pod))
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("Pod", items, pods))
+	}
 
-		// 计算运行时间
-		age := formatAge(pod.CreationTimestamp.Time)
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tREADY\tSTATUS\tRESTARTS\tAGE\tIP\tNODE"))
 
-		result.WriteString(fmt.Sprintf("%s\t%d/%d\t%s\t%d\t%s\t%s\t%s\n",
-			pod.Name,
-			readyContainers,
-			len(pod.Spec.Containers),
-			string(pod.Status.Phase),
-			restarts,
-			age,
-			pod.Status.PodIP,
-			pod.Spec.NodeName))
+	for _, item := range items {
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			item.Name,
+			item.Ready,
+			item.Status,
+			item.Restarts,
+			item.Age,
+			item.IP,
+			item.Node))
 	}
 
+	result.WriteString(scope.footer(pods))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -77,12 +108,17 @@ func DsscribePodTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 		namespace = "default"
 	}
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_pod, pod_name=", podName, ", namespace=", namespace)
 
-	// 创建kubernetes客户端
-	clientset, err := CreateK8sClient()
+	// 获取kubernetes客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获得pod的详情
@@ -91,11 +127,16 @@ func DsscribePodTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 		return mcp.NewToolResultText(fmt.Sprintf("获取Pod详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		events, _ := getEventsForPod(ctx, clientset, pod)
+		return describeResult(format, pod, events)
+	}
+
 	//格式化输出
 	var result strings.Builder // Initialize as a value type, not a pointer
 	result.WriteString(fmt.Sprintf("Name:				%s\n", pod.Name))
 	result.WriteString(fmt.Sprintf("Namespace:			%s\n", pod.Namespace))
-	result.WriteString(fmt.Sprintf("Priority:			%s\n", getPodPriority(pod)))
+	result.WriteString(fmt.Sprintf("Priority:			%d\n", getPodPriority(pod)))
 	result.WriteString(fmt.Sprintf("Node:         		%s\n", pod.Spec.NodeName))
 	result.WriteString(fmt.Sprintf("Start Time:   		%s\n", pod.CreationTimestamp.Format(time.RFC3339)))
 	result.WriteString(fmt.Sprintf("Labels:       		%s\n", formatLabels(pod.Labels)))
@@ -185,24 +226,38 @@ func DeletePodTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallT
 		namespace = "default"
 	}
 	force, _ := request.Params.Arguments["force"].(bool)
+	dryRun := isDryRun(request)
 
-	fmt.Println("ai 正在调用mcp server的tool: delete_pod, pod_name=", podName, ", namespace=", namespace, ", force=", force)
+	fmt.Println("ai 正在调用mcp server的tool: delete_pod, pod_name=", podName, ", namespace=", namespace, ", force=", force, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 	// 设置删除选项
-	deleteOptions := metav1.DeleteOptions{}
+	deleteOptions := metav1.DeleteOptions{DryRun: dryRunOption(dryRun)}
 	if force {
 		gracePeriod := int64(0)
 		deleteOptions.GracePeriodSeconds = &gracePeriod
 	}
+
+	// 演练模式下先获取现有对象用于对比
+	var live *corev1.Pod
+	if dryRun {
+		live, err = clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取Pod失败: %v", err)), err
+		}
+	}
+
 	err = clientset.CoreV1().Pods(namespace).Delete(ctx, podName, deleteOptions)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("删除Pod失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("删除Pod %s/%s", namespace, podName), live, nil)
+	}
 	return mcp.NewToolResultText(fmt.Sprintf("Pod %s 在命名空间 %s 中已成功删除", podName, namespace)), nil
 }
 
@@ -221,10 +276,10 @@ func PodLogsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 
 	fmt.Println("ai 正在调用mcp server的tool: pod_logs, pod_name=", podName, ", namespace=", namespace, ", container=", container)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 设置日志选项
@@ -0,0 +1,423 @@
+package k8s
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// WithResourceArguments 为通用资源工具追加resource和api_version参数
+func WithResourceArguments() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithString("resource",
+			mcp.Required(),
+			mcp.Description("资源类型，支持kubectl api-resources中的复数名、单数名、简称或Kind，包括CRD，例如: deploy、vs、certificate、rollouts.argoproj.io"),
+		)(tool)
+		mcp.WithString("api_version",
+			mcp.Description("可选，指定资源所在的API组和版本，例如: networking.istio.io/v1beta1，不提供则使用集群的首选版本"),
+		)(tool)
+	}
+}
+
+// resourceTarget 通用资源工具解析出的资源类型和访问该资源的客户端
+type resourceTarget struct {
+	mapping *meta.RESTMapping
+	client  dynamic.NamespaceableResourceInterface
+}
+
+// parseResourceTarget 读取resource和api_version参数，通过发现接口解析资源类型
+// 授权策略只检查namespace参数，集群级资源由checkResourceScope按调用方的命名空间范围单独检查
+func parseResourceTarget(ctx context.Context, request mcp.CallToolRequest) (resourceTarget, error) {
+	resource, _ := request.Params.Arguments["resource"].(string)
+	apiVersion, _ := request.Params.Arguments["api_version"].(string)
+	if strings.TrimSpace(resource) == "" {
+		return resourceTarget{}, fmt.Errorf("必须提供resource参数")
+	}
+
+	client, mapper, err := GetDynamicClient(ctx)
+	if err != nil {
+		return resourceTarget{}, fmt.Errorf("获取Kubernetes客户端失败: %v", err)
+	}
+	mapping, err := resolveResource(mapper, resource, apiVersion)
+	if err != nil {
+		return resourceTarget{}, err
+	}
+	if err := checkResourceScope(ctx, mapping); err != nil {
+		return resourceTarget{}, err
+	}
+	return resourceTarget{mapping: mapping, client: client.Resource(mapping.Resource)}, nil
+}
+
+// checkResourceScope 命名空间受限的调用方不能访问集群级资源，集群级资源没有命名空间可供授权策略检查
+func checkResourceScope(ctx context.Context, mapping *meta.RESTMapping) error {
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace && NamespaceRestricted(ctx) {
+		return fmt.Errorf("%s 是集群级资源，授权策略只允许访问部分命名空间，无权操作集群级资源", mapping.GroupVersionKind.Kind)
+	}
+	return nil
+}
+
+// resolveResource 把资源名称解析为REST映射，找不到时刷新发现缓存再试一次，以识别新安装的CRD
+func resolveResource(mapper meta.ResettableRESTMapper, resource, apiVersion string) (*meta.RESTMapping, error) {
+	mapping, err := lookupResource(mapper, resource, apiVersion)
+	if meta.IsNoMatchError(err) {
+		mapper.Reset()
+		mapping, err = lookupResource(mapper, resource, apiVersion)
+	}
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("集群中不存在资源类型 %s，请使用kubectl api-resources中列出的名称、简称或Kind", resource)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析资源类型 %s 失败: %v", resource, err)
+	}
+	return mapping, nil
+}
+
+// lookupResource 按资源名称查找REST映射，支持 resource、resource.group、resource.version.group 形式
+// 单数名和Kind（忽略大小写）由发现接口的映射器处理，简称由ShortcutExpander展开
+func lookupResource(mapper meta.RESTMapper, resource, apiVersion string) (*meta.RESTMapping, error) {
+	arg := strings.ToLower(strings.TrimSpace(resource))
+
+	var gvk schema.GroupVersionKind
+	var err error
+	if apiVersion != "" {
+		gv, parseErr := schema.ParseGroupVersion(apiVersion)
+		if parseErr != nil {
+			return nil, fmt.Errorf("api_version %q 无效: %v", apiVersion, parseErr)
+		}
+		gvk, err = mapper.KindFor(gv.WithResource(arg))
+	} else {
+		fullGVR, gr := schema.ParseResourceArg(arg)
+		if fullGVR != nil {
+			gvk, err = mapper.KindFor(*fullGVR)
+		}
+		if gvk.Empty() {
+			gvk, err = mapper.KindFor(gr.WithVersion(""))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// namespaced 资源是否属于命名空间
+func (t resourceTarget) namespaced() bool {
+	return t.mapping.Scope.Name() == meta.RESTScopeNameNamespace
+}
+
+// kind 返回资源的Kind
+func (t resourceTarget) kind() string {
+	return t.mapping.GroupVersionKind.Kind
+}
+
+// namespace 返回访问单个对象时使用的命名空间，集群级资源返回空字符串
+func (t resourceTarget) namespace(request mcp.CallToolRequest) string {
+	if !t.namespaced() {
+		return ""
+	}
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	return namespace
+}
+
+// resource 返回指定命名空间下的资源客户端
+func (t resourceTarget) resource(namespace string) dynamic.ResourceInterface {
+	if !t.namespaced() {
+		return t.client
+	}
+	return t.client.Namespace(namespace)
+}
+
+// objectName 返回用于提示信息的对象名称，例如 VirtualService default/reviews
+func (t resourceTarget) objectName(namespace, name string) string {
+	if namespace == "" {
+		return t.kind() + " " + name
+	}
+	return fmt.Sprintf("%s %s/%s", t.kind(), namespace, name)
+}
+
+// resourceSummary 通用资源列表中的一行
+type resourceSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Age       string `json:"age"`
+}
+
+// 获取任意资源对象的工具函数
+func GetResourceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, _ := request.Params.Arguments["name"].(string)
+	format, _ := request.Params.Arguments["output"].(string)
+	if format == "" || format == OutputText {
+		format = OutputYAML
+	}
+	if format != OutputJSON && format != OutputYAML {
+		err := fmt.Errorf("不支持的输出格式: %s（仅支持 json 或 yaml）", format)
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	target, err := parseResourceTarget(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	namespace := target.namespace(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: get_resource, resource=", target.mapping.Resource.String(), ", name=", name, ", namespace=", namespace)
+
+	obj, err := target.resource(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取%s失败: %v", target.objectName(namespace, name), err)), err
+	}
+
+	content, err := resourceContent(obj)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("转换对象失败: %v", err)), err
+	}
+	return structuredResult(format, content)
+}
+
+// 列出任意资源的工具函数
+func ListResourcesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	target, err := parseResourceTarget(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	var scope listScope
+	if target.namespaced() {
+		scope, err = parseListScope(request)
+	} else {
+		scope, err = parseClusterListScope(request)
+	}
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: list_resources, resource=", target.mapping.Resource.String()+",", scope)
+
+	list, err := target.resource(scope.namespace).List(ctx, scope.options)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取%s列表失败: %v", target.kind(), err)), err
+	}
+
+	items := make([]resourceSummary, 0, len(list.Items))
+	for _, item := range list.Items {
+		items = append(items, resourceSummary{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Status:    resourceStatus(&item),
+			Age:       formatAge(item.GetCreationTimestamp().Time),
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output(target.kind(), items, list))
+	}
+
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("资源类型: %s (%s)\n", target.kind(), target.mapping.Resource.GroupResource().String()))
+	result.WriteString(scope.header("NAME\tSTATUS\tAGE"))
+	for _, item := range items {
+		status := item.Status
+		if status == "" {
+			status = "<none>"
+		}
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%s\n", item.Name, status, item.Age))
+	}
+	result.WriteString(scope.footer(list))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 查看任意资源对象详情的工具函数
+func DescribeResourceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, _ := request.Params.Arguments["name"].(string)
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	target, err := parseResourceTarget(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	namespace := target.namespace(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: describe_resource, resource=", target.mapping.Resource.String(), ", name=", name, ", namespace=", namespace)
+
+	obj, err := target.resource(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取%s详情失败: %v", target.objectName(namespace, name), err)), err
+	}
+
+	content, err := resourceContent(obj)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("转换对象失败: %v", err)), err
+	}
+	events, _ := getEventsForObject(ctx, obj)
+	if format != OutputText {
+		return structuredResult(format, describeOutput{Object: content, Events: summarizeEvents(events)})
+	}
+
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Name:               %s\n", obj.GetName()))
+	if obj.GetNamespace() != "" {
+		result.WriteString(fmt.Sprintf("Namespace:          %s\n", obj.GetNamespace()))
+	}
+	result.WriteString(fmt.Sprintf("Kind:               %s\n", obj.GetKind()))
+	result.WriteString(fmt.Sprintf("API Version:        %s\n", obj.GetAPIVersion()))
+	result.WriteString(fmt.Sprintf("CreationTimestamp:  %s\n", obj.GetCreationTimestamp().Format(time.RFC3339)))
+	result.WriteString(fmt.Sprintf("Labels:             %s\n", formatLabels(obj.GetLabels())))
+	if status := resourceStatus(obj); status != "" {
+		result.WriteString(fmt.Sprintf("Status:             %s\n", status))
+	}
+
+	// spec、status等其余字段按YAML原样输出，不同资源类型的字段各不相同
+	delete(content, "apiVersion")
+	delete(content, "kind")
+	delete(content, "metadata")
+	if len(content) > 0 {
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("序列化对象失败: %v", err)), err
+		}
+		result.WriteString("\n")
+		result.WriteString(string(data))
+	}
+
+	// 事件
+	if events != nil && len(events.Items) > 0 {
+		result.WriteString("\nEvents:\n")
+		result.WriteString("LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+		for _, event := range events.Items {
+			result.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n",
+				formatAge(event.LastTimestamp.Time),
+				event.Type,
+				event.Reason,
+				event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name,
+				event.Message))
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 删除任意资源对象的工具函数
+func DeleteResourceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, _ := request.Params.Arguments["name"].(string)
+	dryRun := isDryRun(request)
+	target, err := parseResourceTarget(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	namespace := target.namespace(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: delete_resource, resource=", target.mapping.Resource.String(), ", name=", name, ", namespace=", namespace, ", dry_run=", dryRun)
+
+	// 演练模式下先获取现有对象用于对比
+	var live *unstructured.Unstructured
+	if dryRun {
+		live, err = target.resource(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取%s失败: %v", target.objectName(namespace, name), err)), err
+		}
+	}
+
+	err = target.resource(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunOption(dryRun)})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("删除%s失败: %v", target.objectName(namespace, name), err)), err
+	}
+	if dryRun {
+		return dryRunResult("删除"+target.objectName(namespace, name), live, nil)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s 已删除", target.objectName(namespace, name))), nil
+}
+
+// resourceContent 去掉managedFields并隐藏Secret的值，通用资源工具不提供查看Secret明文的方式
+// Secret的隐藏方式与演练差异一致，上次apply的配置注解中的明文也一并隐藏
+func resourceContent(obj *unstructured.Unstructured) (map[string]interface{}, error) {
+	content, err := describeContent(obj)
+	if err != nil {
+		return nil, err
+	}
+	if content["apiVersion"] == "v1" && content["kind"] == "Secret" {
+		// 每次调用使用随机密钥，摘要只能用于比较同一输出中的值是否相同
+		redactKey := make([]byte, 32)
+		if _, err := rand.Read(redactKey); err != nil {
+			return nil, fmt.Errorf("隐藏Secret的值失败: %v", err)
+		}
+		redactSecretData(content, redactKey)
+	}
+	return content, nil
+}
+
+// resourceStatus 从通用对象中提取简短状态：优先使用status.phase，其次使用Ready或Available条件
+func resourceStatus(obj *unstructured.Unstructured) string {
+	if phase, found, _ := unstructured.NestedString(obj.Object, "status", "phase"); found && phase != "" {
+		return phase
+	}
+
+	conditions, found, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if !found {
+		return ""
+	}
+	byType := make(map[string]string, len(conditions))
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		status, _ := condition["status"].(string)
+		byType[conditionType] = status
+	}
+	for _, conditionType := range []string{"Ready", "Available"} {
+		switch byType[conditionType] {
+		case "True":
+			return conditionType
+		case "False":
+			return "Not" + conditionType
+		case "Unknown":
+			return conditionType + "=Unknown"
+		}
+	}
+
+	// 没有Ready/Available条件时列出所有为True的条件
+	var active []string
+	for conditionType, status := range byType {
+		if status == "True" {
+			active = append(active, conditionType)
+		}
+	}
+	sort.Strings(active)
+	return strings.Join(active, ",")
+}
+
+// getEventsForObject 按UID获取与对象相关的事件，集群级对象的事件可能位于任意命名空间
+func getEventsForObject(ctx context.Context, obj *unstructured.Unstructured) (*corev1.EventList, error) {
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().Events(obj.GetNamespace()).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.uid=%s", obj.GetUID()),
+	})
+}
@@ -0,0 +1,231 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// fakeDiscovery 测试API Server的发现接口，包含命名空间级和集群级资源
+var fakeDiscovery = map[string]string{
+	"/api": `{"kind":"APIVersions","versions":["v1"]}`,
+	"/apis": `{"kind":"APIGroupList","apiVersion":"v1","groups":[{"name":"rbac.authorization.k8s.io",` +
+		`"versions":[{"groupVersion":"rbac.authorization.k8s.io/v1","version":"v1"}],` +
+		`"preferredVersion":{"groupVersion":"rbac.authorization.k8s.io/v1","version":"v1"}}]}`,
+	"/api/v1": `{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"v1","resources":[` +
+		`{"name":"pods","singularName":"pod","namespaced":true,"kind":"Pod","verbs":["get","list","delete","patch"]},` +
+		`{"name":"secrets","singularName":"secret","namespaced":true,"kind":"Secret","verbs":["get","list","delete","patch"]},` +
+		`{"name":"namespaces","singularName":"namespace","shortNames":["ns"],"namespaced":false,"kind":"Namespace","verbs":["get","list","delete","patch"]}]}`,
+	"/apis/rbac.authorization.k8s.io/v1": `{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"rbac.authorization.k8s.io/v1","resources":[` +
+		`{"name":"rolebindings","singularName":"rolebinding","namespaced":true,"kind":"RoleBinding","verbs":["get","list","delete","patch"]},` +
+		`{"name":"clusterrolebindings","singularName":"clusterrolebinding","namespaced":false,"kind":"ClusterRoleBinding","verbs":["get","list","delete","patch"]}]}`,
+}
+
+// fakeAPIServer 只提供发现接口和预置对象的测试API Server，记录发现接口之外的请求
+type fakeAPIServer struct {
+	*httptest.Server
+	objects map[string]string // 路径到对象JSON
+
+	mu       sync.Mutex
+	requests []string // "METHOD 路径"
+}
+
+// newFakeAPIServer 启动测试API Server，objects中的对象只响应GET
+func newFakeAPIServer(t *testing.T, objects map[string]string) *fakeAPIServer {
+	t.Helper()
+	s := &fakeAPIServer{objects: objects}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeAPIServer) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if body, ok := fakeDiscovery[r.URL.Path]; ok {
+		w.Write([]byte(body))
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+
+	if body, ok := s.objects[r.URL.Path]; ok && r.Method == http.MethodGet {
+		w.Write([]byte(body))
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "NotFound", "code": http.StatusNotFound,
+	})
+}
+
+// recorded 返回收到的发现接口之外的请求
+func (s *fakeAPIServer) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// context 返回使用该API Server作为当前集群的上下文
+func (s *fakeAPIServer) context() context.Context {
+	provider := newClientProvider("test", ClientOptions{}, func() (*rest.Config, []string, error) {
+		return &rest.Config{Host: s.URL}, nil, nil
+	})
+	manager := &ClusterManager{
+		opts:      ClientOptions{}.withDefaults(),
+		providers: map[string]*ClientProvider{"test": provider},
+	}
+	ctx := context.WithValue(context.Background(), managerKey{}, manager)
+	return context.WithValue(ctx, clusterKey{}, "test")
+}
+
+// toolRequest 构造工具调用请求
+func toolRequest(args map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+	return request
+}
+
+func TestCheckResourceScope(t *testing.T) {
+	pods := &meta.RESTMapping{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Scope:            meta.RESTScopeNamespace,
+	}
+	namespaces := &meta.RESTMapping{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+		Scope:            meta.RESTScopeRoot,
+	}
+	restricted := WithNamespaceRestriction(context.Background())
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		mapping *meta.RESTMapping
+		wantErr bool
+	}{
+		{"未限制命名空间访问命名空间级资源", context.Background(), pods, false},
+		{"未限制命名空间访问集群级资源", context.Background(), namespaces, false},
+		{"限制命名空间访问命名空间级资源", restricted, pods, false},
+		{"限制命名空间访问集群级资源", restricted, namespaces, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkResourceScope(tt.ctx, tt.mapping)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkResourceScope() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "Namespace 是集群级资源") {
+				t.Errorf("错误信息 = %v", err)
+			}
+		})
+	}
+}
+
+func TestResourceToolsRejectClusterScopedForRestrictedCaller(t *testing.T) {
+	tools := map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		"get_resource":      GetResourceTool,
+		"describe_resource": DescribeResourceTool,
+		"list_resources":    ListResourcesTool,
+		"delete_resource":   DeleteResourceTool,
+	}
+
+	for name, tool := range tools {
+		t.Run(name, func(t *testing.T) {
+			api := newFakeAPIServer(t, nil)
+			restricted := WithNamespaceRestriction(api.context())
+
+			// namespace参数为default，但Namespace是集群级资源，必须在请求API Server之前拒绝
+			_, err := tool(restricted, toolRequest(map[string]interface{}{"resource": "ns", "name": "kube-system", "namespace": "default"}))
+			if err == nil || !strings.Contains(err.Error(), "集群级资源") {
+				t.Errorf("集群级资源 err = %v, want 拒绝", err)
+			}
+			if requests := api.recorded(); len(requests) != 0 {
+				t.Errorf("拒绝前不应请求API Server, got %v", requests)
+			}
+
+			// 命名空间级资源不受影响
+			tool(restricted, toolRequest(map[string]interface{}{"resource": "pods", "name": "web", "namespace": "default"}))
+			if requests := api.recorded(); len(requests) == 0 || !strings.Contains(requests[0], "/api/v1/namespaces/default/pods") {
+				t.Errorf("命名空间级资源的请求 = %v", requests)
+			}
+		})
+	}
+
+	// 允许所有命名空间的调用方可以操作集群级资源
+	api := newFakeAPIServer(t, nil)
+	DeleteResourceTool(api.context(), toolRequest(map[string]interface{}{"resource": "namespace", "name": "kube-system"}))
+	if requests := api.recorded(); len(requests) != 1 || requests[0] != "DELETE /api/v1/namespaces/kube-system" {
+		t.Errorf("未限制命名空间时的请求 = %v", requests)
+	}
+}
+
+func TestResourceContentHidesSecret(t *testing.T) {
+	const password = "s3cr3t-password"
+	encoded := "czNjcjN0LXBhc3N3b3Jk" // base64(password)
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      "db",
+			"namespace": "default",
+			"annotations": map[string]interface{}{
+				lastAppliedAnnotation: `{"apiVersion":"v1","kind":"Secret","stringData":{"password":"` + password + `"}}`,
+				"owner":               "payments",
+			},
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"data":       map[string]interface{}{"password": encoded, "copy": encoded},
+		"stringData": map[string]interface{}{"token": password},
+	}}
+
+	content, err := resourceContent(secret)
+	if err != nil {
+		t.Fatalf("resourceContent: %v", err)
+	}
+	out, _ := json.Marshal(content)
+	for _, leaked := range []string{password, encoded} {
+		if strings.Contains(string(out), leaked) {
+			t.Errorf("输出中包含Secret的值 %q: %s", leaked, out)
+		}
+	}
+
+	metadata := content["metadata"].(map[string]interface{})
+	if _, ok := metadata["managedFields"]; ok {
+		t.Error("应该去掉managedFields")
+	}
+	if metadata["annotations"].(map[string]interface{})["owner"] != "payments" {
+		t.Error("其他注解不应被隐藏")
+	}
+	data := content["data"].(map[string]interface{})
+	if data["password"] != data["copy"] || !strings.HasPrefix(data["password"].(string), "<已隐藏") {
+		t.Errorf("相同的值应该得到相同的占位符: %v", data)
+	}
+	if secret.Object["data"].(map[string]interface{})["password"] != encoded {
+		t.Error("不应修改原对象")
+	}
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "app"},
+		"data":       map[string]interface{}{"mode": "debug"},
+	}}
+	content, err = resourceContent(configMap)
+	if err != nil {
+		t.Fatalf("resourceContent: %v", err)
+	}
+	if content["data"].(map[string]interface{})["mode"] != "debug" {
+		t.Errorf("ConfigMap的data不应被隐藏: %v", content["data"])
+	}
+}
@@ -0,0 +1,762 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// changeCauseAnnotation 记录变更原因的注解，rollout_history中显示为CHANGE-CAUSE
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+	// deploymentRevisionAnnotation Deployment控制器写在ReplicaSet上的修订版本号
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	// pausedPartitionAnnotation 暂停StatefulSet滚动更新前的partition，恢复时还原
+	pausedPartitionAnnotation = "mcp-devops/rollout-paused-partition"
+	// pausedStrategyAnnotation 暂停DaemonSet滚动更新前的更新策略（JSON），恢复时还原
+	pausedStrategyAnnotation = "mcp-devops/rollout-paused-strategy"
+)
+
+// rolloutRevision 工作负载的一个修订版本
+// Deployment的修订版本来自ReplicaSet，StatefulSet和DaemonSet来自ControllerRevision
+type rolloutRevision struct {
+	Revision    int64
+	Name        string
+	ChangeCause string
+	Images      []string
+	Created     time.Time
+	template    corev1.PodTemplateSpec
+	data        []byte // ControllerRevision中保存的补丁，回滚时直接提交，Deployment为nil
+}
+
+// rolloutHistory 获取工作负载的全部修订版本，按修订版本号升序排列，最后一个为当前版本
+func rolloutHistory(ctx context.Context, clientset *kubernetes.Clientset, w *workload) ([]*rolloutRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(w.selector())
+	if err != nil {
+		return nil, fmt.Errorf("解析选择器失败: %v", err)
+	}
+	namespace := w.meta().GetNamespace()
+	opts := metav1.ListOptions{LabelSelector: selector.String()}
+
+	var revisions []*rolloutRevision
+	if w.kind == KindDeployment {
+		replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取ReplicaSet列表失败: %v", err)
+		}
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			if !metav1.IsControlledBy(rs, w.meta()) {
+				continue
+			}
+			revision, err := strconv.ParseInt(rs.Annotations[deploymentRevisionAnnotation], 10, 64)
+			if err != nil {
+				continue
+			}
+			template := *rs.Spec.Template.DeepCopy()
+			delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+			revisions = append(revisions, &rolloutRevision{
+				Revision:    revision,
+				Name:        rs.Name,
+				ChangeCause: rs.Annotations[changeCauseAnnotation],
+				Images:      templateImages(&template),
+				Created:     rs.CreationTimestamp.Time,
+				template:    template,
+			})
+		}
+	} else {
+		controllerRevisions, err := clientset.AppsV1().ControllerRevisions(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取ControllerRevision列表失败: %v", err)
+		}
+		for i := range controllerRevisions.Items {
+			cr := &controllerRevisions.Items[i]
+			if !metav1.IsControlledBy(cr, w.meta()) {
+				continue
+			}
+			// StatefulSet和DaemonSet控制器保存的是 {"spec":{"template":{...}}} 形式的补丁
+			var data struct {
+				Spec struct {
+					Template corev1.PodTemplateSpec `json:"template"`
+				} `json:"spec"`
+			}
+			if err := json.Unmarshal(cr.Data.Raw, &data); err != nil {
+				return nil, fmt.Errorf("解析ControllerRevision %s失败: %v", cr.Name, err)
+			}
+			revisions = append(revisions, &rolloutRevision{
+				Revision:    cr.Revision,
+				Name:        cr.Name,
+				ChangeCause: cr.Annotations[changeCauseAnnotation],
+				Images:      templateImages(&data.Spec.Template),
+				Created:     cr.CreationTimestamp.Time,
+				template:    data.Spec.Template,
+				data:        cr.Data.Raw,
+			})
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// findRevision 按修订版本号查找，revision为0时返回上一个版本
+func findRevision(revisions []*rolloutRevision, revision int64) (*rolloutRevision, error) {
+	if revision == 0 {
+		if len(revisions) < 2 {
+			return nil, fmt.Errorf("没有可回滚的上一个修订版本")
+		}
+		return revisions[len(revisions)-2], nil
+	}
+	for _, r := range revisions {
+		if r.Revision == revision {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("修订版本 %d 不存在", revision)
+}
+
+// templateImages 返回Pod模板中所有容器的镜像
+func templateImages(template *corev1.PodTemplateSpec) []string {
+	images := make([]string, 0, len(template.Spec.Containers))
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// 查看工作负载滚动更新历史的工具函数
+func RolloutHistoryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	revision, _ := request.Params.Arguments["revision"].(float64)
+
+	fmt.Println("ai 正在调用mcp server的tool: rollout_history, kind=", request.Params.Arguments["kind"], ", name=", request.Params.Arguments["name"], ", namespace=", request.Params.Arguments["namespace"], ", revision=", revision)
+
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+	w, err := getWorkload(ctx, clientset, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	revisions, err := rolloutHistory(ctx, clientset, w)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	if len(revisions) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("%s 没有任何修订版本", w)), nil
+	}
+
+	var result strings.Builder
+
+	// 指定了修订版本时输出该版本的Pod模板
+	if revision > 0 {
+		r, err := findRevision(revisions, int64(revision))
+		if err != nil {
+			return mcp.NewToolResultText(err.Error()), err
+		}
+		data, err := yaml.Marshal(r.template)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("格式化Pod模板失败: %v", err)), err
+		}
+		result.WriteString(fmt.Sprintf("%s 修订版本 %d（%s）:\n", w, r.Revision, r.Name))
+		result.WriteString(fmt.Sprintf("Change-Cause: %s\n", orNone(r.ChangeCause)))
+		result.WriteString(fmt.Sprintf("创建时间: %s\n", r.Created.Format(time.RFC3339)))
+		result.WriteString("Pod模板:\n")
+		result.Write(data)
+		return mcp.NewToolResultText(result.String()), nil
+	}
+
+	current := revisions[len(revisions)-1].Revision
+	result.WriteString(fmt.Sprintf("%s 的滚动更新历史:\n", w))
+	result.WriteString("REVISION\tNAME\tAGE\tIMAGES\tCHANGE-CAUSE\n")
+	for _, r := range revisions {
+		rev := strconv.FormatInt(r.Revision, 10)
+		if r.Revision == current {
+			rev += " (current)"
+		}
+		result.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n",
+			rev, r.Name, formatAge(r.Created), strings.Join(r.Images, ","), orNone(r.ChangeCause)))
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// 把工作负载回滚到指定修订版本的工具函数
+func RolloutUndoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	toRevision, _ := request.Params.Arguments["to_revision"].(float64)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: rollout_undo, kind=", request.Params.Arguments["kind"], ", name=", request.Params.Arguments["name"], ", namespace=", request.Params.Arguments["namespace"], ", to_revision=", toRevision, ", dry_run=", dryRun)
+
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+	w, err := getWorkload(ctx, clientset, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	if deployment, ok := w.obj.(*appsv1.Deployment); ok && deployment.Spec.Paused {
+		err := fmt.Errorf("%s 已暂停滚动更新，无法回滚，请先调用rollout_resume", w)
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	revisions, err := rolloutHistory(ctx, clientset, w)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	target, err := findRevision(revisions, int64(toRevision))
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%s: %v", w, err)), err
+	}
+
+	// 与kubectl rollout undo一致，目标版本的模板与当前模板相同时不做任何修改
+	current := w.template().DeepCopy()
+	delete(current.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if equality.Semantic.DeepEqual(current, &target.template) {
+		return mcp.NewToolResultText(fmt.Sprintf("%s 的Pod模板已经与修订版本 %d 相同，无需回滚", w, target.Revision)), nil
+	}
+
+	live := w.deepCopy()
+	var updated *workload
+	if w.kind == KindDeployment {
+		// Deployment回滚即用目标ReplicaSet的模板替换当前模板，控制器会复用该ReplicaSet
+		*w.template() = target.template
+		annotations := w.meta().GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if target.ChangeCause != "" {
+			annotations[changeCauseAnnotation] = target.ChangeCause
+		} else {
+			delete(annotations, changeCauseAnnotation)
+		}
+		w.meta().SetAnnotations(annotations)
+		updated, err = w.update(ctx, clientset, dryRun)
+	} else {
+		// ControllerRevision中保存的补丁带有 $patch: replace，提交后模板被整体替换
+		updated, err = w.patch(ctx, clientset, types.StrategicMergePatchType, target.data, dryRun)
+	}
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("回滚%s失败: %v", w, err)), err
+	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("回滚%s到修订版本 %d", w, target.Revision), live.obj, updated.obj)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%s 已回滚到修订版本 %d（镜像: %s），可以调用rollout_status等待完成",
+		w, target.Revision, strings.Join(target.Images, ","))), nil
+}
+
+// 暂停工作负载滚动更新的工具函数
+// Deployment设置spec.paused；StatefulSet把partition调到副本数；DaemonSet临时改为OnDelete策略
+func RolloutPauseTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return setRolloutPaused(ctx, request, true)
+}
+
+// 恢复工作负载滚动更新的工具函数
+func RolloutResumeTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return setRolloutPaused(ctx, request, false)
+}
+
+// setRolloutPaused 暂停或恢复滚动更新，StatefulSet和DaemonSet暂停前的设置保存在注解中，恢复时还原
+func setRolloutPaused(ctx context.Context, request mcp.CallToolRequest, paused bool) (*mcp.CallToolResult, error) {
+	toolName, action := "rollout_resume", "恢复"
+	if paused {
+		toolName, action = "rollout_pause", "暂停"
+	}
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: ", toolName, ", kind=", request.Params.Arguments["kind"], ", name=", request.Params.Arguments["name"], ", namespace=", request.Params.Arguments["namespace"], ", dry_run=", dryRun)
+
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+	w, err := getWorkload(ctx, clientset, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	live := w.deepCopy()
+	changed, err := setWorkloadPaused(w, paused)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%s%s的滚动更新失败: %v", action, w, err)), err
+	}
+	if !changed {
+		state := "未暂停"
+		if paused {
+			state = "已经暂停"
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("%s 的滚动更新%s，无需%s", w, state, action)), nil
+	}
+
+	updated, err := w.update(ctx, clientset, dryRun)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%s%s的滚动更新失败: %v", action, w, err)), err
+	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("%s%s的滚动更新", action, w), live.obj, updated.obj)
+	}
+
+	if paused {
+		return mcp.NewToolResultText(fmt.Sprintf("%s 的滚动更新已暂停，之后对Pod模板的修改不会更新现有Pod，调用rollout_resume恢复", w)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s 的滚动更新已恢复，可以调用rollout_status等待完成", w)), nil
+}
+
+// setWorkloadPaused 在对象上设置暂停状态，返回对象是否被修改
+func setWorkloadPaused(w *workload, paused bool) (bool, error) {
+	annotations := w.meta().GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	defer w.meta().SetAnnotations(annotations)
+
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		if obj.Spec.Paused == paused {
+			return false, nil
+		}
+		obj.Spec.Paused = paused
+		return true, nil
+
+	case *appsv1.StatefulSet:
+		saved, isPaused := annotations[pausedPartitionAnnotation]
+		if isPaused == paused {
+			return false, nil
+		}
+		if obj.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			return false, fmt.Errorf("更新策略为OnDelete，Pod只会在手动删除后更新，不需要暂停或恢复")
+		}
+		if obj.Spec.UpdateStrategy.RollingUpdate == nil {
+			obj.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{}
+		}
+		if paused {
+			// partition不小于副本数时，所有Pod都保持在旧版本
+			var partition int32
+			if obj.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+				partition = *obj.Spec.UpdateStrategy.RollingUpdate.Partition
+			}
+			replicas := int32(1)
+			if obj.Spec.Replicas != nil {
+				replicas = *obj.Spec.Replicas
+			}
+			annotations[pausedPartitionAnnotation] = strconv.Itoa(int(partition))
+			obj.Spec.UpdateStrategy.RollingUpdate.Partition = &replicas
+			return true, nil
+		}
+		partition, err := strconv.ParseInt(saved, 10, 32)
+		if err != nil {
+			return false, fmt.Errorf("注解 %s 的值 %q 无效: %v", pausedPartitionAnnotation, saved, err)
+		}
+		restored := int32(partition)
+		obj.Spec.UpdateStrategy.RollingUpdate.Partition = &restored
+		delete(annotations, pausedPartitionAnnotation)
+		return true, nil
+
+	case *appsv1.DaemonSet:
+		saved, isPaused := annotations[pausedStrategyAnnotation]
+		if isPaused == paused {
+			return false, nil
+		}
+		if paused {
+			if obj.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+				return false, fmt.Errorf("更新策略为OnDelete，Pod只会在手动删除后更新，不需要暂停")
+			}
+			data, err := json.Marshal(obj.Spec.UpdateStrategy)
+			if err != nil {
+				return false, err
+			}
+			annotations[pausedStrategyAnnotation] = string(data)
+			obj.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+			return true, nil
+		}
+		var strategy appsv1.DaemonSetUpdateStrategy
+		if err := json.Unmarshal([]byte(saved), &strategy); err != nil {
+			return false, fmt.Errorf("注解 %s 的值无效: %v", pausedStrategyAnnotation, err)
+		}
+		obj.Spec.UpdateStrategy = strategy
+		delete(annotations, pausedStrategyAnnotation)
+		return true, nil
+	}
+	return false, nil
+}
+
+// orNone 空字符串显示为<none>
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// rollout_status 的默认和最大等待时长，以及未完成时输出的最多事件数
+const (
+	defaultRolloutTimeout = 300 * time.Second
+	maxRolloutTimeout     = 1800 * time.Second
+	maxRolloutEvents      = 20
+)
+
+// rolloutProgress 某一时刻的滚动更新进度
+type rolloutProgress struct {
+	desired   int32
+	updated   int32
+	ready     int32
+	available int32
+	message   string
+	done      bool
+	err       error // 滚动更新已失败或无法继续，继续等待没有意义
+}
+
+// String 返回一行进度描述
+func (p rolloutProgress) String() string {
+	message := p.message
+	if p.err != nil {
+		message = p.err.Error()
+	}
+	return fmt.Sprintf("期望 %d / 已更新 %d / 就绪 %d / 可用 %d - %s", p.desired, p.updated, p.ready, p.available, message)
+}
+
+// workloadRolloutProgress 按kubectl rollout status的规则判断滚动更新进度
+func workloadRolloutProgress(w *workload) rolloutProgress {
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		p := rolloutProgress{
+			desired:   replicasOrDefault(obj.Spec.Replicas),
+			updated:   obj.Status.UpdatedReplicas,
+			ready:     obj.Status.ReadyReplicas,
+			available: obj.Status.AvailableReplicas,
+		}
+		if obj.Generation > obj.Status.ObservedGeneration {
+			p.message = "等待控制器处理最新的变更"
+			return p
+		}
+		for _, condition := range obj.Status.Conditions {
+			if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+				p.err = fmt.Errorf("滚动更新超过progressDeadlineSeconds仍未完成: %s", condition.Message)
+				return p
+			}
+		}
+		switch {
+		case p.updated < p.desired:
+			p.message = fmt.Sprintf("等待滚动更新完成: %d/%d 个新副本已更新", p.updated, p.desired)
+		case obj.Status.Replicas > p.updated:
+			p.message = fmt.Sprintf("等待滚动更新完成: %d 个旧副本等待终止", obj.Status.Replicas-p.updated)
+		case p.available < p.updated:
+			p.message = fmt.Sprintf("等待滚动更新完成: %d/%d 个已更新副本可用", p.available, p.updated)
+		default:
+			p.message, p.done = "滚动更新已完成", true
+			return p
+		}
+		if obj.Spec.Paused {
+			p.err = fmt.Errorf("滚动更新已暂停，调用rollout_resume后才会继续")
+		}
+		return p
+
+	case *appsv1.StatefulSet:
+		p := rolloutProgress{
+			desired:   replicasOrDefault(obj.Spec.Replicas),
+			updated:   obj.Status.UpdatedReplicas,
+			ready:     obj.Status.ReadyReplicas,
+			available: obj.Status.AvailableReplicas,
+		}
+		if obj.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			p.err = fmt.Errorf("更新策略为OnDelete，Pod只会在手动删除后更新，无法等待滚动更新")
+			return p
+		}
+		if obj.Status.ObservedGeneration == 0 || obj.Generation > obj.Status.ObservedGeneration {
+			p.message = "等待控制器处理最新的变更"
+			return p
+		}
+		if _, paused := obj.Annotations[pausedPartitionAnnotation]; paused && obj.Status.UpdateRevision != obj.Status.CurrentRevision {
+			p.err = fmt.Errorf("滚动更新已暂停，调用rollout_resume后才会继续")
+			return p
+		}
+		if p.ready < p.desired {
+			p.message = fmt.Sprintf("等待Pod就绪: %d/%d 个Pod已就绪", p.ready, p.desired)
+			return p
+		}
+		if rollingUpdate := obj.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+			// 分区更新只会更新序号不小于partition的Pod
+			if target := p.desired - *rollingUpdate.Partition; p.updated < target {
+				p.message = fmt.Sprintf("等待分区滚动更新完成: %d/%d 个Pod已更新", p.updated, target)
+				return p
+			}
+			p.message, p.done = fmt.Sprintf("分区滚动更新已完成: %d 个Pod已更新（partition=%d）", p.updated, *rollingUpdate.Partition), true
+			return p
+		}
+		if obj.Status.UpdateRevision != obj.Status.CurrentRevision {
+			p.message = fmt.Sprintf("等待滚动更新完成: %d/%d 个Pod已更新到版本 %s", p.updated, p.desired, obj.Status.UpdateRevision)
+			return p
+		}
+		p.message, p.done = fmt.Sprintf("滚动更新已完成，当前版本 %s", obj.Status.CurrentRevision), true
+		return p
+
+	default:
+		ds := w.obj.(*appsv1.DaemonSet)
+		p := rolloutProgress{
+			desired:   ds.Status.DesiredNumberScheduled,
+			updated:   ds.Status.UpdatedNumberScheduled,
+			ready:     ds.Status.NumberReady,
+			available: ds.Status.NumberAvailable,
+		}
+		if ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			if _, paused := ds.Annotations[pausedStrategyAnnotation]; paused {
+				p.err = fmt.Errorf("滚动更新已暂停，调用rollout_resume后才会继续")
+			} else {
+				p.err = fmt.Errorf("更新策略为OnDelete，Pod只会在手动删除后更新，无法等待滚动更新")
+			}
+			return p
+		}
+		if ds.Generation > ds.Status.ObservedGeneration {
+			p.message = "等待控制器处理最新的变更"
+			return p
+		}
+		switch {
+		case p.updated < p.desired:
+			p.message = fmt.Sprintf("等待滚动更新完成: %d/%d 个节点上的Pod已更新", p.updated, p.desired)
+		case p.available < p.desired:
+			p.message = fmt.Sprintf("等待滚动更新完成: %d/%d 个已更新的Pod可用", p.available, p.desired)
+		default:
+			p.message, p.done = "滚动更新已完成", true
+		}
+		return p
+	}
+}
+
+// replicasOrDefault 返回副本数，未设置时为默认值1
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// waitForRollout 监听工作负载直到滚动更新完成、失败或超时，每次进度变化写入一行
+// 返回最后一次看到的对象和进度，超时时timedOut为true
+func waitForRollout(ctx context.Context, clientset *kubernetes.Clientset, w *workload, result *strings.Builder) (*workload, rolloutProgress, bool, error) {
+	start := time.Now()
+	var (
+		watcher watch.Interface
+		last    string
+		err     error
+	)
+	defer func() {
+		if watcher != nil {
+			watcher.Stop()
+		}
+	}()
+
+	for {
+		progress := workloadRolloutProgress(w)
+		if line := progress.String(); line != last {
+			result.WriteString(fmt.Sprintf("[%s] %s\n", time.Since(start).Round(time.Second), line))
+			last = line
+		}
+		if progress.done || progress.err != nil {
+			return w, progress, false, nil
+		}
+
+		if watcher == nil {
+			watcher, err = w.watch(ctx, clientset)
+			if err != nil {
+				if ctx.Err() != nil {
+					return w, progress, true, nil
+				}
+				return w, progress, false, fmt.Errorf("监听%s失败: %v", w, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return w, progress, true, nil
+		case event, ok := <-watcher.ResultChan():
+			if ok && (event.Type == watch.Added || event.Type == watch.Modified) {
+				w = &workload{kind: w.kind, obj: event.Object}
+				continue
+			}
+			if ok && event.Type == watch.Deleted {
+				progress.err = fmt.Errorf("%s 在等待期间被删除", w)
+				result.WriteString(fmt.Sprintf("[%s] %s\n", time.Since(start).Round(time.Second), progress.err))
+				return w, progress, false, nil
+			}
+			// 监听被服务端关闭或resourceVersion过期时，重新获取对象后再次监听
+			watcher.Stop()
+			watcher = nil
+			refreshed, err := w.refresh(ctx, clientset)
+			if err != nil {
+				if ctx.Err() != nil {
+					return w, progress, true, nil
+				}
+				return w, progress, false, err
+			}
+			w = refreshed
+		}
+	}
+}
+
+// 等待工作负载滚动更新完成的工具函数
+func RolloutStatusTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	timeout := defaultRolloutTimeout
+	if seconds, ok := request.Params.Arguments["timeout_seconds"].(float64); ok && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if timeout > maxRolloutTimeout {
+		timeout = maxRolloutTimeout
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: rollout_status, kind=", request.Params.Arguments["kind"], ", name=", request.Params.Arguments["name"], ", namespace=", request.Params.Arguments["namespace"], ", timeout=", timeout)
+
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+	w, err := getWorkload(ctx, clientset, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("等待 %s 的滚动更新（超时 %s）:\n", w, timeout))
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	w, progress, timedOut, err := waitForRollout(waitCtx, clientset, w, &result)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	switch {
+	case progress.done:
+		result.WriteString(fmt.Sprintf("\n结果: %s %s\n", w, progress.message))
+		return mcp.NewToolResultText(result.String()), nil
+	case timedOut:
+		result.WriteString(fmt.Sprintf("\n结果: 等待超时，%s 的滚动更新在 %s 内没有完成\n", w, timeout))
+	default:
+		result.WriteString(fmt.Sprintf("\n结果: %s 的滚动更新失败: %v\n", w, progress.err))
+	}
+
+	// 没有完成时给出未就绪的Pod和相关事件，说明卡住的原因
+	writeRolloutStall(ctx, clientset, w, &result)
+	return mcp.NewToolResultError(result.String()), nil
+}
+
+// writeRolloutStall 输出未就绪的Pod以及工作负载、ReplicaSet和Pod的相关事件
+func writeRolloutStall(ctx context.Context, clientset *kubernetes.Clientset, w *workload, result *strings.Builder) {
+	namespace := w.meta().GetNamespace()
+	selector, err := metav1.LabelSelectorAsSelector(w.selector())
+	if err != nil {
+		return
+	}
+
+	// 事件的involvedObject，key为 kind/name
+	objects := map[string]bool{w.kind + "/" + w.meta().GetName(): true}
+	if w.kind == KindDeployment {
+		if revisions, err := rolloutHistory(ctx, clientset, w); err == nil {
+			for _, r := range revisions {
+				objects["ReplicaSet/"+r.Name] = true
+			}
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err == nil {
+		var notReady []string
+		for _, pod := range pods.Items {
+			objects["Pod/"+pod.Name] = true
+			if podReady(&pod) {
+				continue
+			}
+			line := fmt.Sprintf("  %s: %s (创建于 %s前)", pod.Name, pod.Status.Phase, formatAge(pod.CreationTimestamp.Time))
+			for _, cs := range pod.Status.ContainerStatuses {
+				if waiting := cs.State.Waiting; waiting != nil {
+					line += fmt.Sprintf("\n    容器 %s: Waiting (%s) %s", cs.Name, waiting.Reason, waiting.Message)
+				} else if terminated := cs.State.Terminated; terminated != nil {
+					line += fmt.Sprintf("\n    容器 %s: Terminated (退出码: %d, 原因: %s)", cs.Name, terminated.ExitCode, terminated.Reason)
+				} else if !cs.Ready {
+					line += fmt.Sprintf("\n    容器 %s: 运行中但未就绪（重启 %d 次）", cs.Name, cs.RestartCount)
+				}
+			}
+			notReady = append(notReady, line)
+		}
+		if len(notReady) > 0 {
+			result.WriteString(fmt.Sprintf("\n未就绪的Pod (%d/%d):\n", len(notReady), len(pods.Items)))
+			result.WriteString(strings.Join(notReady, "\n"))
+			result.WriteString("\n")
+		}
+	}
+
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+	var related []corev1.Event
+	for _, event := range events.Items {
+		if !objects[event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name] {
+			continue
+		}
+		// Pod的正常事件（拉取镜像、启动容器等）数量多且无助于定位问题，只保留告警
+		if event.InvolvedObject.Kind == "Pod" && event.Type != corev1.EventTypeWarning {
+			continue
+		}
+		related = append(related, event)
+	}
+	if len(related) == 0 {
+		return
+	}
+	sort.Slice(related, func(i, j int) bool {
+		return eventTime(&related[i]).After(eventTime(&related[j]))
+	})
+	if len(related) > maxRolloutEvents {
+		related = related[:maxRolloutEvents]
+	}
+	result.WriteString("\n最近事件:\n")
+	for i := range related {
+		event := &related[i]
+		result.WriteString(fmt.Sprintf("  %s [%s] %s/%s %s: %s\n",
+			formatAge(eventTime(event)),
+			event.Type,
+			event.InvolvedObject.Kind,
+			event.InvolvedObject.Name,
+			event.Reason,
+			event.Message))
+	}
+}
+
+// podReady 判断Pod的Ready条件
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// eventTime 返回事件最后一次发生的时间，新版事件只设置eventTime
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -13,43 +13,68 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// secretSummary Secret列表中的一行，只包含键的数量，不包含数据
+type secretSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	Data      int    `json:"data"`
+	Age       string `json:"age"`
+}
+
 // ListSecretsTool 列出Secret的工具函数
 func ListSecretsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_secrets, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_secrets,", scope)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Secret列表
-	secrets, err := clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	secrets, err := clientset.CoreV1().Secrets(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Secret列表失败: %v", err)), err
 	}
 
+	items := make([]secretSummary, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		items = append(items, secretSummary{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+			Type:      string(secret.Type),
+			Data:      len(secret.Data),
+			Age:       formatAge(secret.CreationTimestamp.Time),
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("Secret", items, secrets))
+	}
+
 	// 格式化输出
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tTYPE\tDATA\tAGE\n")
-
-	for _, secret := range secrets.Items {
-		// 计算运行时间
-		age := formatAge(secret.CreationTimestamp.Time)
+	result.WriteString(scope.header("NAME\tTYPE\tDATA\tAGE"))
 
-		result.WriteString(fmt.Sprintf("%s\t%s\t%d\t%s\n",
-			secret.Name,
-			secret.Type,
-			len(secret.Data),
-			age))
+	for _, item := range items {
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%d\t%s\n",
+			item.Name,
+			item.Type,
+			item.Data,
+			item.Age))
 	}
 
+	result.WriteString(scope.footer(secrets))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -64,12 +89,17 @@ func DescribeSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 	// 是否显示敏感数据
 	showData, _ := request.Params.Arguments["show_data"].(bool)
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_secret, secret_name=", secretName, ", namespace=", namespace)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Secret详情
@@ -78,6 +108,10 @@ func DescribeSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultText(fmt.Sprintf("获取Secret详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		return describeSecretResult(ctx, clientset, format, secret, showData)
+	}
+
 	// 格式化输出
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:         %s\n", secret.Name))
@@ -196,12 +230,14 @@ func CreateSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	fromFile, _ := request.Params.Arguments["from_file"].(string)
 	fromLiteral, _ := request.Params.Arguments["from_literal"].(string)
 
-	fmt.Println("ai 正在调用mcp server的tool: create_secret, secret_name=", secretName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: create_secret, secret_name=", secretName, ", namespace=", namespace, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 创建Secret对象
@@ -247,10 +283,13 @@ func CreateSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	}
 
 	// 创建Secret
-	createdSecret, err := clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	createdSecret, err := clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("创建Secret失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("创建Secret %s/%s", namespace, secretName), nil, createdSecret)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Secret %s 在命名空间 %s 中创建成功", createdSecret.Name, createdSecret.Namespace)), nil
 }
@@ -311,12 +350,14 @@ func UpdateSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	key, keyProvided := request.Params.Arguments["key"].(string)
 	value, valueProvided := request.Params.Arguments["value"].(string)
 
-	fmt.Println("ai 正在调用mcp server的tool: update_secret, secret_name=", secretName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: update_secret, secret_name=", secretName, ", namespace=", namespace, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取现有Secret
@@ -324,6 +365,7 @@ func UpdateSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Secret %s 失败: %v", secretName, err)), err
 	}
+	live := secret.DeepCopy()
 
 	// 更新标签
 	if labelsProvided {
@@ -354,10 +396,13 @@ func UpdateSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	}
 
 	// 更新Secret
-	updatedSecret, err := clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	updatedSecret, err := clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("更新Secret失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("更新Secret %s/%s", namespace, secretName), live, updatedSecret)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Secret %s 在命名空间 %s 中更新成功", updatedSecret.Name, updatedSecret.Namespace)), nil
 }
@@ -370,19 +415,33 @@ func DeleteSecretTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		namespace = "default"
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: delete_secret, secret_name=", secretName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: delete_secret, secret_name=", secretName, ", namespace=", namespace, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	// 演练模式下先获取现有对象用于对比
+	var live *corev1.Secret
+	if dryRun {
+		live, err = clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取Secret %s 失败: %v", secretName, err)), err
+		}
 	}
 
 	// 删除Secret
-	err = clientset.CoreV1().Secrets(namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+	err = clientset.CoreV1().Secrets(namespace).Delete(ctx, secretName, metav1.DeleteOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("删除Secret失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("删除Secret %s/%s", namespace, secretName), live, nil)
+	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Secret %s 在命名空间 %s 中已删除", secretName, namespace)), nil
 }
@@ -396,6 +455,22 @@ func getEventsForSecret(ctx context.Context, clientset *kubernetes.Clientset, se
 	})
 }
 
+// describeSecretResult 生成Secret详情的结构化结果，未要求显示数据时只给出每个键的字节数
+func describeSecretResult(ctx context.Context, clientset *kubernetes.Clientset, format string, secret *corev1.Secret, showData bool) (*mcp.CallToolResult, error) {
+	content, err := describeContent(secret)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("转换对象失败: %v", err)), err
+	}
+	if data, ok := content["data"].(map[string]interface{}); ok && !showData {
+		for key := range data {
+			data[key] = fmt.Sprintf("%d bytes", len(secret.Data[key]))
+		}
+	}
+
+	events, _ := getEventsForSecret(ctx, clientset, secret)
+	return structuredResult(format, describeOutput{Object: content, Events: summarizeEvents(events)})
+}
+
 // 辅助函数：检查字符串是否可打印
 func isPrintable(s string) bool {
 	for _, r := range s {
@@ -13,38 +13,47 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// serviceSummary Service列表中的一行
+type serviceSummary struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Type        string            `json:"type"`
+	ClusterIP   string            `json:"clusterIP"`
+	ExternalIPs []string          `json:"externalIPs"`
+	Ports       []string          `json:"ports"`
+	Age         string            `json:"age"`
+	Selector    map[string]string `json:"selector"`
+}
+
 // 列出Service的工具函数
 func ListServicesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_services, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_services,", scope)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Service列表
-	services, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	services, err := clientset.CoreV1().Services(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取Service列表失败: %v", err)), err
 	}
 
-	// 格式化输出
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tTYPE\tCLUSTER-IP\tEXTERNAL-IP\tPORT(S)\tAGE\tSELECTOR\n")
-
+	items := make([]serviceSummary, 0, len(services.Items))
 	for _, service := range services.Items {
-		// 计算运行时间
-		age := formatAge(service.CreationTimestamp.Time)
-
 		// 格式化端口
-		var ports []string
+		ports := []string{}
 		for _, port := range service.Spec.Ports {
 			if port.NodePort > 0 {
 				ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
@@ -52,48 +61,53 @@ func ListServicesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 				ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
 			}
 		}
-		portStr := strings.Join(ports, ", ")
 
-		// 格式化外部IP
-		externalIPs := "<none>"
+		// 外部IP
+		externalIPs := []string{}
 		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
-			if len(service.Status.LoadBalancer.Ingress) > 0 {
-				var ips []string
-				for _, ingress := range service.Status.LoadBalancer.Ingress {
-					if ingress.IP != "" {
-						ips = append(ips, ingress.IP)
-					} else if ingress.Hostname != "" {
-						ips = append(ips, ingress.Hostname)
-					}
-				}
-				if len(ips) > 0 {
-					externalIPs = strings.Join(ips, ", ")
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				if ingress.IP != "" {
+					externalIPs = append(externalIPs, ingress.IP)
+				} else if ingress.Hostname != "" {
+					externalIPs = append(externalIPs, ingress.Hostname)
 				}
 			}
-		} else if len(service.Spec.ExternalIPs) > 0 {
-			externalIPs = strings.Join(service.Spec.ExternalIPs, ", ")
+		} else {
+			externalIPs = append(externalIPs, service.Spec.ExternalIPs...)
 		}
 
-		// 格式化选择器
-		selectorStr := "<none>"
-		if len(service.Spec.Selector) > 0 {
-			var selectors []string
-			for k, v := range service.Spec.Selector {
-				selectors = append(selectors, fmt.Sprintf("%s=%s", k, v))
-			}
-			selectorStr = strings.Join(selectors, ", ")
-		}
+		items = append(items, serviceSummary{
+			Name:        service.Name,
+			Namespace:   service.Namespace,
+			Type:        string(service.Spec.Type),
+			ClusterIP:   service.Spec.ClusterIP,
+			ExternalIPs: externalIPs,
+			Ports:       ports,
+			Age:         formatAge(service.CreationTimestamp.Time),
+			Selector:    service.Spec.Selector,
+		})
+	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("Service", items, services))
+	}
 
-		result.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			service.Name,
-			service.Spec.Type,
-			service.Spec.ClusterIP,
-			externalIPs,
-			portStr,
-			age,
-			selectorStr))
+	// 格式化输出
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tTYPE\tCLUSTER-IP\tEXTERNAL-IP\tPORT(S)\tAGE\tSELECTOR"))
+
+	for _, item := range items {
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.Name,
+			item.Type,
+			item.ClusterIP,
+			joinOrNone(item.ExternalIPs, ", "),
+			strings.Join(item.Ports, ", "),
+			item.Age,
+			formatLabels(item.Selector)))
 	}
 
+	result.WriteString(scope.footer(services))
+
 	return mcp.NewToolResultText(result.String()), nil
 }
 
@@ -105,12 +119,17 @@ func DescribeServiceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		namespace = "default"
 	}
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_service, service_name=", serviceName, ", namespace=", namespace)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Service详情
@@ -119,6 +138,11 @@ func DescribeServiceTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultText(fmt.Sprintf("获取Service详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		events, _ := getEventsForService(ctx, clientset, service)
+		return describeResult(format, service, events)
+	}
+
 	// 格式化输出
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:              %s\n", service.Name))
@@ -260,12 +284,14 @@ func ModifyServiceTypeTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		serviceType = "ClusterIP"
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: modify_service_type, service_name=", serviceName, ", namespace=", namespace, ", serviceType=", serviceType)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: modify_service_type, service_name=", serviceName, ", namespace=", namespace, ", serviceType=", serviceType, ", dry_run=", dryRun)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 限制只能ClusterIP & NodePort
@@ -287,10 +313,22 @@ func ModifyServiceTypeTool(ctx context.Context, request mcp.CallToolRequest) (*m
 
 	patchBytes, _ := json.Marshal(patch)
 
-	_, err = clientset.CoreV1().Services(namespace).Patch(ctx, serviceName, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	// 演练模式下先获取现有对象用于对比
+	var live *corev1.Service
+	if dryRun {
+		live, err = clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取Service失败: %v", err)), err
+		}
+	}
+
+	patched, err := clientset.CoreV1().Services(namespace).Patch(ctx, serviceName, types.MergePatchType, patchBytes, metav1.PatchOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("调整Service Type失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("将Service %s/%s 的类型调整为 %s", namespace, serviceName, serviceType), live, patched)
+	}
 
 	// 重新获取 Service获取最新数据
 	service, err := clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
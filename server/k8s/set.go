@@ -0,0 +1,336 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WithChangeCauseArgument 为修改Pod模板的工具追加change_cause参数
+func WithChangeCauseArgument() mcp.ToolOption {
+	return mcp.WithString("change_cause",
+		mcp.Description("记录到kubernetes.io/change-cause注解中的变更原因，会显示在rollout_history中，不提供则根据本次修改自动生成"),
+	)
+}
+
+// containerRef Pod模板中的一个容器
+type containerRef struct {
+	container *corev1.Container
+	init      bool
+}
+
+// selectContainers 按名称选择容器，名称为空或*时选择全部普通容器
+func selectContainers(template *corev1.PodTemplateSpec, name string) ([]containerRef, error) {
+	var refs []containerRef
+	if name == "" || name == "*" {
+		for i := range template.Spec.Containers {
+			refs = append(refs, containerRef{container: &template.Spec.Containers[i]})
+		}
+		return refs, nil
+	}
+
+	var names []string
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == name {
+			return []containerRef{{container: &template.Spec.Containers[i]}}, nil
+		}
+		names = append(names, template.Spec.Containers[i].Name)
+	}
+	for i := range template.Spec.InitContainers {
+		if template.Spec.InitContainers[i].Name == name {
+			return []containerRef{{container: &template.Spec.InitContainers[i], init: true}}, nil
+		}
+		names = append(names, template.Spec.InitContainers[i].Name)
+	}
+	return nil, fmt.Errorf("容器 %s 不存在，可选的容器: %s", name, strings.Join(names, ", "))
+}
+
+// containerPatch 按容器名合并的策略合并补丁
+type containerPatch struct {
+	containers     []map[string]interface{}
+	initContainers []map[string]interface{}
+}
+
+// add 为容器追加要修改的字段
+func (p *containerPatch) add(ref containerRef, fields map[string]interface{}) {
+	fields["name"] = ref.container.Name
+	if ref.init {
+		p.initContainers = append(p.initContainers, fields)
+	} else {
+		p.containers = append(p.containers, fields)
+	}
+}
+
+// data 生成补丁，同时写入变更原因注解
+func (p *containerPatch) data(changeCause string) ([]byte, error) {
+	spec := map[string]interface{}{}
+	if len(p.containers) > 0 {
+		spec["containers"] = p.containers
+	}
+	if len(p.initContainers) > 0 {
+		spec["initContainers"] = p.initContainers
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{changeCauseAnnotation: changeCause},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": spec,
+			},
+		},
+	})
+}
+
+// patchWorkloadContainers 获取工作负载，由build根据当前Pod模板构造补丁和修改摘要，再以策略合并补丁提交
+// 摘要会写入变更原因，不能包含环境变量值等敏感内容
+func patchWorkloadContainers(ctx context.Context, request mcp.CallToolRequest, toolName string,
+	build func(template *corev1.PodTemplateSpec) (*containerPatch, string, error)) (*mcp.CallToolResult, error) {
+	dryRun := isDryRun(request)
+
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+	w, err := getWorkload(ctx, clientset, request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	patch, summary, err := build(w.template())
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%s: %v", w, err)), err
+	}
+
+	changeCause, _ := request.Params.Arguments["change_cause"].(string)
+	if changeCause == "" {
+		changeCause = fmt.Sprintf("%s %s (by %s)", toolName, summary, ApplyFieldManager)
+	}
+	data, err := patch.data(changeCause)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("生成补丁失败: %v", err)), err
+	}
+
+	updated, err := w.patch(ctx, clientset, types.StrategicMergePatchType, data, dryRun)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("更新%s失败: %v", w, err)), err
+	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("更新%s（%s）", w, summary), w.obj, updated.obj)
+	}
+
+	if updated.meta().GetGeneration() == w.meta().GetGeneration() {
+		return mcp.NewToolResultText(fmt.Sprintf("%s 的Pod模板没有变化（%s），不会触发滚动更新", w, summary)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s 已更新: %s，已触发滚动更新，可以调用rollout_status等待完成", w, summary)), nil
+}
+
+// stringArgs 读取键值对参数，数字和布尔值转换为字符串，null转换为空字符串
+func stringArgs(request mcp.CallToolRequest, key string) map[string]string {
+	values := map[string]string{}
+	args, _ := request.Params.Arguments[key].(map[string]interface{})
+	for k, v := range args {
+		switch value := v.(type) {
+		case string:
+			values[k] = value
+		case nil:
+			values[k] = ""
+		default:
+			values[k] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+// sortedKeys 返回按字母排序的键
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 更新工作负载容器镜像的工具函数，等同于kubectl set image
+func SetImageTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	images := stringArgs(request, "images")
+
+	fmt.Println("ai 正在调用mcp server的tool: set_image, kind=", request.Params.Arguments["kind"], ", name=", request.Params.Arguments["name"], ", namespace=", request.Params.Arguments["namespace"], ", images=", images, ", dry_run=", isDryRun(request))
+
+	if len(images) == 0 {
+		err := fmt.Errorf("必须提供images参数，格式为 {容器名: 镜像}，容器名为*时更新全部容器")
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	return patchWorkloadContainers(ctx, request, "set_image", func(template *corev1.PodTemplateSpec) (*containerPatch, string, error) {
+		patch := &containerPatch{}
+		var changes []string
+		for _, name := range sortedKeys(images) {
+			image := strings.TrimSpace(images[name])
+			if image == "" {
+				return nil, "", fmt.Errorf("容器 %s 的镜像不能为空", name)
+			}
+			refs, err := selectContainers(template, name)
+			if err != nil {
+				return nil, "", err
+			}
+			for _, ref := range refs {
+				patch.add(ref, map[string]interface{}{"image": image})
+				changes = append(changes, fmt.Sprintf("%s=%s", ref.container.Name, image))
+			}
+		}
+		return patch, strings.Join(changes, ", "), nil
+	})
+}
+
+// 更新工作负载容器资源请求和限制的工具函数，等同于kubectl set resources
+func SetResourcesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	container, _ := request.Params.Arguments["container"].(string)
+	requests := stringArgs(request, "requests")
+	limits := stringArgs(request, "limits")
+
+	fmt.Println("ai 正在调用mcp server的tool: set_resources, kind=", request.Params.Arguments["kind"], ", name=", request.Params.Arguments["name"], ", namespace=", request.Params.Arguments["namespace"], ", container=", container, ", requests=", requests, ", limits=", limits, ", dry_run=", isDryRun(request))
+
+	if len(requests) == 0 && len(limits) == 0 {
+		err := fmt.Errorf("必须提供requests或limits参数，例如 {\"cpu\": \"500m\", \"memory\": \"256Mi\"}")
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	requestsPatch, requestsSummary, err := resourceListPatch(requests)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("requests参数无效: %v", err)), err
+	}
+	limitsPatch, limitsSummary, err := resourceListPatch(limits)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("limits参数无效: %v", err)), err
+	}
+
+	return patchWorkloadContainers(ctx, request, "set_resources", func(template *corev1.PodTemplateSpec) (*containerPatch, string, error) {
+		refs, err := selectContainers(template, container)
+		if err != nil {
+			return nil, "", err
+		}
+
+		resources := map[string]interface{}{}
+		var summary []string
+		if len(requestsPatch) > 0 {
+			resources["requests"] = requestsPatch
+			summary = append(summary, "requests "+requestsSummary)
+		}
+		if len(limitsPatch) > 0 {
+			resources["limits"] = limitsPatch
+			summary = append(summary, "limits "+limitsSummary)
+		}
+
+		patch := &containerPatch{}
+		var names []string
+		for _, ref := range refs {
+			patch.add(ref, map[string]interface{}{"resources": resources})
+			names = append(names, ref.container.Name)
+		}
+		return patch, fmt.Sprintf("容器 %s: %s", strings.Join(names, ","), strings.Join(summary, "; ")), nil
+	})
+}
+
+// resourceListPatch 校验资源数量并生成补丁，空值表示删除该资源的设置
+func resourceListPatch(values map[string]string) (map[string]interface{}, string, error) {
+	patch := map[string]interface{}{}
+	var summary []string
+	for _, name := range sortedKeys(values) {
+		value := strings.TrimSpace(values[name])
+		if value == "" {
+			patch[name] = nil
+			summary = append(summary, name+"=<removed>")
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s 的值 %q 不是有效的资源数量: %v", name, value, err)
+		}
+		patch[name] = quantity.String()
+		summary = append(summary, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	return patch, strings.Join(summary, ","), nil
+}
+
+// 设置或删除工作负载容器环境变量的工具函数，等同于kubectl set env
+func SetEnvTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	container, _ := request.Params.Arguments["container"].(string)
+	env := stringArgs(request, "env")
+	removeArg, _ := request.Params.Arguments["remove"].(string)
+	var remove []string
+	for _, name := range strings.Split(removeArg, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			remove = append(remove, name)
+		}
+	}
+
+	// 环境变量的值可能包含凭据，日志和变更原因中只记录变量名
+	fmt.Println("ai 正在调用mcp server的tool: set_env, kind=", request.Params.Arguments["kind"], ", name=", request.Params.Arguments["name"], ", namespace=", request.Params.Arguments["namespace"], ", container=", container, ", env=", sortedKeys(env), ", remove=", remove, ", dry_run=", isDryRun(request))
+
+	if len(env) == 0 && len(remove) == 0 {
+		err := fmt.Errorf("必须提供env或remove参数")
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	for _, name := range remove {
+		if _, ok := env[name]; ok {
+			err := fmt.Errorf("环境变量 %s 不能同时设置和删除", name)
+			return mcp.NewToolResultText(err.Error()), err
+		}
+	}
+
+	return patchWorkloadContainers(ctx, request, "set_env", func(template *corev1.PodTemplateSpec) (*containerPatch, string, error) {
+		refs, err := selectContainers(template, container)
+		if err != nil {
+			return nil, "", err
+		}
+
+		patch := &containerPatch{}
+		var names []string
+		for _, ref := range refs {
+			existing := map[string]corev1.EnvVar{}
+			for _, envVar := range ref.container.Env {
+				existing[envVar.Name] = envVar
+			}
+
+			var items []map[string]interface{}
+			for _, name := range sortedKeys(env) {
+				item := map[string]interface{}{"name": name, "value": env[name]}
+				// 原来引用ConfigMap或Secret的变量改为直接赋值时，需要同时去掉valueFrom
+				if old, ok := existing[name]; ok && old.ValueFrom != nil {
+					item["valueFrom"] = nil
+				}
+				items = append(items, item)
+			}
+			for _, name := range remove {
+				if _, ok := existing[name]; ok {
+					items = append(items, map[string]interface{}{"name": name, "$patch": "delete"})
+				}
+			}
+			if len(items) == 0 {
+				continue
+			}
+			patch.add(ref, map[string]interface{}{"env": items})
+			names = append(names, ref.container.Name)
+		}
+		if len(names) == 0 {
+			return nil, "", fmt.Errorf("要删除的环境变量 %s 在容器中都不存在", strings.Join(remove, ", "))
+		}
+
+		var summary []string
+		if len(env) > 0 {
+			summary = append(summary, "设置 "+strings.Join(sortedKeys(env), ","))
+		}
+		if len(remove) > 0 {
+			summary = append(summary, "删除 "+strings.Join(remove, ","))
+		}
+		return patch, fmt.Sprintf("容器 %s: %s", strings.Join(names, ","), strings.Join(summary, "; ")), nil
+	})
+}
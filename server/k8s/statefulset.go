@@ -11,59 +11,82 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	// Assuming CreateK8sClient and formatAge/formatLabels etc. are accessible
+	// Assuming GetClientset and formatAge/formatLabels etc. are accessible
 )
 
+// statefulSetSummary StatefulSet list row
+type statefulSetSummary struct {
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace"`
+	Ready      string   `json:"ready"`
+	Age        string   `json:"age"`
+	Containers []string `json:"containers"`
+	Images     []string `json:"images"`
+}
+
 // ListStatefulSetsTool lists StatefulSets in a given namespace.
 func ListStatefulSetsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	scope, err := parseListScope(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: list_statefulsets, namespace=", namespace)
+	fmt.Println("ai 正在调用mcp server的tool: list_statefulsets,", scope)
 
-	// Create K8s client
-	clientset, err := CreateK8sClient()
+	// Get K8s client
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// Get StatefulSet list
-	statefulsets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	statefulsets, err := clientset.AppsV1().StatefulSets(scope.namespace).List(ctx, scope.options)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取StatefulSet列表失败: %v", err)), err
 	}
 
-	// Format output
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("命名空间: %s\n\n", namespace))
-	result.WriteString("NAME\tREADY\tAGE\tCONTAINERS\tIMAGES\n") // Adjusted columns for StatefulSet
-
+	items := make([]statefulSetSummary, 0, len(statefulsets.Items))
 	for _, sts := range statefulsets.Items {
-		// Calculate age
-		age := formatAge(sts.CreationTimestamp.Time)
-
 		// Get container and image info
-		var containers []string
-		var images []string
-		for _, container := range sts.Spec.Template.Spec.Containers {
-			containers = append(containers, container.Name)
-			images = append(images, container.Image)
+		containers, images := containerNamesAndImages(sts.Spec.Template.Spec.Containers)
+
+		// StatefulSet spec has replicas pointer
+		var desiredReplicas int32 = 1
+		if sts.Spec.Replicas != nil {
+			desiredReplicas = *sts.Spec.Replicas
 		}
 
-		// StatefulSet status provides ReadyReplicas
-		readyReplicas := sts.Status.ReadyReplicas
-		desiredReplicas := *sts.Spec.Replicas // StatefulSet spec has replicas pointer
-
-		result.WriteString(fmt.Sprintf("%s\t%d/%d\t%s\t%s\t%s\n",
-			sts.Name,
-			readyReplicas,
-			desiredReplicas,
-			age,
-			strings.Join(containers, ","),
-			strings.Join(images, ",")))
+		items = append(items, statefulSetSummary{
+			Name:       sts.Name,
+			Namespace:  sts.Namespace,
+			Ready:      fmt.Sprintf("%d/%d", sts.Status.ReadyReplicas, desiredReplicas),
+			Age:        formatAge(sts.CreationTimestamp.Time),
+			Containers: containers,
+			Images:     images,
+		})
 	}
+	if format != OutputText {
+		return structuredResult(format, scope.output("StatefulSet", items, statefulsets))
+	}
+
+	// Format output
+	var result strings.Builder
+	result.WriteString(scope.header("NAME\tREADY\tAGE\tCONTAINERS\tIMAGES")) // Adjusted columns for StatefulSet
+
+	for _, item := range items {
+		result.WriteString(scope.row(item.Namespace) + fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n",
+			item.Name,
+			item.Ready,
+			item.Age,
+			strings.Join(item.Containers, ","),
+			strings.Join(item.Images, ",")))
+	}
+
+	result.WriteString(scope.footer(statefulsets))
 
 	return mcp.NewToolResultText(result.String()), nil
 }
@@ -76,12 +99,17 @@ func DescribeStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (
 		namespace = "default"
 	}
 
+	format, err := outputFormat(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
 	fmt.Println("ai 正在调用mcp server的tool: describe_statefulset, statefulset_name=", statefulSetName, ", namespace=", namespace)
 
-	// Create K8s client
-	clientset, err := CreateK8sClient()
+	// Get K8s client
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// Get StatefulSet details
@@ -90,6 +118,11 @@ func DescribeStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (
 		return mcp.NewToolResultText(fmt.Sprintf("获取StatefulSet详情失败: %v", err)), err
 	}
 
+	if format != OutputText {
+		events, _ := getEventsForStatefulSet(ctx, clientset, sts)
+		return describeResult(format, sts, events)
+	}
+
 	// Format output
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Name:               %s\n", sts.Name))
@@ -219,13 +252,14 @@ func ScaleStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (*mc
 		return mcp.NewToolResultText("缺少必要的参数: replicas"), fmt.Errorf("缺少replicas参数")
 	}
 	replicasInt := int32(replicas)
+	dryRun := isDryRun(request)
 
-	fmt.Println("ai 正在调用mcp server的tool: scale_statefulset, statefulset_name=", statefulSetName, ", namespace=", namespace, ", replicas=", replicasInt)
+	fmt.Println("ai 正在调用mcp server的tool: scale_statefulset, statefulset_name=", statefulSetName, ", namespace=", namespace, ", replicas=", replicasInt, ", dry_run=", dryRun)
 
-	// Create K8s client
-	clientset, err := CreateK8sClient()
+	// Get K8s client
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// Get current StatefulSet
@@ -235,18 +269,22 @@ func ScaleStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (*mc
 	}
 
 	// Record original replicas
+	live := sts.DeepCopy()
 	oldReplicas := *sts.Spec.Replicas
 
 	// Update replicas
 	sts.Spec.Replicas = &replicasInt
 
 	// Apply update
-	_, err = clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{})
+	updated, err := clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("扩缩StatefulSet失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("将StatefulSet %s/%s 的副本数从 %d 扩缩到 %d", namespace, statefulSetName, oldReplicas, replicasInt), live, updated)
+	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("已将StatefulSet %s 在命名空间 %s 中的副本数从 %d 扩缩到 %d",
+	return mcp.NewToolResultText(fmt.Sprintf("已将StatefulSet %s 在命名空间 %s 中的副本数从 %d 扩缩到 %d，可以调用rollout_status等待完成",
 		statefulSetName, namespace, oldReplicas, replicasInt)), nil
 }
 
@@ -258,12 +296,14 @@ func RestartStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (*
 		namespace = "default"
 	}
 
-	fmt.Println("ai 正在调用mcp server的tool: restart_statefulset, statefulset_name=", statefulSetName, ", namespace=", namespace)
+	dryRun := isDryRun(request)
+
+	fmt.Println("ai 正在调用mcp server的tool: restart_statefulset, statefulset_name=", statefulSetName, ", namespace=", namespace, ", dry_run=", dryRun)
 
-	// Create K8s client
-	clientset, err := CreateK8sClient()
+	// Get K8s client
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// Get current StatefulSet
@@ -273,6 +313,7 @@ func RestartStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (*
 	}
 
 	// Add or update restart annotation in the Pod template
+	live := sts.DeepCopy()
 	if sts.Spec.Template.Annotations == nil {
 		sts.Spec.Template.Annotations = make(map[string]string)
 	}
@@ -281,37 +322,25 @@ func RestartStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (*
 	// Apply update
 	// Note: For StatefulSets, patching might be preferred over Update to avoid conflicts,
 	// but Update works for this annotation change. Consider using Patch for more complex updates.
-	_, err = clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{})
+	updated, err := clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{DryRun: dryRunOption(dryRun)})
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("重启StatefulSet失败: %v", err)), err
 	}
+	if dryRun {
+		return dryRunResult(fmt.Sprintf("重启StatefulSet %s/%s", namespace, statefulSetName), live, updated)
+	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("StatefulSet %s 在命名空间 %s 中已开始重启", statefulSetName, namespace)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("StatefulSet %s 在命名空间 %s 中已开始重启，可以调用rollout_status等待完成", statefulSetName, namespace)), nil
 }
 
-// DeleteStatefulSetTool deletes a StatefulSet.
+// DeleteStatefulSetTool deletes a StatefulSet with an explicit propagation policy.
+// PVCs created from volumeClaimTemplates are kept unless delete_pvcs is set.
 func DeleteStatefulSetTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	statefulSetName := request.Params.Arguments["statefulset_name"].(string)
-	namespace, _ := request.Params.Arguments["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
-	}
+	statefulSetName, _ := request.Params.Arguments["statefulset_name"].(string)
 
-	fmt.Println("ai 正在调用mcp server的tool: delete_statefulset, statefulset_name=", statefulSetName, ", namespace=", namespace)
-
-	// Create K8s client
-	clientset, err := CreateK8sClient()
-	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
-	}
-
-	// Delete StatefulSet
-	err = clientset.AppsV1().StatefulSets(namespace).Delete(ctx, statefulSetName, metav1.DeleteOptions{})
-	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("删除StatefulSet失败: %v", err)), err
-	}
+	fmt.Println("ai 正在调用mcp server的tool: delete_statefulset, statefulset_name=", statefulSetName, ", namespace=", request.Params.Arguments["namespace"], ", propagation_policy=", request.Params.Arguments["propagation_policy"], ", delete_pvcs=", request.Params.Arguments["delete_pvcs"], ", dry_run=", isDryRun(request))
 
-	return mcp.NewToolResultText(fmt.Sprintf("StatefulSet %s 在命名空间 %s 中已删除", statefulSetName, namespace)), nil
+	return deleteWorkload(ctx, request, KindStatefulSet, statefulSetName)
 }
 
 // --- Helper functions ---
@@ -328,4 +357,4 @@ func getEventsForStatefulSet(ctx context.Context, clientset *kubernetes.Clientse
 
 
 // Note: Assuming formatAge, formatLabels, formatContainerPorts, formatContainerHostPorts,
-// formatEnvVars, formatVolumeMounts, CreateK8sClient are defined elsewhere (e.g., pod.go, client.go, or a shared util file)
+// formatEnvVars, formatVolumeMounts, GetClientset are defined elsewhere (e.g., pod.go, client.go, or a shared util file)
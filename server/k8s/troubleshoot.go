@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 	"github.com/mark3labs/mcp-go/mcp"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -15,10 +16,10 @@ import (
 func ClusterHealthTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	fmt.Println("ai 正在调用mcp server的tool: cluster_health")
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取节点列表
@@ -113,10 +114,10 @@ func PodDiagnosticTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 
 	fmt.Println("ai 正在调用mcp server的tool: pod_diagnostic, pod_name=", podName, ", namespace=", namespace)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Pod详情
@@ -296,10 +297,10 @@ func NodeDiagnosticTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 
 	fmt.Println("ai 正在调用mcp server的tool: node_diagnostic, node_name=", nodeName)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取节点详情
@@ -469,10 +470,10 @@ func DeploymentDiagnosticTool(ctx context.Context, request mcp.CallToolRequest)
 
 	fmt.Println("ai 正在调用mcp server的tool: deployment_diagnostic, deployment_name=", deploymentName, ", namespace=", namespace)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 获取Deployment详情
@@ -659,6 +660,7 @@ func DeploymentDiagnosticTool(ctx context.Context, request mcp.CallToolRequest)
 		result.WriteString(fmt.Sprintf("  • Deployment更新未完成: 已更新 %d/%d\n", 
 			deployment.Status.UpdatedReplicas, 
 			*deployment.Spec.Replicas))
+		result.WriteString("    - 新版本有问题时可调用rollout_undo回滚，或用set_image/set_resources/set_env修正后重新发布\n")
 	}
 	
 	// 检查可用性
@@ -716,10 +718,10 @@ func AlertAnalysisTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 
 	fmt.Println("ai 正在调用mcp server的tool: alert_analysis, alert_name=", alertName)
 
-	// 创建K8s客户端
-	clientset, err := CreateK8sClient()
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("创建Kubernetes客户端失败: %v", err)), err
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
 	}
 
 	// 格式化输出
@@ -903,6 +905,208 @@ func AlertAnalysisTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+// JobDiagnosticTool 诊断Job问题，指定CronJob时诊断其最近一次创建的Job
+func JobDiagnosticTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jobName, _ := request.Params.Arguments["job_name"].(string)
+	cronJobName, _ := request.Params.Arguments["cronjob_name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	if jobName == "" && cronJobName == "" {
+		err := fmt.Errorf("必须提供job_name或cronjob_name")
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	fmt.Println("ai 正在调用mcp server的tool: job_diagnostic, job_name=", jobName, ", cronjob_name=", cronJobName, ", namespace=", namespace)
+
+	// 获取K8s客户端
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+
+	var (
+		job     *batchv1.Job
+		cronJob *batchv1.CronJob
+		history []batchv1.Job
+	)
+	if jobName != "" {
+		job, err = clientset.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取Job详情失败: %v", err)), err
+		}
+		if cronJobName == "" {
+			cronJobName = jobOwner(job)
+		}
+	}
+	if cronJobName != "" {
+		cronJob, err = clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJobName, metav1.GetOptions{})
+		if err != nil {
+			if job == nil {
+				return mcp.NewToolResultText(fmt.Sprintf("获取CronJob详情失败: %v", err)), err
+			}
+			// Job所属的CronJob可能已被删除，只诊断Job本身
+			cronJob = nil
+		} else {
+			history, _ = jobsForCronJob(ctx, clientset, cronJob)
+			if job == nil && len(history) > 0 {
+				job = &history[0]
+			}
+		}
+	}
+
+	var result strings.Builder
+	var suggestions []string
+
+	// CronJob调度情况
+	if cronJob != nil {
+		result.WriteString(fmt.Sprintf("CronJob %s 诊断报告:\n\n", cronJob.Name))
+		result.WriteString("调度信息:\n")
+		result.WriteString(fmt.Sprintf("  调度计划: %s\n", cronJob.Spec.Schedule))
+		if cronJob.Spec.TimeZone != nil {
+			result.WriteString(fmt.Sprintf("  时区: %s\n", *cronJob.Spec.TimeZone))
+		}
+		suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+		result.WriteString(fmt.Sprintf("  已暂停: %t\n", suspended))
+		result.WriteString(fmt.Sprintf("  并发策略: %s\n", cronJob.Spec.ConcurrencyPolicy))
+		result.WriteString(fmt.Sprintf("  最近调度时间: %s\n", formatTimePtr(cronJob.Status.LastScheduleTime)))
+		result.WriteString(fmt.Sprintf("  最近成功时间: %s\n", formatTimePtr(cronJob.Status.LastSuccessfulTime)))
+		result.WriteString(fmt.Sprintf("  正在运行的Job: %d\n", len(cronJob.Status.Active)))
+
+		if len(history) > 0 {
+			failed := 0
+			result.WriteString(fmt.Sprintf("\n最近的Job (%d 总计):\n", len(history)))
+			for _, item := range history {
+				status := jobStatus(&item)
+				if status == jobFailed {
+					failed++
+				}
+				result.WriteString(fmt.Sprintf("  %s: %s, 完成 %s, 耗时 %s, 创建于 %s前\n",
+					item.Name, status, jobCompletions(&item), jobDuration(&item), formatAge(item.CreationTimestamp.Time)))
+			}
+			if failed > 0 {
+				suggestions = append(suggestions, fmt.Sprintf("保留的 %d 个Job中有 %d 个失败", len(history), failed))
+			}
+		}
+
+		if suspended {
+			suggestions = append(suggestions, "CronJob处于暂停状态，不会按计划创建Job，确认后可调用resume_cronjob恢复")
+		}
+		if cronJob.Status.LastScheduleTime == nil {
+			suggestions = append(suggestions, "CronJob从未被调度，检查调度计划是否正确以及startingDeadlineSeconds是否过小")
+		} else if cronJob.Status.LastSuccessfulTime == nil {
+			suggestions = append(suggestions, "CronJob从未成功执行过")
+		} else if cronJob.Status.LastSuccessfulTime.Before(cronJob.Status.LastScheduleTime) && len(cronJob.Status.Active) == 0 {
+			suggestions = append(suggestions, fmt.Sprintf("最近一次调度（%s前）没有成功，上次成功是在 %s前",
+				formatAge(cronJob.Status.LastScheduleTime.Time), formatAge(cronJob.Status.LastSuccessfulTime.Time)))
+		}
+		if job == nil {
+			result.WriteString("\n该CronJob当前没有保留任何Job\n")
+		} else {
+			result.WriteString("\n")
+		}
+	}
+
+	if job != nil {
+		suggestions = append(suggestions, diagnoseJob(ctx, clientset, job, &result)...)
+	}
+
+	// 诊断建议
+	result.WriteString("\n诊断建议:\n")
+	if len(suggestions) == 0 {
+		result.WriteString("  • Job看起来运行正常，没有发现明显问题\n")
+	}
+	for _, suggestion := range suggestions {
+		result.WriteString(fmt.Sprintf("  • %s\n", suggestion))
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// diagnoseJob 输出Job的执行情况、失败的Pod和事件，返回诊断建议
+func diagnoseJob(ctx context.Context, clientset *kubernetes.Clientset, job *batchv1.Job, result *strings.Builder) []string {
+	var suggestions []string
+
+	result.WriteString(fmt.Sprintf("Job %s 诊断报告:\n\n", job.Name))
+	result.WriteString("基本信息:\n")
+	result.WriteString(fmt.Sprintf("  状态: %s\n", jobStatus(job)))
+	result.WriteString(fmt.Sprintf("  完成情况: %s\n", jobCompletions(job)))
+	result.WriteString(fmt.Sprintf("  开始时间: %s\n", formatTimePtr(job.Status.StartTime)))
+	result.WriteString(fmt.Sprintf("  完成时间: %s\n", formatTimePtr(job.Status.CompletionTime)))
+	result.WriteString(fmt.Sprintf("  耗时: %s\n", jobDuration(job)))
+	result.WriteString(fmt.Sprintf("  Pod: %d 运行中 / %d 成功 / %d 失败\n", job.Status.Active, job.Status.Succeeded, job.Status.Failed))
+	result.WriteString(fmt.Sprintf("  重试上限(backoffLimit): %s\n", formatInt32Ptr(job.Spec.BackoffLimit)))
+	if job.Spec.ActiveDeadlineSeconds != nil {
+		result.WriteString(fmt.Sprintf("  运行时限(activeDeadlineSeconds): %ds\n", *job.Spec.ActiveDeadlineSeconds))
+	}
+
+	if condition := jobFailedCondition(job); condition != nil {
+		result.WriteString(fmt.Sprintf("  失败原因: %s: %s\n", condition.Reason, condition.Message))
+		switch condition.Reason {
+		case "BackoffLimitExceeded":
+			suggestions = append(suggestions, fmt.Sprintf("失败次数 %d 已达到backoffLimit %s，Job不会再重试，请查看失败Pod的日志（pod_logs）定位原因，修复后可调用trigger_cronjob重新执行",
+				job.Status.Failed, formatInt32Ptr(job.Spec.BackoffLimit)))
+		case "DeadlineExceeded":
+			suggestions = append(suggestions, "Job运行时间超过activeDeadlineSeconds被终止，检查任务是否卡住或适当调大时限")
+		default:
+			suggestions = append(suggestions, fmt.Sprintf("Job失败: %s", condition.Reason))
+		}
+	} else if job.Spec.BackoffLimit != nil && job.Status.Failed > 0 {
+		result.WriteString(fmt.Sprintf("  已失败 %d 次，距离backoffLimit还剩 %d 次\n", job.Status.Failed, *job.Spec.BackoffLimit-job.Status.Failed))
+		suggestions = append(suggestions, fmt.Sprintf("Job已失败 %d 次，仍在重试中", job.Status.Failed))
+	}
+
+	// Job创建的Pod
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err == nil {
+		pods, err := clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err == nil {
+			result.WriteString(fmt.Sprintf("\nPod (%d 总计):\n", len(pods.Items)))
+			pending := 0
+			for _, pod := range pods.Items {
+				result.WriteString(fmt.Sprintf("  %s: %s (节点 %s, 创建于 %s前)\n", pod.Name, pod.Status.Phase, pod.Spec.NodeName, formatAge(pod.CreationTimestamp.Time)))
+				if pod.Status.Phase == corev1.PodPending {
+					pending++
+				}
+				for _, cs := range pod.Status.ContainerStatuses {
+					if terminated := cs.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+						result.WriteString(fmt.Sprintf("    容器 %s: Terminated (退出码: %d, 原因: %s)\n", cs.Name, terminated.ExitCode, terminated.Reason))
+						if terminated.Reason == "OOMKilled" {
+							suggestions = append(suggestions, fmt.Sprintf("Pod %s 的容器 %s 因内存不足被杀死(OOMKilled)，考虑调大内存限制", pod.Name, cs.Name))
+						}
+					} else if waiting := cs.State.Waiting; waiting != nil {
+						result.WriteString(fmt.Sprintf("    容器 %s: Waiting (%s)\n", cs.Name, waiting.Reason))
+						if strings.Contains(waiting.Reason, "ImagePull") || waiting.Reason == "InvalidImageName" {
+							suggestions = append(suggestions, fmt.Sprintf("Pod %s 的镜像拉取失败(%s)，检查镜像地址和拉取凭据", pod.Name, waiting.Reason))
+						}
+					}
+				}
+			}
+			if pending > 0 {
+				suggestions = append(suggestions, fmt.Sprintf("有 %d 个Pod处于Pending状态，检查资源配额和节点调度", pending))
+			}
+			if job.Status.Failed > 0 && len(pods.Items) == 0 {
+				suggestions = append(suggestions, "失败的Pod已被清理，无法查看日志，可以调大backoffLimit或设置podFailurePolicy保留现场")
+			}
+		}
+	}
+
+	// 获取相关事件
+	events, err := getEventsForJob(ctx, clientset, job)
+	if err == nil && len(events.Items) > 0 {
+		result.WriteString("\n最近事件:\n")
+		for _, event := range events.Items {
+			result.WriteString(fmt.Sprintf("  %s [%s] %s: %s\n",
+				formatAge(event.LastTimestamp.Time),
+				event.Type,
+				event.Reason,
+				event.Message))
+		}
+	}
+	return suggestions
+}
+
 // 辅助函数：获取节点相关事件
 func getEventsForNode(ctx context.Context, clientset *kubernetes.Clientset, node *corev1.Node) (*corev1.EventList, error) {
 	fieldSelector := fmt.Sprintf("involvedObject.kind=Node,involvedObject.name=%s", node.Name)
@@ -6,9 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mcp-devops/server/config"
 	"net/http"
-	"os"
 	"strings" // Import strings package
+	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -20,6 +21,40 @@ const (
 	defaultTemplateCard = "text_notice"
 )
 
+// weChatChannels 配置的企业微信机器人，第一个为默认渠道
+var (
+	weChatMu       sync.RWMutex
+	weChatChannels []config.WeChatChannel
+)
+
+// ConfigureWeChat 设置企业微信通知渠道
+func ConfigureWeChat(channels []config.WeChatChannel) {
+	weChatMu.Lock()
+	defer weChatMu.Unlock()
+	weChatChannels = append([]config.WeChatChannel(nil), channels...)
+}
+
+// weChatWebhook 返回指定渠道的Webhook地址，未指定时使用默认渠道，没有配置任何渠道时返回空字符串
+func weChatWebhook(channel string) (string, error) {
+	weChatMu.RLock()
+	defer weChatMu.RUnlock()
+
+	if channel == "" {
+		if len(weChatChannels) == 0 {
+			return "", nil
+		}
+		return weChatChannels[0].WebhookURL, nil
+	}
+	names := make([]string, 0, len(weChatChannels))
+	for _, c := range weChatChannels {
+		if c.Name == channel {
+			return c.WebhookURL, nil
+		}
+		names = append(names, c.Name)
+	}
+	return "", fmt.Errorf("企业微信通知渠道 %s 不存在，可用的渠道: %s", channel, strings.Join(names, ", "))
+}
+
 // WeChatMessage 企业微信消息结构
 type WeChatMessage struct {
 	MsgType      string                 `json:"msgtype"`
@@ -89,12 +124,16 @@ func SendWeChatMessageTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		}
 	}
 
-	webhookURL := os.Getenv("WECHAT_WEBHOOK_URL")
+	channel, _ := request.Params.Arguments["channel"].(string)
+	webhookURL, err := weChatWebhook(channel)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
 	if webhookURL == "" {
 		webhookURL, _ = request.Params.Arguments["webhook_url"].(string)
 		if webhookURL == "" {
-			return mcp.NewToolResultText("企业微信Webhook URL未设置，请配置WECHAT_WEBHOOK_URL环境变量或在参数中提供webhook_url"),
-				fmt.Errorf("企业微信Webhook URL未设置，请配置WECHAT_WEBHOOK_URL环境变量或在参数中提供webhook_url")
+			return mcp.NewToolResultText("企业微信Webhook URL未设置，请在配置文件的notifiers.wechat中配置渠道、配置WECHAT_WEBHOOK_URL环境变量或在参数中提供webhook_url"),
+				fmt.Errorf("企业微信Webhook URL未设置，请在配置文件的notifiers.wechat中配置渠道、配置WECHAT_WEBHOOK_URL环境变量或在参数中提供webhook_url")
 		}
 	}
 
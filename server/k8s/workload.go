@@ -0,0 +1,474 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 支持滚动更新操作的工作负载类型
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+)
+
+// WithWorkloadArguments 为滚动更新类工具追加kind和name参数
+func WithWorkloadArguments() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("工作负载类型: deployment、statefulset 或 daemonset，也支持简称 deploy、sts、ds"),
+		)(tool)
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("工作负载名称"),
+		)(tool)
+	}
+}
+
+// parseWorkloadKind 把kind参数解析为工作负载类型，忽略大小写并支持简称和复数
+func parseWorkloadKind(kind string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "deployment", "deployments", "deploy":
+		return KindDeployment, nil
+	case "statefulset", "statefulsets", "sts":
+		return KindStatefulSet, nil
+	case "daemonset", "daemonsets", "ds":
+		return KindDaemonSet, nil
+	}
+	return "", fmt.Errorf("不支持的工作负载类型: %s（仅支持 deployment、statefulset 或 daemonset）", kind)
+}
+
+// workload 统一访问Deployment、StatefulSet和DaemonSet，obj为对应的类型化对象
+type workload struct {
+	kind string
+	obj  runtime.Object
+}
+
+// getWorkload 读取kind、name和namespace参数并获取工作负载
+func getWorkload(ctx context.Context, clientset *kubernetes.Clientset, request mcp.CallToolRequest) (*workload, error) {
+	kindArg, _ := request.Params.Arguments["kind"].(string)
+	name, _ := request.Params.Arguments["name"].(string)
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	kind, err := parseWorkloadKind(kindArg)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("必须提供name参数")
+	}
+	return getWorkloadByName(ctx, clientset, kind, namespace, name)
+}
+
+// getWorkloadByName 按类型、命名空间和名称获取工作负载
+func getWorkloadByName(ctx context.Context, clientset *kubernetes.Clientset, kind, namespace, name string) (*workload, error) {
+	var (
+		obj runtime.Object
+		err error
+	)
+	switch kind {
+	case KindDeployment:
+		obj, err = clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	case KindStatefulSet:
+		obj, err = clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case KindDaemonSet:
+		obj, err = clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取%s %s/%s失败: %v", kind, namespace, name, err)
+	}
+	return &workload{kind: kind, obj: obj}, nil
+}
+
+// refresh 重新获取最新的对象
+func (w *workload) refresh(ctx context.Context, clientset *kubernetes.Clientset) (*workload, error) {
+	return getWorkloadByName(ctx, clientset, w.kind, w.meta().GetNamespace(), w.meta().GetName())
+}
+
+// watch 从当前对象的resourceVersion开始监听该对象的变化
+func (w *workload) watch(ctx context.Context, clientset *kubernetes.Clientset) (watch.Interface, error) {
+	namespace := w.meta().GetNamespace()
+	opts := metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", w.meta().GetName()).String(),
+		ResourceVersion: w.meta().GetResourceVersion(),
+	}
+	switch w.kind {
+	case KindDeployment:
+		return clientset.AppsV1().Deployments(namespace).Watch(ctx, opts)
+	case KindStatefulSet:
+		return clientset.AppsV1().StatefulSets(namespace).Watch(ctx, opts)
+	default:
+		return clientset.AppsV1().DaemonSets(namespace).Watch(ctx, opts)
+	}
+}
+
+// String 返回用于提示信息的名称，例如 Deployment default/web
+func (w *workload) String() string {
+	return fmt.Sprintf("%s %s/%s", w.kind, w.meta().GetNamespace(), w.meta().GetName())
+}
+
+// meta 返回对象的元数据
+func (w *workload) meta() metav1.Object {
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		return obj
+	case *appsv1.StatefulSet:
+		return obj
+	default:
+		return obj.(*appsv1.DaemonSet)
+	}
+}
+
+// template 返回Pod模板，修改返回值会直接修改对象
+func (w *workload) template() *corev1.PodTemplateSpec {
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		return &obj.Spec.Template
+	case *appsv1.StatefulSet:
+		return &obj.Spec.Template
+	default:
+		return &obj.(*appsv1.DaemonSet).Spec.Template
+	}
+}
+
+// selector 返回工作负载的标签选择器
+func (w *workload) selector() *metav1.LabelSelector {
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		return obj.Spec.Selector
+	case *appsv1.StatefulSet:
+		return obj.Spec.Selector
+	default:
+		return obj.(*appsv1.DaemonSet).Spec.Selector
+	}
+}
+
+// deepCopy 复制工作负载，用于演练模式下保留修改前的对象
+func (w *workload) deepCopy() *workload {
+	return &workload{kind: w.kind, obj: w.obj.DeepCopyObject()}
+}
+
+// update 提交对对象的修改
+func (w *workload) update(ctx context.Context, clientset *kubernetes.Clientset, dryRun bool) (*workload, error) {
+	opts := metav1.UpdateOptions{DryRun: dryRunOption(dryRun)}
+	namespace := w.meta().GetNamespace()
+
+	var (
+		updated runtime.Object
+		err     error
+	)
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		updated, err = clientset.AppsV1().Deployments(namespace).Update(ctx, obj, opts)
+	case *appsv1.StatefulSet:
+		updated, err = clientset.AppsV1().StatefulSets(namespace).Update(ctx, obj, opts)
+	case *appsv1.DaemonSet:
+		updated, err = clientset.AppsV1().DaemonSets(namespace).Update(ctx, obj, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &workload{kind: w.kind, obj: updated}, nil
+}
+
+// patch 以指定的补丁类型修改对象
+func (w *workload) patch(ctx context.Context, clientset *kubernetes.Clientset, patchType types.PatchType, data []byte, dryRun bool) (*workload, error) {
+	opts := metav1.PatchOptions{DryRun: dryRunOption(dryRun)}
+	namespace, name := w.meta().GetNamespace(), w.meta().GetName()
+
+	var (
+		patched runtime.Object
+		err     error
+	)
+	switch w.kind {
+	case KindDeployment:
+		patched, err = clientset.AppsV1().Deployments(namespace).Patch(ctx, name, patchType, data, opts)
+	case KindStatefulSet:
+		patched, err = clientset.AppsV1().StatefulSets(namespace).Patch(ctx, name, patchType, data, opts)
+	case KindDaemonSet:
+		patched, err = clientset.AppsV1().DaemonSets(namespace).Patch(ctx, name, patchType, data, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &workload{kind: w.kind, obj: patched}, nil
+}
+
+// WithPropagationPolicyArgument 为工作负载删除工具追加propagation_policy参数
+func WithPropagationPolicyArgument() mcp.ToolOption {
+	return mcp.WithString("propagation_policy",
+		mcp.Description("级联删除策略: background（默认，立即删除工作负载，由垃圾回收在后台删除其Pod等依赖对象）、foreground（依赖对象全部删除后才删除工作负载）、orphan（只删除工作负载，保留Pod等依赖对象）"),
+		mcp.Enum("background", "foreground", "orphan"),
+		mcp.DefaultString("background"),
+	)
+}
+
+// parsePropagationPolicy 读取propagation_policy参数，默认与kubectl一致为background
+func parsePropagationPolicy(request mcp.CallToolRequest) (metav1.DeletionPropagation, error) {
+	policy, _ := request.Params.Arguments["propagation_policy"].(string)
+	switch strings.ToLower(policy) {
+	case "", "background":
+		return metav1.DeletePropagationBackground, nil
+	case "foreground":
+		return metav1.DeletePropagationForeground, nil
+	case "orphan":
+		return metav1.DeletePropagationOrphan, nil
+	}
+	return "", fmt.Errorf("不支持的级联删除策略: %s（仅支持 background、foreground 或 orphan）", policy)
+}
+
+// workloadDependent 工作负载的依赖对象，删除工作负载时按级联策略处理
+type workloadDependent struct {
+	kind   string
+	name   string
+	detail string
+}
+
+// workloadDependents 获取由工作负载（Deployment经由ReplicaSet）管理的ReplicaSet、ControllerRevision和Pod
+func workloadDependents(ctx context.Context, clientset *kubernetes.Clientset, w *workload) ([]workloadDependent, error) {
+	selector, err := metav1.LabelSelectorAsSelector(w.selector())
+	if err != nil {
+		return nil, fmt.Errorf("解析选择器失败: %v", err)
+	}
+	namespace := w.meta().GetNamespace()
+	opts := metav1.ListOptions{LabelSelector: selector.String()}
+
+	var dependents []workloadDependent
+	// Pod的直接所有者：Deployment为其ReplicaSet，StatefulSet和DaemonSet为其自身
+	owners := []metav1.Object{w.meta()}
+	if w.kind == KindDeployment {
+		owners = nil
+		replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取ReplicaSet列表失败: %v", err)
+		}
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			if !metav1.IsControlledBy(rs, w.meta()) {
+				continue
+			}
+			owners = append(owners, rs)
+			dependents = append(dependents, workloadDependent{
+				kind:   "ReplicaSet",
+				name:   rs.Name,
+				detail: fmt.Sprintf("修订版本 %s, 副本 %d", rs.Annotations[deploymentRevisionAnnotation], rs.Status.Replicas),
+			})
+		}
+	} else {
+		revisions, err := clientset.AppsV1().ControllerRevisions(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取ControllerRevision列表失败: %v", err)
+		}
+		for i := range revisions.Items {
+			cr := &revisions.Items[i]
+			if metav1.IsControlledBy(cr, w.meta()) {
+				dependents = append(dependents, workloadDependent{
+					kind:   "ControllerRevision",
+					name:   cr.Name,
+					detail: fmt.Sprintf("修订版本 %d", cr.Revision),
+				})
+			}
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("获取Pod列表失败: %v", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, owner := range owners {
+			if metav1.IsControlledBy(pod, owner) {
+				dependents = append(dependents, workloadDependent{
+					kind:   "Pod",
+					name:   pod.Name,
+					detail: fmt.Sprintf("%s, 节点 %s", pod.Status.Phase, pod.Spec.NodeName),
+				})
+				break
+			}
+		}
+	}
+	return dependents, nil
+}
+
+// statefulSetClaims 获取由StatefulSet的volumeClaimTemplates创建的PVC，名称格式为 <模板名>-<StatefulSet名>-<序号>
+// 这些PVC不属于StatefulSet，不会被级联删除
+func statefulSetClaims(ctx context.Context, clientset *kubernetes.Clientset, sts *appsv1.StatefulSet) ([]corev1.PersistentVolumeClaim, error) {
+	if len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return nil, nil
+	}
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(sts.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取PVC列表失败: %v", err)
+	}
+
+	var claims []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcs.Items {
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			ordinal, ok := strings.CutPrefix(pvc.Name, template.Name+"-"+sts.Name+"-")
+			if ok && isOrdinal(ordinal) {
+				claims = append(claims, pvc)
+				break
+			}
+		}
+	}
+	return claims, nil
+}
+
+// isOrdinal 判断字符串是否为StatefulSet的Pod序号
+func isOrdinal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// delete 按级联策略删除工作负载，通过UID前置条件保证删除的是预览时看到的对象
+func (w *workload) delete(ctx context.Context, clientset *kubernetes.Clientset, policy metav1.DeletionPropagation, dryRun bool) error {
+	uid := w.meta().GetUID()
+	opts := metav1.DeleteOptions{
+		DryRun:            dryRunOption(dryRun),
+		PropagationPolicy: &policy,
+		Preconditions:     &metav1.Preconditions{UID: &uid},
+	}
+	namespace, name := w.meta().GetNamespace(), w.meta().GetName()
+	switch w.kind {
+	case KindDeployment:
+		return clientset.AppsV1().Deployments(namespace).Delete(ctx, name, opts)
+	case KindStatefulSet:
+		return clientset.AppsV1().StatefulSets(namespace).Delete(ctx, name, opts)
+	default:
+		return clientset.AppsV1().DaemonSets(namespace).Delete(ctx, name, opts)
+	}
+}
+
+// deleteWorkload 预览依赖对象后按propagation_policy删除工作负载
+// StatefulSet的PVC默认保留，delete_pvcs为true时在删除StatefulSet后一并删除
+func deleteWorkload(ctx context.Context, request mcp.CallToolRequest, kind, name string) (*mcp.CallToolResult, error) {
+	namespace, _ := request.Params.Arguments["namespace"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+	deletePVCs, _ := request.Params.Arguments["delete_pvcs"].(bool)
+	dryRun := isDryRun(request)
+	policy, err := parsePropagationPolicy(request)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+
+	clientset, err := GetClientset(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取Kubernetes客户端失败: %v", err)), err
+	}
+	w, err := getWorkloadByName(ctx, clientset, kind, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	dependents, err := workloadDependents(ctx, clientset, w)
+	if err != nil {
+		return mcp.NewToolResultText(err.Error()), err
+	}
+	var claims []corev1.PersistentVolumeClaim
+	sts, isStatefulSet := w.obj.(*appsv1.StatefulSet)
+	if isStatefulSet {
+		if claims, err = statefulSetClaims(ctx, clientset, sts); err != nil {
+			return mcp.NewToolResultText(err.Error()), err
+		}
+	}
+
+	// 预览删除会影响的对象
+	var preview strings.Builder
+	preview.WriteString(fmt.Sprintf("删除 %s（级联策略: %s）\n", w, strings.ToLower(string(policy))))
+	dependentAction := map[metav1.DeletionPropagation]string{
+		metav1.DeletePropagationBackground: "将在工作负载删除后由垃圾回收在后台删除",
+		metav1.DeletePropagationForeground: "将先于工作负载删除",
+		metav1.DeletePropagationOrphan:     "将保留，不再有所有者",
+	}[policy]
+	if len(dependents) == 0 {
+		preview.WriteString("依赖对象: 无\n")
+	} else {
+		preview.WriteString(fmt.Sprintf("依赖对象（%d 个，%s）:\n", len(dependents), dependentAction))
+		for _, d := range dependents {
+			preview.WriteString(fmt.Sprintf("  %s/%s: %s\n", d.kind, d.name, d.detail))
+		}
+	}
+	if len(claims) > 0 {
+		claimAction := "将保留，设置delete_pvcs=true可一并删除"
+		if deletePVCs {
+			claimAction = "将在删除StatefulSet后一并删除，其中的数据可能随PV的回收策略丢失"
+		} else if retention := sts.Spec.PersistentVolumeClaimRetentionPolicy; retention != nil && retention.WhenDeleted == appsv1.DeletePersistentVolumeClaimRetentionPolicyType && policy != metav1.DeletePropagationOrphan {
+			claimAction = "StatefulSet的persistentVolumeClaimRetentionPolicy.whenDeleted为Delete，将由控制器删除"
+		}
+		preview.WriteString(fmt.Sprintf("PVC（%d 个，%s）:\n", len(claims), claimAction))
+		for _, pvc := range claims {
+			capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+			preview.WriteString(fmt.Sprintf("  %s: %s, 容量 %s, StorageClass %s\n",
+				pvc.Name, pvc.Status.Phase, capacity.String(), orNone(stringValue(pvc.Spec.StorageClassName))))
+		}
+	}
+
+	if err := w.delete(ctx, clientset, policy, dryRun); err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("%s删除%s失败: %v", preview.String(), w, err)), err
+	}
+
+	// PVC不属于StatefulSet，只能逐个删除，单个失败不影响其他PVC
+	var failed []string
+	if isStatefulSet && deletePVCs {
+		for _, pvc := range claims {
+			err := clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{DryRun: dryRunOption(dryRun)})
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("  %s: %v", pvc.Name, err))
+			}
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(preview.String())
+	result.WriteString("\n")
+	if dryRun {
+		text, err := dryRunText(fmt.Sprintf("删除%s", w), w.obj, nil)
+		if err != nil {
+			return mcp.NewToolResultText(err.Error()), err
+		}
+		result.WriteString(text)
+	} else {
+		result.WriteString(fmt.Sprintf("%s 已删除", w))
+		if isStatefulSet && deletePVCs && len(claims) > len(failed) {
+			result.WriteString(fmt.Sprintf("，已删除 %d 个PVC", len(claims)-len(failed)))
+		}
+		result.WriteString("\n")
+	}
+	if len(failed) > 0 {
+		result.WriteString(fmt.Sprintf("\n%d/%d 个PVC删除失败:\n%s\n", len(failed), len(claims), strings.Join(failed, "\n")))
+		return mcp.NewToolResultError(result.String()), nil
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// stringValue 返回字符串指针的值，nil时为空字符串
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestIsOrdinal(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"0", true},
+		{"12", true},
+		{"", false},
+		{"1x", false},
+		{"x1", false},
+		{"1-0", false},
+		{"-1", false},
+	}
+	for _, tt := range tests {
+		if got := isOrdinal(tt.s); got != tt.want {
+			t.Errorf("isOrdinal(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestStatefulSetClaims(t *testing.T) {
+	var pvcs corev1.PersistentVolumeClaimList
+	for _, name := range []string{
+		"data-web-0", "data-web-1", "logs-web-0", // web 的两个模板
+		"data-web-1x",  // 序号不是数字
+		"data-web-1-0", // 属于 StatefulSet web-1
+		"data-web",     // 缺少序号
+		"data-website-0", "cache-web-0", "web-0",
+	} {
+		pvcs.Items = append(pvcs.Items, corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})
+	}
+	list, _ := json.Marshal(pvcs)
+	api := newFakeAPIServer(t, map[string]string{"/api/v1/namespaces/default/persistentvolumeclaims": string(list)})
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: api.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statefulSet := func(name string, templates ...string) *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		for _, template := range templates {
+			sts.Spec.VolumeClaimTemplates = append(sts.Spec.VolumeClaimTemplates, corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: template}})
+		}
+		return sts
+	}
+
+	tests := []struct {
+		name string
+		sts  *appsv1.StatefulSet
+		want []string
+	}{
+		{"多个模板", statefulSet("web", "data", "logs"), []string{"data-web-0", "data-web-1", "logs-web-0"}},
+		{"名称为前缀的StatefulSet", statefulSet("web-1", "data"), []string{"data-web-1-0"}},
+		{"没有匹配的PVC", statefulSet("db", "data"), nil},
+		{"没有模板时不查询PVC", statefulSet("web"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := statefulSetClaims(context.Background(), clientset, tt.sts)
+			if err != nil {
+				t.Fatalf("statefulSetClaims: %v", err)
+			}
+			var got []string
+			for _, claim := range claims {
+				got = append(got, claim.Name)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("statefulSetClaims() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("statefulSetClaims() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	// 只有带模板的StatefulSet会查询PVC列表
+	if requests := api.recorded(); len(requests) != 3 {
+		t.Errorf("PVC列表请求 = %v, want 3次", requests)
+	}
+}
@@ -33,7 +33,7 @@ func KubeletStatusTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	result.WriteString("Kubelet状态:\n\n")
 
 	for _, cmd := range commands {
-		output, err := executeCommand(cmd)
+		output, err := executeCommand(ctx, cmd)
 		if err != nil {
 			result.WriteString(fmt.Sprintf("执行命令 '%s' 失败: %v\n", cmd, err))
 			continue
@@ -113,7 +113,7 @@ func ContainerRuntimeStatusTool(ctx context.Context, request mcp.CallToolRequest
 	result.WriteString(fmt.Sprintf("容器运行时 '%s' 状态:\n\n", runtime))
 
 	for _, cmd := range commands {
-		output, err := executeCommand(cmd)
+		output, err := executeCommand(ctx, cmd)
 		if err != nil {
 			result.WriteString(fmt.Sprintf("执行命令 '%s' 失败: %v\n", cmd, err))
 			continue
@@ -152,7 +152,7 @@ func KubeProxyStatusTool(ctx context.Context, request mcp.CallToolRequest) (*mcp
 	result.WriteString("Kube-Proxy状态:\n\n")
 
 	for _, cmd := range commands {
-		output, err := executeCommand(cmd)
+		output, err := executeCommand(ctx, cmd)
 		if err != nil {
 			result.WriteString(fmt.Sprintf("执行命令 '%s' 失败: %v\n", cmd, err))
 			continue
@@ -218,7 +218,7 @@ func NodeNetworkDebugTool(ctx context.Context, request mcp.CallToolRequest) (*mc
 	}
 
 	for _, cmd := range commands {
-		output, err := executeCommand(cmd)
+		output, err := executeCommand(ctx, cmd)
 		if err != nil {
 			result.WriteString(fmt.Sprintf("执行命令 '%s' 失败: %v\n", cmd, err))
 			continue
@@ -298,7 +298,7 @@ func CNIStatusTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallT
 	}
 
 	for _, cmd := range commands {
-		output, err := executeCommand(cmd)
+		output, err := executeCommand(ctx, cmd)
 		if err != nil {
 			result.WriteString(fmt.Sprintf("执行命令 '%s' 失败: %v\n", cmd, err))
 			continue
@@ -334,7 +334,7 @@ func KubeComponentLogsTool(ctx context.Context, request mcp.CallToolRequest) (*m
 	}
 
 	// 执行命令
-	output, err := executeCommand(command)
+	output, err := executeCommand(ctx, command)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取组件日志失败: %v", err)), err
 	}
@@ -354,8 +354,8 @@ func KubeComponentLogsTool(ctx context.Context, request mcp.CallToolRequest) (*m
 		warnCommand = fmt.Sprintf("journalctl -u %s --no-pager | grep -i \"warn\" | wc -l", component)
 	}
 
-	errorCount, err1 := executeCommand(errorCommand)
-	warnCount, err2 := executeCommand(warnCommand)
+	errorCount, err1 := executeCommand(ctx, errorCommand)
+	warnCount, err2 := executeCommand(ctx, warnCommand)
 
 	if err1 == nil && err2 == nil {
 		result.WriteString("\n日志统计:\n")
@@ -436,7 +436,7 @@ func ContainerInspectTool(ctx context.Context, request mcp.CallToolRequest) (*mc
 	result.WriteString(fmt.Sprintf("容器 '%s' 详情:\n\n", containerID))
 
 	for _, cmd := range commands {
-		output, err := executeCommand(cmd)
+		output, err := executeCommand(ctx, cmd)
 		if err != nil {
 			result.WriteString(fmt.Sprintf("执行命令 '%s' 失败: %v\n", cmd, err))
 			continue
@@ -0,0 +1,117 @@
+package linux
+
+import (
+	"fmt"
+	"mcp-devops/server/config"
+	"mcp-devops/server/metrics"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSettings holds the SSH inventory, replaced as a whole when the configuration is reloaded
+var (
+	sshMu       sync.RWMutex
+	sshSettings config.SSHConfig
+)
+
+// ConfigureSSH sets the default SSH user, key and the host inventory.
+// Tools may refer to an inventory host by name; unknown hostnames are dialled directly with the defaults.
+func ConfigureSSH(cfg config.SSHConfig) {
+	sshMu.Lock()
+	defer sshMu.Unlock()
+	sshSettings = cfg
+}
+
+// sshTarget resolves a hostname or inventory name to a dial address and client configuration
+func sshTarget(hostname string) (string, *ssh.ClientConfig, error) {
+	sshMu.RLock()
+	settings := sshSettings
+	sshMu.RUnlock()
+
+	address, user, keyPath := hostname, settings.User, settings.KeyPath
+	for _, host := range settings.Hosts {
+		if host.Name == hostname {
+			address = host.Address
+			if host.User != "" {
+				user = host.User
+			}
+			if host.KeyPath != "" {
+				keyPath = host.KeyPath
+			}
+			break
+		}
+	}
+
+	// Format host with port (default to 22 if not specified)
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "22")
+	}
+
+	clientConfig, err := newSSHClientConfig(user, keyPath)
+	if err != nil {
+		return "", nil, err
+	}
+	return address, clientConfig, nil
+}
+
+// sshHostLabel returns the inventory name used as the metrics label for a hostname.
+// Hosts outside the inventory share a single label to keep the series count bounded.
+func sshHostLabel(hostname string) string {
+	sshMu.RLock()
+	defer sshMu.RUnlock()
+
+	for _, host := range sshSettings.Hosts {
+		if host.Name == hostname || host.Address == hostname {
+			return host.Name
+		}
+		if h, _, err := net.SplitHostPort(host.Address); err == nil && h == hostname {
+			return host.Name
+		}
+	}
+	return metrics.SSHOtherHost
+}
+
+// newSSHClientConfig builds an SSH client configuration for passwordless authentication
+func newSSHClientConfig(user, keyPath string) (*ssh.ClientConfig, error) {
+	// Default to ~/.ssh/id_rsa
+	if keyPath == "" {
+		keyPath = os.Getenv("HOME") + "/.ssh/id_rsa"
+	}
+	if user == "" {
+		user = os.Getenv("USER") // Fallback to current user
+	}
+
+	// Read the private key
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取SSH私钥文件 %s: %v", keyPath, err)
+	}
+
+	// Create the signer for this private key
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("解析SSH私钥失败: %v", err)
+	}
+
+	return &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeys(signer),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // Note: In production, use a proper host key verification
+		Timeout:         5 * time.Second,
+	}, nil
+}
+
+// dialSSH opens an SSH connection to a hostname or inventory name
+func dialSSH(hostname string) (*ssh.Client, error) {
+	address, clientConfig, err := sshTarget(hostname)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.Dial("tcp", address, clientConfig)
+}
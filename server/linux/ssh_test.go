@@ -0,0 +1,33 @@
+package linux
+
+import (
+	"testing"
+
+	"mcp-devops/server/config"
+	"mcp-devops/server/metrics"
+)
+
+func TestSSHHostLabel(t *testing.T) {
+	ConfigureSSH(config.SSHConfig{Hosts: []config.SSHHost{
+		{Name: "web-1", Address: "10.0.0.1"},
+		{Name: "db-1", Address: "10.0.0.2:2222"},
+	}})
+	t.Cleanup(func() { ConfigureSSH(config.SSHConfig{}) })
+
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"web-1", "web-1"},
+		{"10.0.0.1", "web-1"},
+		{"10.0.0.2:2222", "db-1"},
+		{"10.0.0.2", "db-1"},
+		{"10.0.0.3", metrics.SSHOtherHost},
+		{"build-42.example.com", metrics.SSHOtherHost},
+	}
+	for _, tt := range tests {
+		if got := sshHostLabel(tt.hostname); got != tt.want {
+			t.Errorf("sshHostLabel(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
@@ -4,72 +4,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"os"
+	"mcp-devops/server/metrics"
+	"mcp-devops/server/tracing"
 	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"golang.org/x/crypto/ssh"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// sshClient is a reusable SSH client for command execution
-var sshClient *ssh.Client
-var sshConfig *ssh.ClientConfig
-
-// initSSHConfig initializes SSH configuration for passwordless authentication
-func initSSHConfig() error {
-	// Use environment variable for SSH key path or default to ~/.ssh/id_rsa
-	sshKeyPath := os.Getenv("SSH_KEY_PATH")
-	if sshKeyPath == "" {
-		sshKeyPath = os.Getenv("HOME") + "/.ssh/id_rsa"
-	}
-
-	// Read the private key
-	key, err := os.ReadFile(sshKeyPath)
-	if err != nil {
-		return fmt.Errorf("无法读取SSH私钥文件 %s: %v", sshKeyPath, err)
-	}
-
-	// Create the signer for this private key
-	signer, err := ssh.ParsePrivateKey(key)
-	if err != nil {
-		return fmt.Errorf("解析SSH私钥失败: %v", err)
-	}
-
-	// Configure SSH client with passwordless auth
-	sshConfig = &ssh.ClientConfig{
-		User: os.Getenv("SSH_USER"),
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // Note: In production, use a proper host key verification
-		Timeout:         5 * time.Second,
-	}
-
-	if sshConfig.User == "" {
-		sshConfig.User = os.Getenv("USER") // Fallback to current user
-	}
-
-	return nil
-}
-
 // testSSHConnection tests if SSH passwordless connection is working for a given host
 func testSSHConnection(hostname string) error {
-	if sshConfig == nil {
-		if err := initSSHConfig(); err != nil {
-			return err
-		}
-	}
-
-	// Format host with port (default to 22 if not specified)
-	hostWithPort := hostname
-	if !strings.Contains(hostname, ":") {
-		hostWithPort = hostname + ":22"
-	}
-
 	// Attempt to establish SSH connection
-	client, err := ssh.Dial("tcp", hostWithPort, sshConfig)
+	client, err := dialSSH(hostname)
 	if err != nil {
 		return fmt.Errorf("SSH连接测试失败，请确保已配置免密认证: %v", err)
 	}
@@ -80,22 +27,18 @@ func testSSHConnection(hostname string) error {
 }
 
 // executeSSHCommand executes a command via SSH on a remote host
-func executeSSHCommand(hostname, command string) (string, error) {
-	if sshConfig == nil {
-		if err := initSSHConfig(); err != nil {
-			return "", err
-		}
-	}
-
-	// Format host with port (default to 22 if not specified)
-	hostWithPort := hostname
-	if !strings.Contains(hostname, ":") {
-		hostWithPort = hostname + ":22"
-	}
+func executeSSHCommand(ctx context.Context, hostname, command string) (output string, err error) {
+	_, span := tracing.StartClient(ctx, "ssh.exec",
+		attribute.String("ssh.host", hostname),
+		attribute.String("ssh.command", command),
+	)
+	defer func() { tracing.End(span, err) }()
+	done := metrics.SSHSessionStarted(sshHostLabel(hostname))
 
 	// Establish SSH connection
-	client, err := ssh.Dial("tcp", hostWithPort, sshConfig)
+	client, err := dialSSH(hostname)
 	if err != nil {
+		done(metrics.SSHDialError)
 		return "", fmt.Errorf("无法连接到 %s: %v", hostname, err)
 	}
 	defer client.Close()
@@ -103,6 +46,7 @@ func executeSSHCommand(hostname, command string) (string, error) {
 	// Create a session
 	session, err := client.NewSession()
 	if err != nil {
+		done(metrics.SSHSessionError)
 		return "", fmt.Errorf("创建SSH会话失败: %v", err)
 	}
 	defer session.Close()
@@ -114,14 +58,19 @@ func executeSSHCommand(hostname, command string) (string, error) {
 
 	err = session.Run(command)
 	if err != nil {
+		done(metrics.SSHCommandError)
 		return stderr.String(), fmt.Errorf("执行命令失败: %v\n错误输出: %s", err, stderr.String())
 	}
+	done(metrics.SSHSuccess)
 
 	return stdout.String(), nil
 }
 
 // executeLocalCommand executes a command locally on the server
-func executeLocalCommand(command string) (string, error) {
+func executeLocalCommand(ctx context.Context, command string) (output string, err error) {
+	_, span := tracing.Start(ctx, "local.exec", attribute.String("command", command))
+	defer func() { tracing.End(span, err) }()
+
 	// Split the command into parts for exec.Command
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
@@ -133,7 +82,7 @@ func executeLocalCommand(command string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
 		return stderr.String(), fmt.Errorf("执行本地命令失败: %v\n错误输出: %s", err, stderr.String())
 	}
@@ -142,7 +91,7 @@ func executeLocalCommand(command string) (string, error) {
 }
 
 // executeCommand 辅助函数：执行命令
-func executeCommand(command string) (string, error) {
+func executeCommand(ctx context.Context, command string) (string, error) {
 	// Check if the command involves SSH to a remote host
 	if strings.HasPrefix(command, "ssh ") {
 		// Extract hostname from the command
@@ -166,7 +115,7 @@ func executeCommand(command string) (string, error) {
 		remoteCommand := command[sshCmdIndex+1 : len(command)-1]
 
 		// Execute the command via SSH
-		output, err := executeSSHCommand(hostname, remoteCommand)
+		output, err := executeSSHCommand(ctx, hostname, remoteCommand)
 		if err != nil {
 			return "", fmt.Errorf("SSH命令执行失败: %v", err)
 		}
@@ -174,7 +123,7 @@ func executeCommand(command string) (string, error) {
 	}
 
 	// Local command execution
-	output, err := executeLocalCommand(command)
+	output, err := executeLocalCommand(ctx, command)
 	if err != nil {
 		return "", fmt.Errorf("本地命令执行失败: %v", err)
 	}
@@ -208,7 +157,7 @@ func SystemInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 	result.WriteString(fmt.Sprintf("<b>%s节点系统信息：</b><br><br>", hostname))
 
 	// 获取内核信息
-	unameOutput, err := executeCommand(unameCmd)
+	unameOutput, err := executeCommand(ctx, unameCmd)
 	if err != nil {
 		result.WriteString(fmt.Sprintf("获取内核信息失败: %v<br>", err))
 	} else {
@@ -218,7 +167,7 @@ func SystemInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 	}
 
 	// 获取操作系统信息
-	osReleaseOutput, err := executeCommand(osReleaseCmd)
+	osReleaseOutput, err := executeCommand(ctx, osReleaseCmd)
 	if err != nil {
 		result.WriteString(fmt.Sprintf("获取操作系统信息失败: %v<br>", err))
 	} else {
@@ -240,7 +189,7 @@ func SystemInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 	}
 
 	// 获取运行时间
-	uptimeOutput, err := executeCommand(uptimeCmd)
+	uptimeOutput, err := executeCommand(ctx, uptimeCmd)
 	if err != nil {
 		result.WriteString(fmt.Sprintf("获取运行时间失败: %v<br>", err))
 	} else {
@@ -250,7 +199,7 @@ func SystemInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 	}
 
 	// 获取内存使用情况
-	memOutput, err := executeCommand(memCmd)
+	memOutput, err := executeCommand(ctx, memCmd)
 	if err != nil {
 		result.WriteString(fmt.Sprintf("获取内存信息失败: %v<br>", err))
 	} else {
@@ -264,7 +213,7 @@ func SystemInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 	}
 
 	// 获取磁盘使用情况
-	diskOutput, err := executeCommand(diskCmd)
+	diskOutput, err := executeCommand(ctx, diskCmd)
 	if err != nil {
 		result.WriteString(fmt.Sprintf("获取磁盘信息失败: %v<br>", err))
 	} else {
@@ -300,18 +249,18 @@ func ProcessInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 		if processName != "" {
 			command = fmt.Sprintf("ssh %s 'ps aux | grep %s | grep -v grep'", hostname, processName)
 		} else {
-			command = fmt.Sprintf("ssh %s 'ps aux --sort=-%cpu | head -%d'", hostname, int(topCount))
+			command = fmt.Sprintf("ssh %s 'ps aux --sort=-%%cpu | head -%d'", hostname, int(topCount))
 		}
 	} else {
 		if processName != "" {
 			command = fmt.Sprintf("ps aux | grep %s | grep -v grep", processName)
 		} else {
-			command = fmt.Sprintf("ps aux --sort=-%cpu | head -%d", int(topCount))
+			command = fmt.Sprintf("ps aux --sort=-%%cpu | head -%d", int(topCount))
 		}
 	}
 
 	// 执行命令
-	output, err := executeCommand(command)
+	output, err := executeCommand(ctx, command)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取进程信息失败: %v", err)), err
 	}
@@ -347,7 +296,7 @@ func ResourceUsageTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	}
 
 	// 执行命令
-	output, err := executeCommand(command)
+	output, err := executeCommand(ctx, command)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取资源使用情况失败: %v", err)), err
 	}
@@ -365,7 +314,7 @@ func ResourceUsageTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 		memCommand = "free -h"
 	}
 
-	memOutput, err := executeCommand(memCommand)
+	memOutput, err := executeCommand(ctx, memCommand)
 	if err == nil {
 		result.WriteString("\n内存使用情况:\n\n")
 		result.WriteString(memOutput)
@@ -379,7 +328,7 @@ func ResourceUsageTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 		diskCommand = "df -h"
 	}
 
-	diskOutput, err := executeCommand(diskCommand)
+	diskOutput, err := executeCommand(ctx, diskCommand)
 	if err == nil {
 		result.WriteString("\n磁盘使用情况:\n\n")
 		result.WriteString(diskOutput)
@@ -434,7 +383,7 @@ func NetworkInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	}
 
 	for _, cmd := range commands {
-		output, err := executeCommand(cmd)
+		output, err := executeCommand(ctx, cmd)
 		if err != nil {
 			result.WriteString(fmt.Sprintf("执行命令 '%s' 失败: %v\n", cmd, err))
 			continue
@@ -479,7 +428,7 @@ func LogAnalysisTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	}
 
 	// 执行命令
-	output, err := executeCommand(command)
+	output, err := executeCommand(ctx, command)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("分析日志失败: %v", err)), err
 	}
@@ -504,8 +453,8 @@ func LogAnalysisTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 			warnCommand = fmt.Sprintf("grep -i \"warn\" %s | wc -l", logPath)
 		}
 
-		errorCount, err1 := executeCommand(errorCommand)
-		warnCount, err2 := executeCommand(warnCommand)
+		errorCount, err1 := executeCommand(ctx, errorCommand)
+		warnCount, err2 := executeCommand(ctx, warnCommand)
 
 		if err1 == nil && err2 == nil {
 			result.WriteString("\n日志统计:\n")
@@ -541,7 +490,7 @@ func ServiceStatusTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	}
 
 	// 执行命令
-	output, err := executeCommand(command)
+	output, err := executeCommand(ctx, command)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("获取服务状态失败: %v", err)), err
 	}
@@ -1,13 +1,17 @@
 package loki
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mcp-devops/server/tracing"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // LokiResponse represents the structure of Loki API response
@@ -51,7 +55,13 @@ type LokiStats struct {
 }
 
 // QueryLoki sends a query to Loki and returns the response
-func QueryLoki(lokiAddress, logQL string, startTime, endTime time.Time, limit int, direction string) (*LokiResponse, error) {
+func QueryLoki(ctx context.Context, lokiAddress, logQL string, startTime, endTime time.Time, limit int, direction string) (resp *LokiResponse, err error) {
+	ctx, span := tracing.StartClient(ctx, "loki.query_range",
+		attribute.String("loki.address", lokiAddress),
+		attribute.String("loki.query", logQL),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	// Build request URL
 	apiEndpoint := fmt.Sprintf("%s/loki/api/v1/query_range", lokiAddress)
 	queryParams := url.Values{}
@@ -68,7 +78,7 @@ func QueryLoki(lokiAddress, logQL string, startTime, endTime time.Time, limit in
 		Timeout: 60 * time.Second,
 	}
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建 HTTP GET 请求失败: %v", err)
 	}
@@ -77,21 +87,21 @@ func QueryLoki(lokiAddress, logQL string, startTime, endTime time.Time, limit in
 	req.Header.Set("Accept", "application/json")
 
 	// Send request
-	resp, err := client.Do(req)
+	httpResp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("执行 HTTP 请求失败: %v", err)
 	}
-	defer resp.Body.Close()
+	defer httpResp.Body.Close()
 
 	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("读取响应体失败: %v", err)
 	}
 
 	// Check status code
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("请求未成功 (状态码: %d)，响应内容: %s", resp.StatusCode, string(bodyBytes))
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, fmt.Errorf("请求未成功 (状态码: %d)，响应内容: %s", httpResp.StatusCode, string(bodyBytes))
 	}
 
 	// Parse JSON response
@@ -107,3 +117,20 @@ func QueryLoki(lokiAddress, logQL string, startTime, endTime time.Time, limit in
 
 	return &lokiResp, nil
 }
+
+// Ping checks that the default Loki endpoint is ready
+func Ping() error {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+	resp, err := client.Get(defaultAddress() + "/ready")
+	if err != nil {
+		return fmt.Errorf("执行 HTTP 请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Loki 未就绪 (状态码: %d)", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package loki
+
+import (
+	"mcp-devops/server/config"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// endpoints holds the configured Loki endpoints; the first one is the default.
+var (
+	endpointsMu sync.RWMutex
+	endpoints   []config.LokiEndpoint
+)
+
+// Configure replaces the Loki endpoints the tools query by default.
+func Configure(list []config.LokiEndpoint) {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	endpoints = append([]config.LokiEndpoint(nil), list...)
+}
+
+// resolveAddress resolves the loki_address argument: empty means the default endpoint,
+// a configured endpoint name maps to its URL, anything else is used as the URL itself.
+func resolveAddress(request mcp.CallToolRequest) string {
+	address, _ := request.Params.Arguments["loki_address"].(string)
+	address = strings.TrimSpace(address)
+
+	if address == "" {
+		return defaultAddress()
+	}
+
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	for _, endpoint := range endpoints {
+		if endpoint.Name == address {
+			return endpoint.URL
+		}
+	}
+	return address
+}
+
+// defaultAddress returns the URL of the first configured endpoint.
+func defaultAddress() string {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	if len(endpoints) == 0 {
+		return config.DefaultLokiURL
+	}
+	return endpoints[0].URL
+}
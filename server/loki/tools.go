@@ -20,10 +20,7 @@ type FormattedLogEntry struct {
 
 // ServiceLogsTool handles querying logs for a specific service for the last 30 minutes
 func ServiceLogsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	lokiAddress, ok := request.Params.Arguments["loki_address"].(string)
-	if !ok {
-		lokiAddress = "http://localhost:3100" // Default address
-	}
+	lokiAddress := resolveAddress(request)
 
 	serviceName, ok := request.Params.Arguments["service_name"].(string)
 	if !ok {
@@ -36,7 +33,7 @@ func ServiceLogsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	limit := 300
 	direction := "backward"
 
-	resp, err := QueryLoki(lokiAddress, logQL, startTime, endTime, limit, direction)
+	resp, err := QueryLoki(ctx, lokiAddress, logQL, startTime, endTime, limit, direction)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("查询服务日志失败: %v", err)), err
 	}
@@ -59,10 +56,7 @@ func ServiceLogsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 
 // TimeRangeLogsTool handles querying logs for a specific service within a time range
 func TimeRangeLogsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	lokiAddress, ok := request.Params.Arguments["loki_address"].(string)
-	if !ok {
-		lokiAddress = "http://localhost:3100" // Default address
-	}
+	lokiAddress := resolveAddress(request)
 
 	serviceName, ok := request.Params.Arguments["service_name"].(string)
 	if !ok {
@@ -93,7 +87,7 @@ func TimeRangeLogsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 	limit := 300
 	direction := "backward"
 
-	resp, err := QueryLoki(lokiAddress, logQL, startTime, endTime, limit, direction)
+	resp, err := QueryLoki(ctx, lokiAddress, logQL, startTime, endTime, limit, direction)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("查询服务日志失败: %v", err)), err
 	}
@@ -119,8 +113,7 @@ func AddLokiTools(svr *server.MCPServer) {
 	svr.AddTool(mcp.NewTool("loki_service_logs",
 		mcp.WithDescription("查询指定服务的日志（最近30分钟）"),
 		mcp.WithString("loki_address",
-			mcp.Description("Loki服务器地址，默认为http://localhost:3100"),
-			mcp.DefaultString("http://localhost:3100"),
+			mcp.Description("Loki服务器地址或配置文件中的Loki名称，默认为配置的第一个Loki地址"),
 		),
 		mcp.WithString("service_name",
 			mcp.Description("要查询日志的服务名（app标签）"),
@@ -130,8 +123,7 @@ func AddLokiTools(svr *server.MCPServer) {
 	svr.AddTool(mcp.NewTool("loki_time_range_logs",
 		mcp.WithDescription("查询指定服务在指定时间范围内的日志"),
 		mcp.WithString("loki_address",
-			mcp.Description("Loki服务器地址，默认为http://localhost:3100"),
-			mcp.DefaultString("http://localhost:3100"),
+			mcp.Description("Loki服务器地址或配置文件中的Loki名称，默认为配置的第一个Loki地址"),
 		),
 		mcp.WithString("service_name",
 			mcp.Description("要查询日志的服务名（app标签）"),
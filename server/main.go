@@ -1,42 +1,212 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
+	"mcp-devops/server/auth"
+	"mcp-devops/server/config"
+	"mcp-devops/server/metrics"
 	"mcp-devops/server/sse"
+	"mcp-devops/server/streamable"
+	"mcp-devops/server/tracing"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// 支持的传输方式
+const (
+	transportStdio          = "stdio"           // 通过标准输入输出与本地MCP宿主（桌面客户端、IDE）通信
+	transportSSE            = "sse"             // 通过HTTP提供SSE端点，同时提供streamable HTTP端点
+	transportStreamableHTTP = "streamable-http" // 只提供streamable HTTP端点
+)
+
 func main() {
-	var err error
-	// 加载环境变量
-	err = godotenv.Load()
+	configFile := flag.String("config", "", "YAML配置文件，默认读取 MCP_CONFIG，未配置时只使用环境变量")
+	transport := flag.String("transport", "", "传输方式: stdio、sse 或 streamable-http，覆盖配置文件和 MCP_TRANSPORT")
+	address := flag.String("address", "", "HTTP方式的监听地址，覆盖配置文件和 MCP_SERVER_ADDRESS")
+	envFile := flag.String("env-file", ".env", "环境变量文件，不存在时忽略")
+	flag.Parse()
+
+	// 加载环境变量文件，配置也可以直接来自环境变量和命令行参数
+	if err := godotenv.Load(*envFile); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || isFlagSet("env-file") {
+			log.Fatalf("加载环境变量文件 %s 失败: %v", *envFile, err)
+		}
+	}
+	if *configFile == "" {
+		*configFile = os.Getenv("MCP_CONFIG")
+	}
+
+	// 命令行参数的优先级最高
+	flagOverrides := func(cfg *config.Config) {
+		if *transport != "" {
+			cfg.Server.Transport = *transport
+		}
+		if *address != "" {
+			cfg.Server.Address = *address
+		}
+	}
+	cfg, err := config.Load(*configFile, flagOverrides)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatal(err)
+	}
+
+	// stdio方式下标准输出是MCP协议通道，其余输出全部改写到标准错误
+	stdout := os.Stdout
+	if cfg.Server.Transport == transportStdio {
+		os.Stdout = os.Stderr
+		log.SetOutput(os.Stderr)
 	}
 
 	fmt.Println("======================================")
 	fmt.Println("Kubernetes MCP 服务器启动中...")
 	fmt.Println("版本: 1.1.0")
 	fmt.Println("======================================")
+	if *configFile != "" {
+		fmt.Println("已加载配置文件:", *configFile)
+	}
 
-	// 获取配置参数
-	address := os.Getenv("MCP_SERVER_ADDRESS")
+	// 按OTEL_*环境变量初始化链路追踪，未配置导出地址时只透传追踪上下文
+	shutdownTracing, err := tracing.Setup(context.Background(), "mcp-devops-server")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if tracing.Enabled() {
+		fmt.Println("已启用OpenTelemetry链路追踪")
+	}
 
 	// 创建并配置 MCP 服务器
-	svr, _ := sse.K8sServer()
+	svr, err := sse.K8sServer(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// 添加HTTP服务器
-	sseServer := server.NewSSEServer(svr)
+	// 收到SIGHUP时重新加载配置文件
+	go reloadOnSignal(cfg, *configFile, flagOverrides)
 
-	// 启动服务器
-	fmt.Printf("MCP服务器启动成功，监听地址: %s\n", address)
-	err = http.ListenAndServe(address, sseServer)
+	// 单独的指标监听地址不需要认证，stdio方式下只能通过这种方式暴露指标
+	if cfg.Server.MetricsAddress != "" {
+		go serveMetrics(cfg.Server.MetricsAddress)
+	}
+
+	switch cfg.Server.Transport {
+	case transportStdio:
+		err = serveStdio(svr, stdout)
+	default:
+		err = serveHTTP(svr, cfg, cfg.Server.Transport == transportSSE)
+	}
+	// 退出前导出尚未发送的span
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		log.Printf("关闭链路追踪失败: %v", shutdownErr)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// reloadOnSignal 收到SIGHUP时重新加载并校验配置，校验失败时继续使用原配置
+// 只有SSH主机清单、Redis实例、Loki地址和通知渠道会立即生效，监听地址、认证、审计等配置需要重启
+func reloadOnSignal(running *config.Config, configFile string, overrides func(*config.Config)) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		cfg, err := config.Load(configFile, overrides)
+		if err != nil {
+			log.Printf("重新加载配置失败，继续使用原配置: %v", err)
+			continue
+		}
+		sse.ApplyRuntimeConfig(cfg)
+		log.Println("配置已重新加载，SSH主机清单、Redis实例、Loki地址和通知渠道已更新")
+		if changed := running.RestartRequired(cfg); len(changed) > 0 {
+			log.Printf("以下配置已修改但需要重启后生效: %s", strings.Join(changed, ", "))
+		}
+	}
+}
+
+// serveStdio 以stdio方式运行，调用方是启动本进程的本地用户
+func serveStdio(svr *server.MCPServer, stdout *os.File) error {
+	identity := auth.LocalIdentity()
+	fmt.Printf("MCP服务器以stdio方式启动，本地调用方: %s\n", identity.Name)
+
+	ctx := auth.WithIdentity(context.Background(), identity)
+	return server.NewStdioServer(svr).Listen(ctx, os.Stdin, stdout)
+}
+
+// serveHTTP 以HTTP方式运行，streamable HTTP端点始终可用，withSSE为true时同时提供SSE端点
+func serveHTTP(svr *server.MCPServer, cfg *config.Config, withSSE bool) error {
+	address := cfg.Server.Address
+	// 读取认证配置，SSE端点和消息端点都需要认证
+	authCfg, err := sse.AuthConfig(cfg)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := authCfg.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if !authCfg.Enabled() {
+		fmt.Println("警告: 未配置 MCP_API_TOKEN 或 MCP_TLS_CLIENT_CA，任何能访问该地址的客户端都可以调用工具")
+	}
+
+	// 添加HTTP服务器
+	mux := http.NewServeMux()
+	mux.Handle(streamable.DefaultEndpoint, streamable.NewServer(svr))
+	if withSSE {
+		// SSE服务器自行处理 /sse 和 /message 路径
+		mux.Handle("/", server.NewSSEServer(svr))
+	}
+	if cfg.Server.MetricsAddress == "" {
+		// 与MCP端点共用认证，Prometheus需要配置相同的Bearer令牌
+		mux.Handle(metrics.Path, metrics.Handler())
+	}
+	httpServer := &http.Server{
+		Addr:      address,
+		Handler:   authCfg.Middleware(tracing.HTTPMiddleware(mux)),
+		TLSConfig: tlsConfig,
+	}
+
+	// 启动服务器
+	fmt.Printf("MCP服务器启动成功，监听地址: %s，streamable HTTP端点: %s\n", address, streamable.DefaultEndpoint)
+	if withSSE {
+		fmt.Println("SSE端点: /sse，消息端点: /message")
+	}
+	if cfg.Server.MetricsAddress == "" {
+		fmt.Println("指标端点:", metrics.Path)
+	}
+	if tlsConfig != nil {
+		// 证书已加载到TLSConfig中
+		return httpServer.ListenAndServeTLS("", "")
+	}
+	return httpServer.ListenAndServe()
+}
+
+// serveMetrics 在单独的地址上提供Prometheus指标
+func serveMetrics(address string) {
+	mux := http.NewServeMux()
+	mux.Handle(metrics.Path, metrics.Handler())
+	fmt.Printf("指标端点: http://%s%s\n", address, metrics.Path)
+	if err := http.ListenAndServe(address, mux); err != nil {
+		log.Printf("指标服务器退出: %v", err)
+	}
+}
+
+// isFlagSet 命令行中是否显式指定了参数
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path 指标端点的路径
+const Path = "/metrics"
+
+// namespace 所有指标名称的前缀
+const namespace = "mcp"
+
+// registry 服务器自身的指标注册表，不使用全局注册表，避免依赖库注册的指标混入
+var registry = prometheus.NewRegistry()
+
+var (
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "工具调用总次数",
+	}, []string{"tool"})
+
+	toolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_errors_total",
+		Help:      "返回错误的工具调用次数，包括处理函数返回的错误和isError结果",
+	}, []string{"tool"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "工具调用耗时",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"tool"})
+
+	toolsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_in_flight",
+		Help:      "正在执行的工具调用数",
+	}, []string{"tool"})
+
+	kubeRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kubernetes_requests_total",
+		Help:      "发往Kubernetes API Server的请求数，code为HTTP状态码，连接失败时为error",
+	}, []string{"cluster", "method", "code"})
+
+	sshSessions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ssh_sessions_total",
+		Help:      "SSH会话次数，host为主机清单中的名称，不在清单中的主机为other，result为success、dial_error、session_error或command_error",
+	}, []string{"host", "result"})
+
+	sshSessionsActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ssh_sessions_active",
+		Help:      "当前打开的SSH会话数",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		toolCalls, toolErrors, toolDuration, toolsInFlight,
+		kubeRequests, sshSessions, sshSessionsActive,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler 返回暴露指标的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Middleware 记录每次工具调用的次数、错误、耗时和并发数
+func Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool := request.Params.Name
+		toolsInFlight.WithLabelValues(tool).Inc()
+		start := time.Now()
+
+		result, err := next(ctx, request)
+
+		toolsInFlight.WithLabelValues(tool).Dec()
+		toolDuration.WithLabelValues(tool).Observe(time.Since(start).Seconds())
+		toolCalls.WithLabelValues(tool).Inc()
+		if err != nil || (result != nil && result.IsError) {
+			toolErrors.WithLabelValues(tool).Inc()
+		}
+		return result, err
+	}
+}
+
+// KubernetesTransport 返回统计Kubernetes API请求的RoundTripper包装函数，用于rest.Config.Wrap
+func KubernetesTransport(cluster string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := rt.RoundTrip(req)
+			code := "error"
+			if err == nil {
+				code = strconv.Itoa(resp.StatusCode)
+			}
+			kubeRequests.WithLabelValues(cluster, req.Method, code).Inc()
+			return resp, err
+		})
+	}
+}
+
+// roundTripperFunc 把函数适配为http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip 执行请求
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// SSH会话结果
+const (
+	SSHSuccess      = "success"
+	SSHDialError    = "dial_error"
+	SSHSessionError = "session_error"
+	SSHCommandError = "command_error"
+)
+
+// SSHOtherHost 不在主机清单中的主机统一使用的host标签，避免任意主机名导致标签基数无限增长
+const SSHOtherHost = "other"
+
+// SSHSessionStarted 记录一次SSH会话开始，返回的函数在会话结束时以结果调用
+// host应该是主机清单中的名称或SSHOtherHost
+func SSHSessionStarted(host string) func(result string) {
+	sshSessionsActive.Inc()
+	return func(result string) {
+		sshSessionsActive.Dec()
+		sshSessions.WithLabelValues(host, result).Inc()
+	}
+}
@@ -3,7 +3,6 @@ package redis
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -16,39 +15,39 @@ type Client struct {
 	ctx context.Context
 }
 
-// NewClient creates a new Redis client by reading connection details from environment variables.
-func NewClient() (*Client, error) {
-	addr := os.Getenv("REDIS_ADDR")
-	if addr == "" {
-		addr = "localhost:6379" // Default fallback
-	}
-
-	password := os.Getenv("REDIS_PASSWORD")
-	dbStr := os.Getenv("REDIS_DB")
-	db := 0 // Default DB
-	if dbStr != "" {
-		var dbNum int
-		_, err := fmt.Sscanf(dbStr, "%d", &dbNum)
-		if err == nil {
-			db = dbNum
-		}
+// NewClient creates a new Redis client for the named instance, or the default instance when name is empty.
+// Commands run with ctx so that they are traced as children of the calling tool.
+func NewClient(ctx context.Context, name string) (*Client, error) {
+	instance, err := lookupInstance(name)
+	if err != nil {
+		return nil, err
 	}
 
 	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
+		Addr:     instance.Addr,
+		Password: instance.Password,
+		DB:       instance.DB,
 	})
 
-	ctx := context.Background()
-	_, err := rdb.Ping(ctx).Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+	rdb.AddHook(tracingHook{instance: instance.Name})
+
+	if _, err := rdb.Ping(ctx).Result(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("failed to connect to Redis %s (%s): %v", instance.Name, instance.Addr, err)
 	}
 
 	return &Client{rdb: rdb, ctx: ctx}, nil
 }
 
+// Ping checks that the default instance is reachable.
+func Ping() error {
+	client, err := NewClient(context.Background(), "")
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
+
 // Close closes the Redis connection.
 func (c *Client) Close() error {
 	return c.rdb.Close()
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"fmt"
+	"mcp-devops/server/config"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// instances holds the configured Redis instances; the first one is the default.
+var (
+	instancesMu sync.RWMutex
+	instances   []config.RedisInstance
+)
+
+// Configure replaces the Redis instances the tools can connect to.
+func Configure(list []config.RedisInstance) {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	instances = append([]config.RedisInstance(nil), list...)
+}
+
+// lookupInstance returns the named instance, or the default instance when name is empty.
+func lookupInstance(name string) (config.RedisInstance, error) {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	if len(instances) == 0 {
+		return config.RedisInstance{Name: "default", Addr: config.DefaultRedisAddr}, nil
+	}
+	if name == "" {
+		return instances[0], nil
+	}
+	names := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Name == name {
+			return instance, nil
+		}
+		names = append(names, instance.Name)
+	}
+	return config.RedisInstance{}, fmt.Errorf("unknown Redis instance %q, available: %s", name, strings.Join(names, ", "))
+}
+
+// instanceArgument reads the optional instance argument of a tool call.
+func instanceArgument(request mcp.CallToolRequest) string {
+	name, _ := request.Params.Arguments["instance"].(string)
+	return strings.TrimSpace(name)
+}
+
+// withInstanceArgument adds the instance argument shared by all Redis tools.
+func withInstanceArgument() mcp.ToolOption {
+	return mcp.WithString("instance",
+		mcp.Description("Redis实例名称，对应配置文件中redis列表的name，默认为第一个实例"),
+	)
+}
@@ -14,7 +14,7 @@ import (
 
 // InfoTool handles the redis-cli INFO command.
 func InfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -31,7 +31,7 @@ func InfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolRe
 
 // SlowLogTool handles the redis-cli SLOWLOG command.
 func SlowLogTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -48,7 +48,7 @@ func SlowLogTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 
 // BigKeysTool handles the redis-cli --bigkeys command.
 func BigKeysTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -64,7 +64,7 @@ func BigKeysTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 
 // HotKeysTool handles the redis-cli --hotkeys command.
 func HotKeysTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -80,7 +80,7 @@ func HotKeysTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 
 // MonitorTool handles the redis-cli MONITOR command.
 func MonitorTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -99,7 +99,7 @@ func MonitorTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 
 // LatencyTool handles the redis-cli --latency command.
 func LatencyTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -115,7 +115,7 @@ func LatencyTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 
 // LatencyHistoryTool handles the redis-cli --latency-history command.
 func LatencyHistoryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -137,7 +137,7 @@ func LatencyHistoryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 
 // StatTool handles the redis-cli --stat command.
 func StatTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	client, err := NewClient()
+	client, err := NewClient(ctx, instanceArgument(request))
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("Failed to connect to Redis: %v", err)), err
 	}
@@ -155,6 +155,7 @@ func StatTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolRe
 func AddRedisTools(svr *server.MCPServer) {
 	svr.AddTool(mcp.NewTool("redis_info",
 		mcp.WithDescription("获取Redis服务器运行时信息"),
+		withInstanceArgument(),
 		mcp.WithString("section",
 			mcp.Description("要查询的信息部分，例如SERVER, CLIENTS, MEMORY等，默认为ALL"),
 			mcp.DefaultString("ALL"),
@@ -163,6 +164,7 @@ func AddRedisTools(svr *server.MCPServer) {
 
 	svr.AddTool(mcp.NewTool("redis_slowlog",
 		mcp.WithDescription("分析Redis慢查询日志"),
+		withInstanceArgument(),
 		mcp.WithNumber("count",
 			mcp.Description("要获取的慢查询日志条目数，默认为10"),
 			mcp.DefaultNumber(10),
@@ -171,22 +173,27 @@ func AddRedisTools(svr *server.MCPServer) {
 
 	svr.AddTool(mcp.NewTool("redis_bigkeys",
 		mcp.WithDescription("查找Redis中的大键"),
+		withInstanceArgument(),
 	), BigKeysTool)
 
 	svr.AddTool(mcp.NewTool("redis_hotkeys",
 		mcp.WithDescription("查找Redis中的热键"),
+		withInstanceArgument(),
 	), HotKeysTool)
 
 	svr.AddTool(mcp.NewTool("redis_monitor",
 		mcp.WithDescription("实时查看Redis执行的命令流（高负载，慎用）"),
+		withInstanceArgument(),
 	), MonitorTool)
 
 	svr.AddTool(mcp.NewTool("redis_latency",
 		mcp.WithDescription("测量Redis的延迟"),
+		withInstanceArgument(),
 	), LatencyTool)
 
 	svr.AddTool(mcp.NewTool("redis_latency_history",
 		mcp.WithDescription("测量Redis延迟并获取历史数据"),
+		withInstanceArgument(),
 		mcp.WithNumber("interval",
 			mcp.Description("测量间隔时间（秒），默认为1秒"),
 			mcp.DefaultNumber(1),
@@ -199,6 +206,7 @@ func AddRedisTools(svr *server.MCPServer) {
 
 	svr.AddTool(mcp.NewTool("redis_stat",
 		mcp.WithDescription("实时查看Redis简洁统计信息"),
+		withInstanceArgument(),
 	), StatTool)
 }
 
@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"mcp-devops/server/tracing"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingHook creates a child span for every Redis command.
+type tracingHook struct {
+	instance string
+}
+
+// BeforeProcess starts the span of a single command.
+func (h tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = tracing.StartClient(ctx, "redis "+cmd.Name(),
+		attribute.String("db.system", "redis"),
+		attribute.String("db.redis.instance", h.instance),
+	)
+	return ctx, nil
+}
+
+// AfterProcess ends the span of a single command.
+func (h tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	tracing.End(trace.SpanFromContext(ctx), commandError(cmd))
+	return nil
+}
+
+// BeforeProcessPipeline starts the span of a pipeline.
+func (h tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, _ = tracing.StartClient(ctx, "redis pipeline",
+		attribute.String("db.system", "redis"),
+		attribute.String("db.redis.instance", h.instance),
+		attribute.Int("db.redis.pipeline_length", len(cmds)),
+	)
+	return ctx, nil
+}
+
+// AfterProcessPipeline ends the span of a pipeline.
+func (h tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if err = commandError(cmd); err != nil {
+			break
+		}
+	}
+	tracing.End(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+// commandError returns the command error, treating a missing key as success.
+func commandError(cmd redis.Cmder) error {
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		return err
+	}
+	return nil
+}
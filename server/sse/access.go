@@ -0,0 +1,223 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolAccess 工具的访问级别
+type ToolAccess string
+
+const (
+	AccessRead  ToolAccess = "read"  // 只读取信息，不改变任何系统状态
+	AccessWrite ToolAccess = "write" // 会创建、修改或删除资源
+)
+
+// WriteToolMarker 写工具描述的前缀，客户端据此识别需要人工审批的工具，避免在客户端另外维护一份写工具清单
+const WriteToolMarker = "【写操作】"
+
+// 只读模式下处理写工具的方式
+const (
+	ReadOnlyHide   = "hide"   // 从tools/list中隐藏写工具，调用时同样拒绝
+	ReadOnlyReject = "reject" // 写工具仍然可见，但调用时返回错误
+)
+
+// toolAccess 所有工具的访问级别分类
+// 新增工具时必须在此登记，未登记的工具一律按写工具处理
+var toolAccess = map[string]ToolAccess{
+	// 集群
+	"list_clusters": AccessRead,
+
+	// Pod
+	"list_pods":    AccessRead,
+	"describe_pod": AccessRead,
+	"pod_logs":     AccessRead,
+	"delete_pod":   AccessWrite,
+
+	// Deployment
+	"list_deployments":    AccessRead,
+	"describe_deployment": AccessRead,
+	"scale_deployment":    AccessWrite,
+	"restart_deployment":  AccessWrite,
+	"delete_deployment":   AccessWrite,
+
+	// DaemonSet
+	"list_daemonsets":    AccessRead,
+	"describe_daemonset": AccessRead,
+	"restart_daemonset":  AccessWrite,
+	"delete_daemonset":   AccessWrite,
+
+	// StatefulSet
+	"list_statefulsets":    AccessRead,
+	"describe_statefulset": AccessRead,
+	"scale_statefulset":    AccessWrite,
+	"restart_statefulset":  AccessWrite,
+	"delete_statefulset":   AccessWrite,
+
+	// Service
+	"list_services":       AccessRead,
+	"describe_service":    AccessRead,
+	"modify_service_type": AccessWrite,
+
+	// Namespace
+	"list_namespaces":    AccessRead,
+	"describe_namespace": AccessRead,
+	"create_namespace":   AccessWrite,
+	"delete_namespace":   AccessWrite,
+
+	// Ingress
+	"list_ingresses":   AccessRead,
+	"describe_ingress": AccessRead,
+	"create_ingress":   AccessWrite,
+	"update_ingress":   AccessWrite,
+	"delete_ingress":   AccessWrite,
+
+	// ConfigMap
+	"list_configmaps":    AccessRead,
+	"describe_configmap": AccessRead,
+	"create_configmap":   AccessWrite,
+	"update_configmap":   AccessWrite,
+	"delete_configmap":   AccessWrite,
+
+	// Secret
+	"list_secrets":    AccessRead,
+	"describe_secret": AccessRead,
+	"create_secret":   AccessWrite,
+	"update_secret":   AccessWrite,
+	"delete_secret":   AccessWrite,
+
+	// Job和CronJob
+	"list_jobs":        AccessRead,
+	"describe_job":     AccessRead,
+	"delete_job":       AccessWrite,
+	"list_cronjobs":    AccessRead,
+	"describe_cronjob": AccessRead,
+	"delete_cronjob":   AccessWrite,
+	"trigger_cronjob":  AccessWrite,
+	"suspend_cronjob":  AccessWrite,
+	"resume_cronjob":   AccessWrite,
+
+	// 滚动更新
+	"rollout_history": AccessRead,
+	"rollout_undo":    AccessWrite,
+	"rollout_pause":   AccessWrite,
+	"rollout_resume":  AccessWrite,
+	"rollout_status":  AccessRead,
+	"set_image":       AccessWrite,
+	"set_resources":   AccessWrite,
+	"set_env":         AccessWrite,
+
+	// 通用资源
+	"get_resource":      AccessRead,
+	"list_resources":    AccessRead,
+	"describe_resource": AccessRead,
+	"delete_resource":   AccessWrite,
+	"apply_manifest":    AccessWrite,
+	"diff_manifest":     AccessRead,
+
+	// 故障诊断
+	"cluster_health":        AccessRead,
+	"pod_diagnostic":        AccessRead,
+	"node_diagnostic":       AccessRead,
+	"deployment_diagnostic": AccessRead,
+	"job_diagnostic":        AccessRead,
+	"alert_analysis":        AccessRead,
+
+	// Linux系统，只执行查看类命令
+	"system_info":              AccessRead,
+	"process_info":             AccessRead,
+	"resource_usage":           AccessRead,
+	"network_info":             AccessRead,
+	"log_analysis":             AccessRead,
+	"service_status":           AccessRead,
+	"kubelet_status":           AccessRead,
+	"container_runtime_status": AccessRead,
+	"kube_proxy_status":        AccessRead,
+	"node_network_debug":       AccessRead,
+	"cni_status":               AccessRead,
+	"kube_component_logs":      AccessRead,
+	"container_inspect":        AccessRead,
+
+	// 通知，只向外发送消息，不改变被管理的系统，告警分析流程需要在只读模式下使用
+	"send_wechat_message": AccessRead,
+
+	// Redis诊断
+	"redis_info":            AccessRead,
+	"redis_slowlog":         AccessRead,
+	"redis_bigkeys":         AccessRead,
+	"redis_hotkeys":         AccessRead,
+	"redis_monitor":         AccessRead,
+	"redis_latency":         AccessRead,
+	"redis_latency_history": AccessRead,
+	"redis_stat":            AccessRead,
+
+	// Loki日志
+	"loki_service_logs":    AccessRead,
+	"loki_time_range_logs": AccessRead,
+
+	// 审计日志
+	"query_audit_log": AccessRead,
+}
+
+// ToolAccessOf 返回工具的访问级别，未分类的工具按写工具处理
+func ToolAccessOf(name string) ToolAccess {
+	if access, ok := toolAccess[name]; ok {
+		return access
+	}
+	return AccessWrite
+}
+
+// markWriteTools 在tools/list结果中写工具的描述前加上WriteToolMarker
+func markWriteTools(ctx context.Context, id any, message *mcp.ListToolsRequest, result *mcp.ListToolsResult) {
+	for i, tool := range result.Tools {
+		if ToolAccessOf(tool.Name) == AccessWrite && !strings.HasPrefix(tool.Description, WriteToolMarker) {
+			result.Tools[i].Description = WriteToolMarker + tool.Description
+		}
+	}
+}
+
+// AccessConfig 工具访问控制配置
+type AccessConfig struct {
+	ReadOnly bool   // 是否启用只读模式
+	Behavior string // 只读模式下处理写工具的方式: hide 或 reject
+}
+
+// readOnlyMiddleware 只读模式下拒绝调用写工具
+func readOnlyMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if ToolAccessOf(request.Params.Name) == AccessWrite {
+			fmt.Println("只读模式，已拒绝调用写工具:", request.Params.Name)
+			return mcp.NewToolResultError(fmt.Sprintf("服务器运行在只读模式，不允许调用会修改资源的工具 %s，请联系管理员执行该操作", request.Params.Name)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// hideWriteTools 从tools/list的结果中移除写工具
+func hideWriteTools(ctx context.Context, id any, message *mcp.ListToolsRequest, result *mcp.ListToolsResult) {
+	tools := result.Tools[:0]
+	for _, tool := range result.Tools {
+		if ToolAccessOf(tool.Name) == AccessRead {
+			tools = append(tools, tool)
+		}
+	}
+	result.Tools = tools
+}
+
+// accessOptions 根据访问控制配置生成服务器选项，在共享的hooks上标记写工具，hide方式同时注册工具列表过滤
+func accessOptions(cfg AccessConfig, hooks *server.Hooks) []server.ServerOption {
+	hooks.AddAfterListTools(markWriteTools)
+	if !cfg.ReadOnly {
+		return nil
+	}
+
+	fmt.Printf("服务器运行在只读模式，写工具处理方式: %s\n", cfg.Behavior)
+	if cfg.Behavior == ReadOnlyHide {
+		hooks.AddAfterListTools(hideWriteTools)
+	}
+	return []server.ServerOption{server.WithToolHandlerMiddleware(readOnlyMiddleware)}
+}
@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// listTools 通过tools/list请求获取服务器返回的工具列表
+func listTools(t *testing.T, svr *server.MCPServer) []mcp.Tool {
+	t.Helper()
+	message := svr.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	response, ok := message.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("tools/list 返回 %#v", message)
+	}
+	result, ok := response.Result.(mcp.ListToolsResult)
+	if !ok {
+		t.Fatalf("tools/list 结果类型为 %T", response.Result)
+	}
+	return result.Tools
+}
+
+// newAccessTestServer 创建注册了toolAccess中全部工具以及一个未分类工具的服务器
+func newAccessTestServer(cfg AccessConfig) *server.MCPServer {
+	hooks := &server.Hooks{}
+	opts := append([]server.ServerOption{server.WithHooks(hooks)}, accessOptions(cfg, hooks)...)
+	svr := server.NewMCPServer("test", mcp.LATEST_PROTOCOL_VERSION, opts...)
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	for name := range toolAccess {
+		svr.AddTool(mcp.NewTool(name, mcp.WithDescription("描述")), handler)
+	}
+	svr.AddTool(mcp.NewTool("unclassified_tool", mcp.WithDescription("描述")), handler)
+	return svr
+}
+
+func TestWriteToolsAreMarked(t *testing.T) {
+	tools := listTools(t, newAccessTestServer(AccessConfig{}))
+	if len(tools) != len(toolAccess)+1 {
+		t.Fatalf("工具数量 = %d, want %d", len(tools), len(toolAccess)+1)
+	}
+	for _, tool := range tools {
+		marked := strings.HasPrefix(tool.Description, WriteToolMarker)
+		if write := ToolAccessOf(tool.Name) == AccessWrite; marked != write {
+			t.Errorf("工具 %s 的描述 %q 标记为写工具 = %v, want %v", tool.Name, tool.Description, marked, write)
+		}
+		if strings.Count(tool.Description, WriteToolMarker) > 1 {
+			t.Errorf("工具 %s 的描述重复标记: %q", tool.Name, tool.Description)
+		}
+	}
+}
+
+func TestReadOnlyHideWriteTools(t *testing.T) {
+	tools := listTools(t, newAccessTestServer(AccessConfig{ReadOnly: true, Behavior: ReadOnlyHide}))
+	for _, tool := range tools {
+		if ToolAccessOf(tool.Name) == AccessWrite {
+			t.Errorf("只读模式下不应返回写工具 %s", tool.Name)
+		}
+	}
+}
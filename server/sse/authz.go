@@ -0,0 +1,162 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"mcp-devops/server/auth"
+	"mcp-devops/server/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// namespaceArgs 工具中表示命名空间的参数名
+var namespaceArgs = []string{"namespace", "namespace_name"}
+
+// sshHostArg Linux工具中表示SSH目标主机的参数名，未提供时在服务器本机执行
+const sshHostArg = "hostname"
+
+// localHost 未指定主机时用于匹配策略的主机名
+const localHost = "localhost"
+
+// namespaceParam 工具的命名空间参数及其默认值
+type namespaceParam struct {
+	name         string
+	defaultValue string
+}
+
+// authorizer 按授权策略检查每次工具调用的工具、命名空间和SSH主机
+type authorizer struct {
+	policy   *auth.Policy
+	params   map[string]namespaceParam // 带命名空间参数的工具
+	sshTools map[string]bool           // 通过SSH访问主机的工具
+}
+
+// newAuthorizer 创建授权检查器，未配置策略时返回nil
+func newAuthorizer(policy *auth.Policy) *authorizer {
+	if policy == nil {
+		return nil
+	}
+	return &authorizer{
+		policy:   policy,
+		params:   make(map[string]namespaceParam),
+		sshTools: make(map[string]bool),
+	}
+}
+
+// registerTool 记录工具的命名空间参数，未传参时按参数默认值检查
+func (a *authorizer) registerTool(tool mcp.Tool) {
+	if a == nil {
+		return
+	}
+	if _, ok := tool.InputSchema.Properties[sshHostArg]; ok {
+		a.sshTools[tool.Name] = true
+	}
+	for _, name := range namespaceArgs {
+		property, ok := tool.InputSchema.Properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		param := namespaceParam{name: name}
+		param.defaultValue, _ = property["default"].(string)
+		a.params[tool.Name] = param
+		return
+	}
+}
+
+// principal 获取调用方对应的授权规则
+func (a *authorizer) principal(ctx context.Context) (auth.Principal, string, bool) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return auth.Principal{}, "", false
+	}
+	principal, ok := a.policy.Lookup(identity)
+	return principal, identity.Name, ok
+}
+
+// check 检查调用方是否可以使用指定参数调用工具，返回拒绝原因
+func (a *authorizer) check(ctx context.Context, request mcp.CallToolRequest) string {
+	name := request.Params.Name
+	principal, caller, ok := a.principal(ctx)
+	if !ok {
+		if caller == "" {
+			return "无法识别调用方身份"
+		}
+		return fmt.Sprintf("调用方 %s 没有配置授权策略", caller)
+	}
+
+	if !principal.AllowTool(name) {
+		return fmt.Sprintf("调用方 %s 无权调用工具 %s", caller, name)
+	}
+	if principal.ReadOnly && ToolAccessOf(name) == AccessWrite {
+		return fmt.Sprintf("调用方 %s 只允许调用只读工具，无权调用 %s", caller, name)
+	}
+
+	// 跨所有命名空间查询时忽略namespace参数，要求策略允许所有命名空间
+	if allNamespaces, _ := request.Params.Arguments[k8s.AllNamespacesArg].(bool); allNamespaces {
+		if !principal.AllowAllNamespaces() {
+			return fmt.Sprintf("调用方 %s 无权查询所有命名空间", caller)
+		}
+	} else if param, ok := a.params[name]; ok {
+		namespace, _ := request.Params.Arguments[param.name].(string)
+		if namespace == "" {
+			namespace = param.defaultValue
+		}
+		if namespace != "" && !principal.AllowNamespace(namespace) {
+			return fmt.Sprintf("调用方 %s 无权操作命名空间 %s", caller, namespace)
+		}
+	}
+
+	if a.sshTools[name] {
+		host, _ := request.Params.Arguments[sshHostArg].(string)
+		if host == "" {
+			host = localHost
+		}
+		if !principal.AllowSSHHost(host) {
+			return fmt.Sprintf("调用方 %s 无权访问主机 %s", caller, host)
+		}
+	}
+	return ""
+}
+
+// Middleware 拒绝策略不允许的工具调用
+func (a *authorizer) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if reason := a.check(ctx, request); reason != "" {
+			fmt.Println("授权策略拒绝调用:", reason)
+			return mcp.NewToolResultError(fmt.Sprintf("授权策略拒绝了本次调用: %s", reason)), nil
+		}
+		// 通用资源工具解析出资源类型后才知道是否为集群级资源，由工具按此标记拒绝
+		if principal, _, _ := a.principal(ctx); !principal.AllowAllNamespaces() {
+			ctx = k8s.WithNamespaceRestriction(ctx)
+		}
+		return next(ctx, request)
+	}
+}
+
+// hideForbiddenTools 从tools/list的结果中移除调用方无权调用的工具
+func (a *authorizer) hideForbiddenTools(ctx context.Context, id any, message *mcp.ListToolsRequest, result *mcp.ListToolsResult) {
+	principal, _, ok := a.principal(ctx)
+	tools := result.Tools[:0]
+	for _, tool := range result.Tools {
+		if !ok || !principal.AllowTool(tool.Name) {
+			continue
+		}
+		if principal.ReadOnly && ToolAccessOf(tool.Name) == AccessWrite {
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	result.Tools = tools
+}
+
+// options 生成授权相关的服务器选项，并在共享的hooks上注册工具列表过滤
+func (a *authorizer) options(hooks *server.Hooks) []server.ServerOption {
+	if a == nil {
+		return nil
+	}
+
+	fmt.Printf("授权策略已启用，共 %d 个调用方\n", len(a.policy.Principals))
+	hooks.AddAfterListTools(a.hideForbiddenTools)
+	return []server.ServerOption{server.WithToolHandlerMiddleware(a.Middleware)}
+}
@@ -0,0 +1,150 @@
+package sse
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mcp-devops/server/auth"
+	"mcp-devops/server/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newTestAuthorizer 创建登记了常用工具的授权检查器
+func newTestAuthorizer(policy *auth.Policy) *authorizer {
+	a := newAuthorizer(policy)
+	a.registerTool(mcp.NewTool("list_pods",
+		mcp.WithString("namespace", mcp.DefaultString("default")),
+		k8s.WithAllNamespacesArgument(),
+	))
+	a.registerTool(mcp.NewTool("delete_pod",
+		mcp.WithString("namespace", mcp.DefaultString("default")),
+	))
+	a.registerTool(mcp.NewTool("describe_namespace",
+		mcp.WithString("namespace_name", mcp.Required()),
+	))
+	a.registerTool(mcp.NewTool("system_info",
+		mcp.WithString(sshHostArg),
+	))
+	return a
+}
+
+// callRequest 构造工具调用请求
+func callRequest(name string, args map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = args
+	return request
+}
+
+// asCaller 返回带有调用方身份的上下文
+func asCaller(name string) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{Name: name, Method: auth.MethodToken})
+}
+
+func TestAuthorizerCheck(t *testing.T) {
+	policy := &auth.Policy{
+		Principals: []auth.Principal{
+			{Name: "admin", Tools: []string{"*"}, Namespaces: []string{"*"}, SSHHosts: []string{"*"}},
+			{Name: "payments", Tools: []string{"*"}, Namespaces: []string{"payments", "payments-*"}, SSHHosts: []string{"web-*"}},
+			{Name: "viewer", ReadOnly: true, Tools: []string{"*"}, Namespaces: []string{"*"}},
+			{Name: "lister", Tools: []string{"list_*"}, Namespaces: []string{"*"}},
+		},
+		Default: &auth.Principal{Tools: []string{"list_pods"}, Namespaces: []string{"default"}},
+	}
+	a := newTestAuthorizer(policy)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		tool   string
+		args   map[string]interface{}
+		reject string // 为空表示允许，否则为拒绝原因中包含的文本
+	}{
+		{"没有身份", context.Background(), "list_pods", nil, "无法识别调用方身份"},
+		{"管理员任意命名空间", asCaller("admin"), "delete_pod", map[string]interface{}{"namespace": "kube-system"}, ""},
+		{"允许的命名空间", asCaller("payments"), "delete_pod", map[string]interface{}{"namespace": "payments-prod"}, ""},
+		{"不允许的命名空间", asCaller("payments"), "delete_pod", map[string]interface{}{"namespace": "kube-system"}, "无权操作命名空间 kube-system"},
+		{"未传命名空间时按默认值检查", asCaller("payments"), "delete_pod", nil, "无权操作命名空间 default"},
+		{"namespace_name参数", asCaller("payments"), "describe_namespace", map[string]interface{}{"namespace_name": "kube-system"}, "无权操作命名空间 kube-system"},
+		{"all_namespaces需要*", asCaller("payments"), "list_pods", map[string]interface{}{"namespace": "payments", k8s.AllNamespacesArg: true}, "无权查询所有命名空间"},
+		{"all_namespaces允许*", asCaller("admin"), "list_pods", map[string]interface{}{k8s.AllNamespacesArg: true}, ""},
+		{"只读调用方调用只读工具", asCaller("viewer"), "list_pods", map[string]interface{}{"namespace": "kube-system"}, ""},
+		{"只读调用方调用写工具", asCaller("viewer"), "delete_pod", map[string]interface{}{"namespace": "default"}, "只允许调用只读工具"},
+		{"工具不在允许列表", asCaller("lister"), "delete_pod", nil, "无权调用工具 delete_pod"},
+		{"未配置的身份使用default规则", asCaller("someone"), "list_pods", nil, ""},
+		{"default规则限制工具", asCaller("someone"), "delete_pod", nil, "无权调用工具 delete_pod"},
+		{"default规则限制命名空间", asCaller("someone"), "list_pods", map[string]interface{}{"namespace": "payments"}, "无权操作命名空间 payments"},
+		{"允许的SSH主机", asCaller("payments"), "system_info", map[string]interface{}{sshHostArg: "web-1"}, ""},
+		{"未知的SSH主机", asCaller("payments"), "system_info", map[string]interface{}{sshHostArg: "db-1"}, "无权访问主机 db-1"},
+		{"未指定主机时按本机检查", asCaller("payments"), "system_info", nil, "无权访问主机 localhost"},
+		{"管理员访问任意主机", asCaller("admin"), "system_info", map[string]interface{}{sshHostArg: "db-1:22"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := a.check(tt.ctx, callRequest(tt.tool, tt.args))
+			if tt.reject == "" && reason != "" {
+				t.Errorf("check() 拒绝: %s", reason)
+			}
+			if tt.reject != "" && !strings.Contains(reason, tt.reject) {
+				t.Errorf("check() = %q, want 包含 %q", reason, tt.reject)
+			}
+		})
+	}
+}
+
+func TestAuthorizerWithoutDefault(t *testing.T) {
+	a := newTestAuthorizer(&auth.Policy{Principals: []auth.Principal{{Name: "admin", Tools: []string{"*"}}}})
+	reason := a.check(asCaller("someone"), callRequest("list_pods", nil))
+	if !strings.Contains(reason, "没有配置授权策略") {
+		t.Errorf("check() = %q, want 没有配置授权策略", reason)
+	}
+}
+
+func TestHideForbiddenTools(t *testing.T) {
+	a := newTestAuthorizer(&auth.Policy{Principals: []auth.Principal{{Name: "viewer", ReadOnly: true, Tools: []string{"list_*", "delete_*"}}}})
+	result := &mcp.ListToolsResult{Tools: []mcp.Tool{
+		mcp.NewTool("list_pods"), mcp.NewTool("delete_pod"), mcp.NewTool("system_info"),
+	}}
+	a.hideForbiddenTools(asCaller("viewer"), 1, nil, result)
+	if len(result.Tools) != 1 || result.Tools[0].Name != "list_pods" {
+		t.Errorf("只读调用方可见的工具 = %v, want [list_pods]", result.Tools)
+	}
+
+	result = &mcp.ListToolsResult{Tools: []mcp.Tool{mcp.NewTool("list_pods")}}
+	a.hideForbiddenTools(asCaller("unknown"), 1, nil, result)
+	if len(result.Tools) != 0 {
+		t.Errorf("未配置策略的调用方不应看到任何工具, got %v", result.Tools)
+	}
+}
+
+func TestMiddlewareRestrictsClusterScope(t *testing.T) {
+	a := newTestAuthorizer(&auth.Policy{Principals: []auth.Principal{
+		{Name: "admin", Tools: []string{"*"}, Namespaces: []string{"*"}},
+		{Name: "payments", Tools: []string{"*"}, Namespaces: []string{"default", "payments-*"}},
+	}})
+
+	tests := []struct {
+		caller         string
+		wantRestricted bool
+	}{
+		{"admin", false},
+		{"payments", true},
+	}
+	for _, tt := range tests {
+		var restricted, called bool
+		handler := a.Middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			called, restricted = true, k8s.NamespaceRestricted(ctx)
+			return mcp.NewToolResultText("ok"), nil
+		})
+		// namespace参数为default时命名空间检查通过，集群级资源由通用资源工具按标记拒绝
+		if _, err := handler(asCaller(tt.caller), callRequest("delete_pod", map[string]interface{}{"namespace": "default"})); err != nil || !called {
+			t.Fatalf("%s: 调用被拒绝, err = %v", tt.caller, err)
+		}
+		if restricted != tt.wantRestricted {
+			t.Errorf("%s: NamespaceRestricted = %v, want %v", tt.caller, restricted, tt.wantRestricted)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sse
+
+import (
+	"mcp-devops/server/audit"
+	"mcp-devops/server/auth"
+	"mcp-devops/server/config"
+	"mcp-devops/server/k8s"
+	"mcp-devops/server/linux"
+	"mcp-devops/server/loki"
+	"mcp-devops/server/redis"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// accessConfig 从服务器配置中取出只读模式配置
+func accessConfig(cfg *config.Config) AccessConfig {
+	return AccessConfig{
+		ReadOnly: cfg.Access.ReadOnly,
+		Behavior: cfg.Access.ReadOnlyBehavior,
+	}
+}
+
+// auditConfig 从服务器配置中取出审计日志配置，路径为 off 时关闭审计
+func auditConfig(cfg *config.Config) audit.Config {
+	auditCfg := audit.Config{
+		MaxSize:    int64(cfg.Audit.MaxSizeMB) << 20,
+		MaxBackups: cfg.Audit.MaxBackups,
+	}
+	if cfg.Audit.AuditEnabled() {
+		auditCfg.Path = cfg.Audit.Path
+	}
+	return auditCfg
+}
+
+// impersonationConfig 从服务器配置中取出身份模拟配置
+func impersonationConfig(cfg *config.Config) ImpersonationConfig {
+	return ImpersonationConfig{
+		Enabled:    cfg.Auth.Impersonate.Enabled,
+		UserPrefix: cfg.Auth.Impersonate.UserPrefix,
+		Groups:     cfg.Auth.Impersonate.Groups,
+	}
+}
+
+// clientOptions 从服务器配置中取出Kubernetes客户端参数
+func clientOptions(cfg *config.Config) k8s.ClientOptions {
+	return k8s.ClientOptions{
+		Kubeconfig: strings.Join(cfg.Kubernetes.Kubeconfig, string(filepath.ListSeparator)),
+		QPS:        cfg.Kubernetes.QPS,
+		Burst:      cfg.Kubernetes.Burst,
+		Timeout:    time.Duration(cfg.Kubernetes.Timeout),
+	}
+}
+
+// AuthConfig 根据服务器配置生成HTTP端点的认证配置，并加载授权策略
+func AuthConfig(cfg *config.Config) (auth.Config, error) {
+	authCfg := auth.Config{
+		Token:        cfg.Auth.Token,
+		TLSCertFile:  cfg.Auth.TLSCert,
+		TLSKeyFile:   cfg.Auth.TLSKey,
+		ClientCAFile: cfg.Auth.TLSClientCA,
+	}
+	policy, err := loadPolicy(cfg)
+	if err != nil {
+		return authCfg, err
+	}
+	authCfg.Policy = policy
+	return authCfg, nil
+}
+
+// loadPolicy 加载授权策略文件，未配置时返回nil
+func loadPolicy(cfg *config.Config) (*auth.Policy, error) {
+	if cfg.Auth.PolicyFile == "" {
+		return nil, nil
+	}
+	return auth.LoadPolicy(cfg.Auth.PolicyFile)
+}
+
+// ApplyRuntimeConfig 应用可以在运行时更新的配置：SSH主机清单、Redis实例、Loki地址和通知渠道
+// 启动时和收到SIGHUP重新加载配置后调用
+func ApplyRuntimeConfig(cfg *config.Config) {
+	linux.ConfigureSSH(cfg.SSH)
+	redis.Configure(cfg.Redis)
+	loki.Configure(cfg.Loki)
+	k8s.ConfigureWeChat(cfg.Notifiers.WeChat)
+}
@@ -0,0 +1,206 @@
+package sse
+
+import (
+	"fmt"
+	"mcp-devops/server/config"
+	"mcp-devops/server/k8s"
+	"mcp-devops/server/loki"
+	"mcp-devops/server/redis"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeTimeout 启动时探测单个后端的最长等待时间
+const probeTimeout = 10 * time.Second
+
+// toolGroup 工具所属的分组，未登记的工具（如审计查询）不属于任何分组，始终注册
+var toolGroup = map[string]string{
+	// 集群与资源管理
+	"list_clusters":        config.GroupK8sCore,
+	"list_pods":            config.GroupK8sCore,
+	"describe_pod":         config.GroupK8sCore,
+	"pod_logs":             config.GroupK8sCore,
+	"delete_pod":           config.GroupK8sCore,
+	"list_deployments":     config.GroupK8sCore,
+	"describe_deployment":  config.GroupK8sCore,
+	"scale_deployment":     config.GroupK8sCore,
+	"restart_deployment":   config.GroupK8sCore,
+	"delete_deployment":    config.GroupK8sCore,
+	"list_daemonsets":      config.GroupK8sCore,
+	"describe_daemonset":   config.GroupK8sCore,
+	"restart_daemonset":    config.GroupK8sCore,
+	"delete_daemonset":     config.GroupK8sCore,
+	"list_statefulsets":    config.GroupK8sCore,
+	"describe_statefulset": config.GroupK8sCore,
+	"scale_statefulset":    config.GroupK8sCore,
+	"restart_statefulset":  config.GroupK8sCore,
+	"delete_statefulset":   config.GroupK8sCore,
+	"list_services":        config.GroupK8sCore,
+	"describe_service":     config.GroupK8sCore,
+	"modify_service_type":  config.GroupK8sCore,
+	"list_namespaces":      config.GroupK8sCore,
+	"describe_namespace":   config.GroupK8sCore,
+	"create_namespace":     config.GroupK8sCore,
+	"delete_namespace":     config.GroupK8sCore,
+	"list_ingresses":       config.GroupK8sCore,
+	"describe_ingress":     config.GroupK8sCore,
+	"create_ingress":       config.GroupK8sCore,
+	"update_ingress":       config.GroupK8sCore,
+	"delete_ingress":       config.GroupK8sCore,
+	"list_configmaps":      config.GroupK8sCore,
+	"describe_configmap":   config.GroupK8sCore,
+	"create_configmap":     config.GroupK8sCore,
+	"update_configmap":     config.GroupK8sCore,
+	"delete_configmap":     config.GroupK8sCore,
+	"list_secrets":         config.GroupK8sCore,
+	"describe_secret":      config.GroupK8sCore,
+	"create_secret":        config.GroupK8sCore,
+	"update_secret":        config.GroupK8sCore,
+	"delete_secret":        config.GroupK8sCore,
+	"list_jobs":            config.GroupK8sCore,
+	"describe_job":         config.GroupK8sCore,
+	"delete_job":           config.GroupK8sCore,
+	"list_cronjobs":        config.GroupK8sCore,
+	"describe_cronjob":     config.GroupK8sCore,
+	"delete_cronjob":       config.GroupK8sCore,
+	"trigger_cronjob":      config.GroupK8sCore,
+	"suspend_cronjob":      config.GroupK8sCore,
+	"resume_cronjob":       config.GroupK8sCore,
+	"rollout_history":      config.GroupK8sCore,
+	"rollout_undo":         config.GroupK8sCore,
+	"rollout_pause":        config.GroupK8sCore,
+	"rollout_resume":       config.GroupK8sCore,
+	"rollout_status":       config.GroupK8sCore,
+	"set_image":            config.GroupK8sCore,
+	"set_resources":        config.GroupK8sCore,
+	"set_env":              config.GroupK8sCore,
+	"get_resource":         config.GroupK8sCore,
+	"list_resources":       config.GroupK8sCore,
+	"describe_resource":    config.GroupK8sCore,
+	"delete_resource":      config.GroupK8sCore,
+	"apply_manifest":       config.GroupK8sCore,
+	"diff_manifest":        config.GroupK8sCore,
+
+	// 故障诊断
+	"cluster_health":        config.GroupK8sTroubleshoot,
+	"pod_diagnostic":        config.GroupK8sTroubleshoot,
+	"node_diagnostic":       config.GroupK8sTroubleshoot,
+	"deployment_diagnostic": config.GroupK8sTroubleshoot,
+	"job_diagnostic":        config.GroupK8sTroubleshoot,
+	"alert_analysis":        config.GroupK8sTroubleshoot,
+
+	// Linux系统与节点组件
+	"system_info":              config.GroupLinux,
+	"process_info":             config.GroupLinux,
+	"resource_usage":           config.GroupLinux,
+	"network_info":             config.GroupLinux,
+	"log_analysis":             config.GroupLinux,
+	"service_status":           config.GroupLinux,
+	"kubelet_status":           config.GroupLinux,
+	"container_runtime_status": config.GroupLinux,
+	"kube_proxy_status":        config.GroupLinux,
+	"node_network_debug":       config.GroupLinux,
+	"cni_status":               config.GroupLinux,
+	"kube_component_logs":      config.GroupLinux,
+	"container_inspect":        config.GroupLinux,
+
+	// 通知
+	"send_wechat_message": config.GroupNotify,
+
+	// Redis诊断
+	"redis_info":            config.GroupRedis,
+	"redis_slowlog":         config.GroupRedis,
+	"redis_bigkeys":         config.GroupRedis,
+	"redis_hotkeys":         config.GroupRedis,
+	"redis_monitor":         config.GroupRedis,
+	"redis_latency":         config.GroupRedis,
+	"redis_latency_history": config.GroupRedis,
+	"redis_stat":            config.GroupRedis,
+
+	// Loki日志
+	"loki_service_logs":    config.GroupLoki,
+	"loki_time_range_logs": config.GroupLoki,
+}
+
+// toolGroups 启动时确定的各分组启用状态
+type toolGroups map[string]bool
+
+// enabled 工具是否需要注册
+func (g toolGroups) enabled(tool string) bool {
+	group, ok := toolGroup[tool]
+	return !ok || g[group]
+}
+
+// groupEnabled 分组是否启用
+func (g toolGroups) groupEnabled(group string) bool {
+	return g[group]
+}
+
+// String 按名称排序列出启用的分组
+func (g toolGroups) String() string {
+	var names []string
+	for group, enabled := range g {
+		if enabled {
+			names = append(names, group)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// resolveToolGroups 根据配置确定启用的分组：显式配置优先，其余分组在启用探测时检查后端是否可达
+func resolveToolGroups(cfg config.ToolsConfig, clusters *k8s.ClusterManager) toolGroups {
+	probes := map[string]func() error{
+		config.GroupK8sCore:         clusters.Ping,
+		config.GroupK8sTroubleshoot: clusters.Ping,
+		config.GroupRedis:           redis.Ping,
+		config.GroupLoki:            loki.Ping,
+	}
+
+	groups := make(toolGroups, len(config.ToolGroups))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, group := range config.ToolGroups {
+		if enabled, explicit := cfg.GroupSetting(group); explicit {
+			groups[group] = enabled
+			continue
+		}
+		probe, ok := probes[group]
+		if !ok || !cfg.ProbeBackends {
+			groups[group] = true
+			continue
+		}
+
+		wg.Add(1)
+		go func(group string, probe func() error) {
+			defer wg.Done()
+			err := probeWithTimeout(probe)
+			if err != nil {
+				fmt.Printf("工具分组 %s 的后端不可达，已自动关闭: %v\n", group, err)
+			}
+			mu.Lock()
+			groups[group] = err == nil
+			mu.Unlock()
+		}(group, probe)
+	}
+	wg.Wait()
+	return groups
+}
+
+// probeWithTimeout 执行探测，超时视为不可达
+func probeWithTimeout(probe func() error) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- probe()
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(probeTimeout):
+		return fmt.Errorf("探测超时(%s)", probeTimeout)
+	}
+}
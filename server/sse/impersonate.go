@@ -0,0 +1,64 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"mcp-devops/server/auth"
+	"mcp-devops/server/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/client-go/rest"
+)
+
+// ImpersonationConfig Kubernetes身份模拟配置
+type ImpersonationConfig struct {
+	Enabled    bool     // 是否以调用方身份访问Kubernetes
+	UserPrefix string   // 由调用方名称生成Kubernetes用户名时添加的前缀
+	Groups     []string // 模拟用户所属的组
+}
+
+// impersonator 把通过认证的调用方映射为Kubernetes用户和组
+type impersonator struct {
+	cfg    ImpersonationConfig
+	policy *auth.Policy
+}
+
+// impersonation 计算调用方对应的模拟身份，授权策略中配置的kube_user和kube_groups优先
+func (i impersonator) impersonation(identity auth.Identity) rest.ImpersonationConfig {
+	impersonate := rest.ImpersonationConfig{
+		UserName: i.cfg.UserPrefix + identity.Name,
+		Groups:   i.cfg.Groups,
+	}
+	if principal, ok := i.policy.Lookup(identity); ok {
+		if principal.KubeUser != "" {
+			impersonate.UserName = principal.KubeUser
+		}
+		if len(principal.KubeGroups) > 0 {
+			impersonate.Groups = principal.KubeGroups
+		}
+	}
+	return impersonate
+}
+
+// Middleware 把模拟身份注入到上下文中，无法识别调用方时拒绝调用，避免退回到服务器自身的凭据
+func (i impersonator) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		identity, ok := auth.IdentityFromContext(ctx)
+		if !ok {
+			return mcp.NewToolResultError("服务器启用了Kubernetes身份模拟，但无法识别调用方身份，请配置 MCP_API_TOKEN 或授权策略"), nil
+		}
+		return next(k8s.WithImpersonation(ctx, i.impersonation(identity)), request)
+	}
+}
+
+// impersonationOptions 根据身份模拟配置生成服务器选项
+func impersonationOptions(cfg ImpersonationConfig, policy *auth.Policy) []server.ServerOption {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	fmt.Printf("已启用Kubernetes身份模拟，用户名前缀: %q，组: %v\n", cfg.UserPrefix, cfg.Groups)
+	i := impersonator{cfg: cfg, policy: policy}
+	return []server.ServerOption{server.WithToolHandlerMiddleware(i.Middleware)}
+}
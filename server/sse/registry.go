@@ -0,0 +1,36 @@
+package sse
+
+import (
+	"mcp-devops/server/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolServer 注册工具时同时登记到授权检查器，记录工具的命名空间和主机参数
+// 所属分组未启用的工具不会注册
+type toolServer struct {
+	*server.MCPServer
+	authz  *authorizer
+	groups toolGroups
+}
+
+// AddTool 登记并注册工具
+func (s toolServer) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if !s.groups.enabled(tool.Name) {
+		return
+	}
+	s.authz.registerTool(tool)
+	s.MCPServer.AddTool(tool, handler)
+}
+
+// k8sToolServer 注册Kubernetes工具时自动追加cluster参数，使每个工具都可以指定目标集群
+type k8sToolServer struct {
+	toolServer
+}
+
+// AddTool 追加cluster参数后注册工具
+func (s k8sToolServer) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	k8s.WithClusterArgument()(&tool)
+	s.toolServer.AddTool(tool, handler)
+}
@@ -1,27 +1,91 @@
 package sse
 
 import (
+	"fmt"
+	"mcp-devops/server/audit"
+	"mcp-devops/server/config"
 	"mcp-devops/server/k8s"
 	"mcp-devops/server/linux"
 	"mcp-devops/server/loki"
+	"mcp-devops/server/metrics"
 	"mcp-devops/server/redis"
+	"mcp-devops/server/tracing"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func K8sServer() (*server.MCPServer, error) {
-	svr := server.NewMCPServer("Kubernetes MCP Server", mcp.LATEST_PROTOCOL_VERSION)
+// K8sServer 根据配置创建MCP服务器并注册所有工具
+func K8sServer(cfg *config.Config) (*server.MCPServer, error) {
+	// 读取访问控制配置，只读模式下隐藏或拒绝写工具
+	access := accessConfig(cfg)
+
+	// 打开审计日志，记录每次工具调用
+	auditLog, err := audit.NewLogger(auditConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	// 读取授权策略，按调用方限制工具、命名空间和SSH主机
+	policy, err := loadPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	authz := newAuthorizer(policy)
+
+	// 读取身份模拟配置，启用后以调用方身份访问Kubernetes，使RBAC和审计日志反映真实操作者
+	impersonation := impersonationConfig(cfg)
+
+	// 应用SSH主机清单、Redis实例、Loki地址和通知渠道
+	ApplyRuntimeConfig(cfg)
+
+	// 构建服务器级别共享的多集群客户端管理器，通过中间件注入到每次工具调用中
+	clusters := k8s.NewClusterManager(clientOptions(cfg))
+	// 确定启用的工具分组，只注册启用分组中的工具，减少模型需要理解的工具数量
+	groups := resolveToolGroups(cfg.Tools, clusters)
+	fmt.Println("已启用的工具分组:", groups)
+	// 只读模式和授权策略都需要过滤工具列表，共用同一组hooks
+	hooks := &server.Hooks{}
+	opts := []server.ServerOption{server.WithHooks(hooks)}
+	// 追踪、指标和审计中间件放在最外层，被拒绝的调用同样会被追踪、统计和记录
+	opts = append(opts, server.WithToolHandlerMiddleware(tracing.Middleware))
+	opts = append(opts, server.WithToolHandlerMiddleware(metrics.Middleware))
+	if auditLog != nil {
+		fmt.Printf("审计日志已启用，写入文件: %s\n", auditLog.Path())
+		opts = append(opts, server.WithToolHandlerMiddleware(auditLog.Middleware))
+	}
+	opts = append(opts, authz.options(hooks)...)
+	opts = append(opts, impersonationOptions(impersonation, policy)...)
+	opts = append(opts, server.WithToolHandlerMiddleware(k8s.WithClusterManager(clusters)))
+	opts = append(opts, accessOptions(access, hooks)...)
+	svr := server.NewMCPServer("Kubernetes MCP Server", mcp.LATEST_PROTOCOL_VERSION, opts...)
+	// 登记工具的命名空间和主机参数，供授权策略检查
+	toolSvr := toolServer{MCPServer: svr, authz: authz, groups: groups}
+	// Kubernetes工具统一追加cluster参数
+	k8sSvr := k8sToolServer{toolSvr}
 	// 添加tool， tool 三大要素，名称，描述，参数，参数中也要有描述
+	// 添加集群管理工具
+	toolSvr.AddTool(mcp.NewTool("list_clusters",
+		mcp.WithDescription("列出所有可用的Kubernetes集群（kubeconfig中的context）"),
+		mcp.WithBoolean("check_connectivity",
+			mcp.Description("是否检查各集群的连通性并显示版本"),
+			mcp.DefaultBool(false),
+		),
+		k8s.WithOutputArgument(),
+	), k8s.ListClustersTool)
+
 	// 添加kubernetes pod相关工具
-	svr.AddTool(mcp.NewTool("list_pods",
-		mcp.WithDescription("列出指定命名空间中的所有Pod"),
+	k8sSvr.AddTool(mcp.NewTool("list_pods",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的Pod，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListPodsTool)
-	svr.AddTool(mcp.NewTool("describe_pod",
+	k8sSvr.AddTool(mcp.NewTool("describe_pod",
 		mcp.WithDescription("查看pod的详细信息"),
 		mcp.WithString("pod_name",
 			mcp.Required(),
@@ -32,9 +96,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Required(),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DsscribePodTool)
 
-	svr.AddTool(mcp.NewTool("delete_pod",
+	k8sSvr.AddTool(mcp.NewTool("delete_pod",
 		mcp.WithDescription("删除指定的Pod"),
 		mcp.WithString("pod_name",
 			mcp.Required(),
@@ -48,9 +113,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("是否强制删除"),
 			mcp.DefaultBool(false),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.DeletePodTool)
 
-	svr.AddTool(mcp.NewTool("pod_logs",
+	k8sSvr.AddTool(mcp.NewTool("pod_logs",
 		mcp.WithDescription("获取Pod的日志"),
 		mcp.WithString("pod_name",
 			mcp.Required(),
@@ -70,15 +136,18 @@ func K8sServer() (*server.MCPServer, error) {
 	), k8s.PodLogsTool)
 
 	// 添加Kubernetes Deployment相关工具
-	svr.AddTool(mcp.NewTool("list_deployments",
-		mcp.WithDescription("列出指定命名空间中的所有Deployment"),
+	k8sSvr.AddTool(mcp.NewTool("list_deployments",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的Deployment，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListDeploymentsTool)
 
-	svr.AddTool(mcp.NewTool("describe_deployment",
+	k8sSvr.AddTool(mcp.NewTool("describe_deployment",
 		mcp.WithDescription("查看Deployment的详细信息"),
 		mcp.WithString("deployment_name",
 			mcp.Required(),
@@ -88,9 +157,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("Deployment所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeDeploymentTool)
 
-	svr.AddTool(mcp.NewTool("scale_deployment",
+	k8sSvr.AddTool(mcp.NewTool("scale_deployment",
 		mcp.WithDescription("调整Deployment的副本数"),
 		mcp.WithString("deployment_name",
 			mcp.Required(),
@@ -104,9 +174,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Required(),
 			mcp.Description("要设置的副本数"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.ScaleDeploymentTool)
 
-	svr.AddTool(mcp.NewTool("restart_deployment",
+	k8sSvr.AddTool(mcp.NewTool("restart_deployment",
 		mcp.WithDescription("重启Deployment的所有Pod"),
 		mcp.WithString("deployment_name",
 			mcp.Required(),
@@ -116,18 +187,36 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("Deployment所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.RestartDeploymentTool)
 
+	k8sSvr.AddTool(mcp.NewTool("delete_deployment",
+		mcp.WithDescription("删除Deployment，按级联策略处理其ReplicaSet和Pod，结果中会先列出受影响的依赖对象；可先设置dry_run预览"),
+		mcp.WithString("deployment_name",
+			mcp.Required(),
+			mcp.Description("要删除的Deployment名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Deployment所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithPropagationPolicyArgument(),
+		k8s.WithDryRunArgument(),
+	), k8s.DeleteDeploymentTool)
+
 	// 添加Kubernetes DaemonSet相关工具
-	svr.AddTool(mcp.NewTool("list_daemonsets",
-		mcp.WithDescription("列出指定命名空间中的所有DaemonSets"),
+	k8sSvr.AddTool(mcp.NewTool("list_daemonsets",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的DaemonSets，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListDaemonSetsTool)
 
-	svr.AddTool(mcp.NewTool("describe_daemonset",
+	k8sSvr.AddTool(mcp.NewTool("describe_daemonset",
 		mcp.WithDescription("查看DaemonSet的详细信息"),
 		mcp.WithString("daemonset_name",
 			mcp.Required(),
@@ -137,9 +226,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("DaemonSet所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeDaemonSetTool)
 
-	svr.AddTool(mcp.NewTool("restart_daemonset",
+	k8sSvr.AddTool(mcp.NewTool("restart_daemonset",
 		mcp.WithDescription("重启DaemonSet的所有Pod"),
 		mcp.WithString("daemonset_name",
 			mcp.Required(),
@@ -149,18 +239,36 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("DaemonSet所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.RestartDaemonSetTool)
 
+	k8sSvr.AddTool(mcp.NewTool("delete_daemonset",
+		mcp.WithDescription("删除DaemonSet，按级联策略处理其Pod和ControllerRevision，结果中会先列出受影响的依赖对象；可先设置dry_run预览"),
+		mcp.WithString("daemonset_name",
+			mcp.Required(),
+			mcp.Description("要删除的DaemonSet名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("DaemonSet所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithPropagationPolicyArgument(),
+		k8s.WithDryRunArgument(),
+	), k8s.DeleteDaemonSetTool)
+
 	// 添加Kubernetes StatefulSet相关工具
-	svr.AddTool(mcp.NewTool("list_statefulsets",
-		mcp.WithDescription("列出指定命名空间中的所有StatefulSet"),
+	k8sSvr.AddTool(mcp.NewTool("list_statefulsets",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的StatefulSet，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListStatefulSetsTool)
 
-	svr.AddTool(mcp.NewTool("describe_statefulset",
+	k8sSvr.AddTool(mcp.NewTool("describe_statefulset",
 		mcp.WithDescription("查看StatefulSet的详细信息"),
 		mcp.WithString("statefulset_name", // Use statefulset_name
 			mcp.Required(),
@@ -170,9 +278,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("StatefulSet所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeStatefulSetTool)
 
-	svr.AddTool(mcp.NewTool("scale_statefulset",
+	k8sSvr.AddTool(mcp.NewTool("scale_statefulset",
 		mcp.WithDescription("调整StatefulSet的副本数"),
 		mcp.WithString("statefulset_name",
 			mcp.Required(),
@@ -186,9 +295,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Required(),
 			mcp.Description("要设置的副本数"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.ScaleStatefulSetTool)
 
-	svr.AddTool(mcp.NewTool("restart_statefulset",
+	k8sSvr.AddTool(mcp.NewTool("restart_statefulset",
 		mcp.WithDescription("重启StatefulSet的所有Pod"),
 		mcp.WithString("statefulset_name",
 			mcp.Required(),
@@ -198,18 +308,40 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("StatefulSet所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.RestartStatefulSetTool)
 
+	k8sSvr.AddTool(mcp.NewTool("delete_statefulset",
+		mcp.WithDescription("删除StatefulSet，按级联策略处理其Pod和ControllerRevision，并列出由volumeClaimTemplates创建的PVC；PVC默认保留，可先设置dry_run预览"),
+		mcp.WithString("statefulset_name",
+			mcp.Required(),
+			mcp.Description("要删除的StatefulSet名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("StatefulSet所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithPropagationPolicyArgument(),
+		mcp.WithBoolean("delete_pvcs",
+			mcp.Description("是否同时删除由volumeClaimTemplates创建的PVC，删除后数据可能无法恢复，默认保留"),
+			mcp.DefaultBool(false),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.DeleteStatefulSetTool)
+
 	// 添加Kubernetes Service相关工具
-	svr.AddTool(mcp.NewTool("list_services",
-		mcp.WithDescription("列出指定命名空间中的所有Service"),
+	k8sSvr.AddTool(mcp.NewTool("list_services",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的Service，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListServicesTool)
 
-	svr.AddTool(mcp.NewTool("describe_service",
+	k8sSvr.AddTool(mcp.NewTool("describe_service",
 		mcp.WithDescription("查看Service的详细信息"),
 		mcp.WithString("service_name",
 			mcp.Required(),
@@ -219,9 +351,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("Service所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeServiceTool)
 
-	svr.AddTool(mcp.NewTool("modify_service_type",
+	k8sSvr.AddTool(mcp.NewTool("modify_service_type",
 		mcp.WithDescription("调整Service Type"),
 		mcp.WithString("service_name",
 			mcp.Required(),
@@ -236,47 +369,56 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("Service类型"),
 			mcp.DefaultString("ClusterIP"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.ModifyServiceTypeTool)
 
 	// 添加Kubernetes Namespace相关工具
-	svr.AddTool(mcp.NewTool("list_namespaces",
-		mcp.WithDescription("列出所有命名空间"),
+	k8sSvr.AddTool(mcp.NewTool("list_namespaces",
+		mcp.WithDescription("列出所有命名空间，支持标签/字段选择器和分页"),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListNamespacesTool)
 
-	svr.AddTool(mcp.NewTool("describe_namespace",
+	k8sSvr.AddTool(mcp.NewTool("describe_namespace",
 		mcp.WithDescription("查看命名空间的详细信息"),
 		mcp.WithString("namespace_name",
 			mcp.Required(),
 			mcp.Description("要查看的命名空间名称"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeNamespaceTool)
 
-	svr.AddTool(mcp.NewTool("create_namespace",
+	k8sSvr.AddTool(mcp.NewTool("create_namespace",
 		mcp.WithDescription("创建新的命名空间"),
 		mcp.WithString("namespace_name",
 			mcp.Required(),
 			mcp.Description("要创建的命名空间名称"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.CreateNamespaceTool)
 
-	svr.AddTool(mcp.NewTool("delete_namespace",
+	k8sSvr.AddTool(mcp.NewTool("delete_namespace",
 		mcp.WithDescription("删除指定的命名空间"),
 		mcp.WithString("namespace_name",
 			mcp.Required(),
 			mcp.Description("要删除的命名空间名称"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.DeleteNamespaceTool)
 
 	// 添加Kubernetes Ingress相关工具
-	svr.AddTool(mcp.NewTool("list_ingresses",
-		mcp.WithDescription("列出指定命名空间中的所有Ingress"),
+	k8sSvr.AddTool(mcp.NewTool("list_ingresses",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的Ingress，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListIngressesTool)
 
-	svr.AddTool(mcp.NewTool("describe_ingress",
+	k8sSvr.AddTool(mcp.NewTool("describe_ingress",
 		mcp.WithDescription("查看Ingress的详细信息"),
 		mcp.WithString("ingress_name",
 			mcp.Required(),
@@ -286,9 +428,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("Ingress所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeIngressTool)
 
-	svr.AddTool(mcp.NewTool("create_ingress",
+	k8sSvr.AddTool(mcp.NewTool("create_ingress",
 		mcp.WithDescription("创建新的Ingress"),
 		mcp.WithString("ingress_name",
 			mcp.Required(),
@@ -327,9 +470,10 @@ func K8sServer() (*server.MCPServer, error) {
 		mcp.WithString("tls_secret_name",
 			mcp.Description("TLS证书Secret名称, 如不提供则使用<ingress-name>-tls"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.CreateIngressTool)
 
-	svr.AddTool(mcp.NewTool("update_ingress",
+	k8sSvr.AddTool(mcp.NewTool("update_ingress",
 		mcp.WithDescription("更新现有的Ingress"),
 		mcp.WithString("ingress_name",
 			mcp.Required(),
@@ -363,9 +507,10 @@ func K8sServer() (*server.MCPServer, error) {
 		mcp.WithString("tls_secret_name",
 			mcp.Description("TLS证书Secret名称"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.UpdateIngressTool)
 
-	svr.AddTool(mcp.NewTool("delete_ingress",
+	k8sSvr.AddTool(mcp.NewTool("delete_ingress",
 		mcp.WithDescription("删除指定的Ingress"),
 		mcp.WithString("ingress_name",
 			mcp.Required(),
@@ -375,18 +520,22 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("Ingress所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.DeleteIngressTool)
 
 	// 添加Kubernetes ConfigMap相关工具
-	svr.AddTool(mcp.NewTool("list_configmaps",
-		mcp.WithDescription("列出指定命名空间中的所有ConfigMap"),
+	k8sSvr.AddTool(mcp.NewTool("list_configmaps",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的ConfigMap，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListConfigMapsTool)
 
-	svr.AddTool(mcp.NewTool("describe_configmap",
+	k8sSvr.AddTool(mcp.NewTool("describe_configmap",
 		mcp.WithDescription("查看ConfigMap的详细信息"),
 		mcp.WithString("configmap_name",
 			mcp.Required(),
@@ -396,9 +545,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("ConfigMap所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeConfigMapTool)
 
-	svr.AddTool(mcp.NewTool("create_configmap",
+	k8sSvr.AddTool(mcp.NewTool("create_configmap",
 		mcp.WithDescription("创建新的ConfigMap"),
 		mcp.WithString("configmap_name",
 			mcp.Required(),
@@ -423,9 +573,10 @@ func K8sServer() (*server.MCPServer, error) {
 		mcp.WithString("from_literal",
 			mcp.Description("从字面值创建ConfigMap，格式为key=value"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.CreateConfigMapTool)
 
-	svr.AddTool(mcp.NewTool("update_configmap",
+	k8sSvr.AddTool(mcp.NewTool("update_configmap",
 		mcp.WithDescription("更新现有的ConfigMap"),
 		mcp.WithString("configmap_name",
 			mcp.Required(),
@@ -450,9 +601,10 @@ func K8sServer() (*server.MCPServer, error) {
 		mcp.WithString("value",
 			mcp.Description("键对应的值"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.UpdateConfigMapTool)
 
-	svr.AddTool(mcp.NewTool("delete_configmap",
+	k8sSvr.AddTool(mcp.NewTool("delete_configmap",
 		mcp.WithDescription("删除指定的ConfigMap"),
 		mcp.WithString("configmap_name",
 			mcp.Required(),
@@ -462,18 +614,22 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("ConfigMap所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.DeleteConfigMapTool)
 
 	// 添加Kubernetes Secret相关工具
-	svr.AddTool(mcp.NewTool("list_secrets",
-		mcp.WithDescription("列出指定命名空间中的所有Secret"),
+	k8sSvr.AddTool(mcp.NewTool("list_secrets",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的Secret，支持标签/字段选择器和分页"),
 		mcp.WithString("namespace",
 			mcp.Description("要查询的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
 	), k8s.ListSecretsTool)
 
-	svr.AddTool(mcp.NewTool("describe_secret",
+	k8sSvr.AddTool(mcp.NewTool("describe_secret",
 		mcp.WithDescription("查看Secret的详细信息"),
 		mcp.WithString("secret_name",
 			mcp.Required(),
@@ -487,9 +643,10 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("是否显示敏感数据"),
 			mcp.DefaultBool(false),
 		),
+		k8s.WithOutputArgument(),
 	), k8s.DescribeSecretTool)
 
-	svr.AddTool(mcp.NewTool("create_secret",
+	k8sSvr.AddTool(mcp.NewTool("create_secret",
 		mcp.WithDescription("创建新的Secret"),
 		mcp.WithString("secret_name",
 			mcp.Required(),
@@ -521,9 +678,10 @@ func K8sServer() (*server.MCPServer, error) {
 		mcp.WithString("from_literal",
 			mcp.Description("从字面值创建Secret，格式为key=value"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.CreateSecretTool)
 
-	svr.AddTool(mcp.NewTool("update_secret",
+	k8sSvr.AddTool(mcp.NewTool("update_secret",
 		mcp.WithDescription("更新现有的Secret"),
 		mcp.WithString("secret_name",
 			mcp.Required(),
@@ -551,9 +709,10 @@ func K8sServer() (*server.MCPServer, error) {
 		mcp.WithString("value",
 			mcp.Description("键对应的值"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.UpdateSecretTool)
 
-	svr.AddTool(mcp.NewTool("delete_secret",
+	k8sSvr.AddTool(mcp.NewTool("delete_secret",
 		mcp.WithDescription("删除指定的Secret"),
 		mcp.WithString("secret_name",
 			mcp.Required(),
@@ -563,14 +722,320 @@ func K8sServer() (*server.MCPServer, error) {
 			mcp.Description("Secret所在的命名空间, 默认为default"),
 			mcp.DefaultString("default"),
 		),
+		k8s.WithDryRunArgument(),
 	), k8s.DeleteSecretTool)
 
+	// 添加Kubernetes Job和CronJob相关工具
+	k8sSvr.AddTool(mcp.NewTool("list_jobs",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的Job，支持标签/字段选择器和分页"),
+		mcp.WithString("namespace",
+			mcp.Description("要查询的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
+	), k8s.ListJobsTool)
+	k8sSvr.AddTool(mcp.NewTool("describe_job",
+		mcp.WithDescription("查看Job的详细信息，包括完成情况、条件和事件"),
+		mcp.WithString("job_name",
+			mcp.Required(),
+			mcp.Description("要查看的Job名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Job所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithOutputArgument(),
+	), k8s.DescribeJobTool)
+	k8sSvr.AddTool(mcp.NewTool("delete_job",
+		mcp.WithDescription("删除指定的Job，其创建的Pod在后台一并删除"),
+		mcp.WithString("job_name",
+			mcp.Required(),
+			mcp.Description("要删除的Job名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Job所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.DeleteJobTool)
+	k8sSvr.AddTool(mcp.NewTool("list_cronjobs",
+		mcp.WithDescription("列出指定命名空间或所有命名空间中的CronJob，支持标签/字段选择器和分页"),
+		mcp.WithString("namespace",
+			mcp.Description("要查询的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
+	), k8s.ListCronJobsTool)
+	k8sSvr.AddTool(mcp.NewTool("describe_cronjob",
+		mcp.WithDescription("查看CronJob的详细信息，包括调度计划、最近调度和成功时间、最近的Job和事件"),
+		mcp.WithString("cronjob_name",
+			mcp.Required(),
+			mcp.Description("要查看的CronJob名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("CronJob所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithOutputArgument(),
+	), k8s.DescribeCronJobTool)
+	k8sSvr.AddTool(mcp.NewTool("delete_cronjob",
+		mcp.WithDescription("删除指定的CronJob，其创建的Job和Pod在后台一并删除"),
+		mcp.WithString("cronjob_name",
+			mcp.Required(),
+			mcp.Description("要删除的CronJob名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("CronJob所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.DeleteCronJobTool)
+	k8sSvr.AddTool(mcp.NewTool("trigger_cronjob",
+		mcp.WithDescription("按CronJob的Job模板立即创建一个Job，等同于kubectl create job --from=cronjob/<name>"),
+		mcp.WithString("cronjob_name",
+			mcp.Required(),
+			mcp.Description("要触发的CronJob名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("CronJob所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithString("job_name",
+			mcp.Description("新建Job的名称，不提供则使用 <cronjob名称>-manual-<时间戳>"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.TriggerCronJobTool)
+	k8sSvr.AddTool(mcp.NewTool("suspend_cronjob",
+		mcp.WithDescription("暂停CronJob，暂停后不再按计划创建Job，正在运行的Job不受影响"),
+		mcp.WithString("cronjob_name",
+			mcp.Required(),
+			mcp.Description("要暂停的CronJob名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("CronJob所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.SuspendCronJobTool)
+	k8sSvr.AddTool(mcp.NewTool("resume_cronjob",
+		mcp.WithDescription("恢复已暂停的CronJob，使其继续按计划创建Job"),
+		mcp.WithString("cronjob_name",
+			mcp.Required(),
+			mcp.Description("要恢复的CronJob名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("CronJob所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.ResumeCronJobTool)
+
+	// 添加Kubernetes滚动更新相关工具，支持Deployment、StatefulSet和DaemonSet
+	k8sSvr.AddTool(mcp.NewTool("rollout_history",
+		mcp.WithDescription("查看工作负载的滚动更新历史，列出每个修订版本的镜像和变更原因（Deployment来自ReplicaSet，StatefulSet和DaemonSet来自ControllerRevision）"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithNumber("revision",
+			mcp.Description("查看指定修订版本的Pod模板详情，不提供则列出全部修订版本"),
+		),
+	), k8s.RolloutHistoryTool)
+	k8sSvr.AddTool(mcp.NewTool("rollout_undo",
+		mcp.WithDescription("把工作负载回滚到指定的修订版本，等同于kubectl rollout undo"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithNumber("to_revision",
+			mcp.Description("要回滚到的修订版本，不提供或为0时回滚到上一个版本"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.RolloutUndoTool)
+	k8sSvr.AddTool(mcp.NewTool("rollout_pause",
+		mcp.WithDescription("暂停工作负载的滚动更新，暂停后对Pod模板的修改不会更新现有Pod。Deployment设置spec.paused，StatefulSet把partition调到副本数，DaemonSet临时改为OnDelete策略"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.RolloutPauseTool)
+	k8sSvr.AddTool(mcp.NewTool("rollout_resume",
+		mcp.WithDescription("恢复被rollout_pause暂停的滚动更新，StatefulSet和DaemonSet还原暂停前的更新策略"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.RolloutResumeTool)
+	k8sSvr.AddTool(mcp.NewTool("rollout_status",
+		mcp.WithDescription("等待工作负载的滚动更新完成，持续输出已更新/就绪/可用副本数；Deployment超过progressDeadlineSeconds或等待超时则返回失败，并给出未就绪的Pod和相关事件。适合在扩缩容、重启、回滚或修改镜像后确认结果"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithNumber("timeout_seconds",
+			mcp.Description("最长等待时间（秒），默认300，最大1800"),
+			mcp.DefaultNumber(300),
+		),
+	), k8s.RolloutStatusTool)
+
+	// 添加修改工作负载Pod模板的工具，以策略合并补丁提交并记录变更原因
+	k8sSvr.AddTool(mcp.NewTool("set_image",
+		mcp.WithDescription("更新Deployment、StatefulSet或DaemonSet中容器的镜像，等同于kubectl set image，会触发滚动更新"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithObject("images",
+			mcp.Required(),
+			mcp.Description("容器名到新镜像的映射，例如 {\"nginx\": \"nginx:1.25\"}，容器名为*时更新全部容器"),
+		),
+		k8s.WithChangeCauseArgument(),
+		k8s.WithDryRunArgument(),
+	), k8s.SetImageTool)
+	k8sSvr.AddTool(mcp.NewTool("set_resources",
+		mcp.WithDescription("更新Deployment、StatefulSet或DaemonSet中容器的资源请求和限制，等同于kubectl set resources，会触发滚动更新"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithString("container",
+			mcp.Description("要修改的容器名称，不提供则修改全部容器"),
+		),
+		mcp.WithObject("requests",
+			mcp.Description("资源请求，例如 {\"cpu\": \"250m\", \"memory\": \"256Mi\"}，值为空字符串时删除该项"),
+		),
+		mcp.WithObject("limits",
+			mcp.Description("资源限制，例如 {\"cpu\": \"1\", \"memory\": \"512Mi\"}，值为空字符串时删除该项"),
+		),
+		k8s.WithChangeCauseArgument(),
+		k8s.WithDryRunArgument(),
+	), k8s.SetResourcesTool)
+	k8sSvr.AddTool(mcp.NewTool("set_env",
+		mcp.WithDescription("设置或删除Deployment、StatefulSet或DaemonSet中容器的环境变量，等同于kubectl set env，会触发滚动更新"),
+		k8s.WithWorkloadArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("工作负载所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithString("container",
+			mcp.Description("要修改的容器名称，不提供则修改全部容器"),
+		),
+		mcp.WithObject("env",
+			mcp.Description("要设置的环境变量，例如 {\"LOG_LEVEL\": \"debug\"}，已存在的变量会被覆盖"),
+		),
+		mcp.WithString("remove",
+			mcp.Description("要删除的环境变量名，多个用逗号分隔"),
+		),
+		k8s.WithChangeCauseArgument(),
+		k8s.WithDryRunArgument(),
+	), k8s.SetEnvTool)
+
+	// 添加通用资源工具，基于动态客户端和发现接口，支持任意资源类型（包括CRD）
+	k8sSvr.AddTool(mcp.NewTool("get_resource",
+		mcp.WithDescription("获取任意资源对象的完整定义（包括CRD，例如Argo Rollout、Istio VirtualService、cert-manager Certificate），Secret的值会被隐藏"),
+		k8s.WithResourceArguments(),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("资源对象名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("对象所在的命名空间, 默认为default，集群级资源忽略该参数"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithString("output",
+			mcp.Description("输出格式: yaml（默认）或 json"),
+			mcp.Enum(k8s.OutputYAML, k8s.OutputJSON),
+			mcp.DefaultString(k8s.OutputYAML),
+		),
+	), k8s.GetResourceTool)
+	k8sSvr.AddTool(mcp.NewTool("list_resources",
+		mcp.WithDescription("列出任意资源类型（包括CRD）在指定命名空间或所有命名空间中的对象，支持标签/字段选择器和分页"),
+		k8s.WithResourceArguments(),
+		mcp.WithString("namespace",
+			mcp.Description("要查询的命名空间, 默认为default，集群级资源忽略该参数"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithAllNamespacesArgument(),
+		k8s.WithListArguments(),
+		k8s.WithOutputArgument(),
+	), k8s.ListResourcesTool)
+	k8sSvr.AddTool(mcp.NewTool("describe_resource",
+		mcp.WithDescription("查看任意资源对象（包括CRD）的详细信息和相关事件，Secret的值会被隐藏"),
+		k8s.WithResourceArguments(),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("资源对象名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("对象所在的命名空间, 默认为default，集群级资源忽略该参数"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithOutputArgument(),
+	), k8s.DescribeResourceTool)
+	k8sSvr.AddTool(mcp.NewTool("delete_resource",
+		mcp.WithDescription("删除任意资源类型（包括CRD）的指定对象"),
+		k8s.WithResourceArguments(),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("要删除的资源对象名称"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("对象所在的命名空间, 默认为default，集群级资源忽略该参数"),
+			mcp.DefaultString("default"),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.DeleteResourceTool)
+	k8sSvr.AddTool(mcp.NewTool("apply_manifest",
+		mcp.WithDescription("以服务端应用（server-side apply）方式提交YAML或JSON清单，支持多文档和任意资源类型，返回每个对象的created/configured/unchanged结果"),
+		mcp.WithString("manifest",
+			mcp.Required(),
+			mcp.Description("要应用的清单，多个YAML文档用---分隔，也可以是JSON或kind为List的对象"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("清单中未指定命名空间的对象使用的命名空间, 默认为default；对象指定的命名空间必须与此一致"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithBoolean("force_conflicts",
+			mcp.Description("字段由其他管理器（如kubectl、Helm、控制器）持有时是否强制接管，默认false，冲突时返回错误"),
+			mcp.DefaultBool(false),
+		),
+		k8s.WithDryRunArgument(),
+	), k8s.ApplyManifestTool)
+	k8sSvr.AddTool(mcp.NewTool("diff_manifest",
+		mcp.WithDescription("比较YAML或JSON清单与集群中现有对象的差异，以服务端应用演练计算应用后的对象，去掉managedFields和status后返回统一格式差异，不修改集群。适合在update_configmap、update_ingress或apply_manifest之前确认变更"),
+		mcp.WithString("manifest",
+			mcp.Required(),
+			mcp.Description("要比较的清单，多个YAML文档用---分隔，也可以是JSON或kind为List的对象"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("清单中未指定命名空间的对象使用的命名空间, 默认为default；对象指定的命名空间必须与此一致"),
+			mcp.DefaultString("default"),
+		),
+		mcp.WithBoolean("force_conflicts",
+			mcp.Description("按强制接管其他管理器持有的字段计算差异，默认false，冲突时返回错误"),
+			mcp.DefaultBool(false),
+		),
+	), k8s.DiffManifestTool)
+
 	// 添加Kubernetes故障诊断工具
-	svr.AddTool(mcp.NewTool("cluster_health",
+	k8sSvr.AddTool(mcp.NewTool("cluster_health",
 		mcp.WithDescription("获取集群健康状态概览"),
 	), k8s.ClusterHealthTool)
 
-	svr.AddTool(mcp.NewTool("pod_diagnostic",
+	k8sSvr.AddTool(mcp.NewTool("pod_diagnostic",
 		mcp.WithDescription("诊断Pod问题"),
 		mcp.WithString("pod_name",
 			mcp.Required(),
@@ -582,7 +1047,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), k8s.PodDiagnosticTool)
 
-	svr.AddTool(mcp.NewTool("node_diagnostic",
+	k8sSvr.AddTool(mcp.NewTool("node_diagnostic",
 		mcp.WithDescription("诊断节点问题"),
 		mcp.WithString("node_name",
 			mcp.Required(),
@@ -590,7 +1055,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), k8s.NodeDiagnosticTool)
 
-	svr.AddTool(mcp.NewTool("deployment_diagnostic",
+	k8sSvr.AddTool(mcp.NewTool("deployment_diagnostic",
 		mcp.WithDescription("诊断Deployment问题"),
 		mcp.WithString("deployment_name",
 			mcp.Required(),
@@ -602,7 +1067,21 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), k8s.DeploymentDiagnosticTool)
 
-	svr.AddTool(mcp.NewTool("alert_analysis",
+	k8sSvr.AddTool(mcp.NewTool("job_diagnostic",
+		mcp.WithDescription("诊断Job或CronJob问题：失败的Pod及退出原因、是否达到backoffLimit、CronJob最近的调度和成功时间；只提供cronjob_name时诊断其最近一次创建的Job"),
+		mcp.WithString("job_name",
+			mcp.Description("要诊断的Job名称，与cronjob_name至少提供一个"),
+		),
+		mcp.WithString("cronjob_name",
+			mcp.Description("要诊断的CronJob名称，与job_name至少提供一个"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Job或CronJob所在的命名空间, 默认为default"),
+			mcp.DefaultString("default"),
+		),
+	), k8s.JobDiagnosticTool)
+
+	k8sSvr.AddTool(mcp.NewTool("alert_analysis",
 		mcp.WithDescription("分析告警信息"),
 		mcp.WithString("alert_name",
 			mcp.Description("告警名称"),
@@ -628,14 +1107,14 @@ func K8sServer() (*server.MCPServer, error) {
 	), k8s.AlertAnalysisTool)
 
 	// 添加Linux系统工具
-	svr.AddTool(mcp.NewTool("system_info",
+	toolSvr.AddTool(mcp.NewTool("system_info",
 		mcp.WithDescription("获取系统信息"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
 		),
 	), linux.SystemInfoTool)
 
-	svr.AddTool(mcp.NewTool("process_info",
+	toolSvr.AddTool(mcp.NewTool("process_info",
 		mcp.WithDescription("获取进程信息"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -649,7 +1128,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.ProcessInfoTool)
 
-	svr.AddTool(mcp.NewTool("resource_usage",
+	toolSvr.AddTool(mcp.NewTool("resource_usage",
 		mcp.WithDescription("获取资源使用情况"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -660,7 +1139,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.ResourceUsageTool)
 
-	svr.AddTool(mcp.NewTool("network_info",
+	toolSvr.AddTool(mcp.NewTool("network_info",
 		mcp.WithDescription("获取网络信息"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -670,7 +1149,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.NetworkInfoTool)
 
-	svr.AddTool(mcp.NewTool("log_analysis",
+	toolSvr.AddTool(mcp.NewTool("log_analysis",
 		mcp.WithDescription("分析日志文件"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -688,7 +1167,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.LogAnalysisTool)
 
-	svr.AddTool(mcp.NewTool("service_status",
+	toolSvr.AddTool(mcp.NewTool("service_status",
 		mcp.WithDescription("获取服务状态"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -699,14 +1178,14 @@ func K8sServer() (*server.MCPServer, error) {
 	), linux.ServiceStatusTool)
 
 	// 添加Kubernetes特定的Linux工具
-	svr.AddTool(mcp.NewTool("kubelet_status",
+	toolSvr.AddTool(mcp.NewTool("kubelet_status",
 		mcp.WithDescription("获取kubelet状态"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
 		),
 	), linux.KubeletStatusTool)
 
-	svr.AddTool(mcp.NewTool("container_runtime_status",
+	toolSvr.AddTool(mcp.NewTool("container_runtime_status",
 		mcp.WithDescription("获取容器运行时状态"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -717,14 +1196,14 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.ContainerRuntimeStatusTool)
 
-	svr.AddTool(mcp.NewTool("kube_proxy_status",
+	toolSvr.AddTool(mcp.NewTool("kube_proxy_status",
 		mcp.WithDescription("获取kube-proxy状态"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
 		),
 	), linux.KubeProxyStatusTool)
 
-	svr.AddTool(mcp.NewTool("node_network_debug",
+	toolSvr.AddTool(mcp.NewTool("node_network_debug",
 		mcp.WithDescription("节点网络调试"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -738,7 +1217,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.NodeNetworkDebugTool)
 
-	svr.AddTool(mcp.NewTool("cni_status",
+	toolSvr.AddTool(mcp.NewTool("cni_status",
 		mcp.WithDescription("获取CNI状态"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -748,7 +1227,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.CNIStatusTool)
 
-	svr.AddTool(mcp.NewTool("kube_component_logs",
+	toolSvr.AddTool(mcp.NewTool("kube_component_logs",
 		mcp.WithDescription("获取Kubernetes组件日志"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -763,7 +1242,7 @@ func K8sServer() (*server.MCPServer, error) {
 		),
 	), linux.KubeComponentLogsTool)
 
-	svr.AddTool(mcp.NewTool("container_inspect",
+	toolSvr.AddTool(mcp.NewTool("container_inspect",
 		mcp.WithDescription("检查容器详情"),
 		mcp.WithString("hostname",
 			mcp.Description("要查询的主机名，如不提供则查询本地系统"),
@@ -779,7 +1258,7 @@ func K8sServer() (*server.MCPServer, error) {
 	), linux.ContainerInspectTool)
 
 	// 添加企业微信消息发送工具
-	svr.AddTool(mcp.NewTool("send_wechat_message",
+	toolSvr.AddTool(mcp.NewTool("send_wechat_message",
 		mcp.WithDescription("发送企业微信消息通知"),
 		mcp.WithString("content",
 			mcp.Required(),
@@ -792,8 +1271,11 @@ func K8sServer() (*server.MCPServer, error) {
 		mcp.WithString("title",
 			mcp.Description("消息标题，用于markdown和template_card类型"),
 		),
+		mcp.WithString("channel",
+			mcp.Description("通知渠道名称，对应配置文件中notifiers.wechat的name，默认为第一个渠道"),
+		),
 		mcp.WithString("webhook_url",
-			mcp.Description("企业微信机器人Webhook地址，如不提供则使用WECHAT_WEBHOOK_URL环境变量"),
+			mcp.Description("企业微信机器人Webhook地址，仅在服务器未配置任何通知渠道时使用"),
 		),
 		mcp.WithString("card_type",
 			mcp.Description("卡片类型，用于template_card类型消息，默认为text_notice"),
@@ -802,9 +1284,43 @@ func K8sServer() (*server.MCPServer, error) {
 	), k8s.SendWeChatMessageTool)
 
 	// 添加Redis工具
-	redis.AddRedisTools(svr)
+	if groups.groupEnabled(config.GroupRedis) {
+		redis.AddRedisTools(svr)
+	}
 
 	// 添加Loki工具
-	loki.AddLokiTools(svr)
+	if groups.groupEnabled(config.GroupLoki) {
+		loki.AddLokiTools(svr)
+	}
+
+	// 添加审计日志查询工具
+	if auditLog != nil {
+		svr.AddTool(mcp.NewTool("query_audit_log",
+			mcp.WithDescription("查询MCP服务器的工具调用审计日志，用于还原AI在某段时间内执行了哪些操作"),
+			mcp.WithString("tool",
+				mcp.Description("工具名称，支持通配符，例如 delete_*"),
+			),
+			mcp.WithString("session",
+				mcp.Description("客户端会话ID"),
+			),
+			mcp.WithString("contains",
+				mcp.Description("参数中包含的文本，例如资源名称或命名空间"),
+			),
+			mcp.WithString("since",
+				mcp.Description("起始时间，RFC3339格式（如 2024-01-02T15:04:05+08:00）或时长（如 2h 表示两小时前）"),
+			),
+			mcp.WithString("until",
+				mcp.Description("截止时间，格式同since"),
+			),
+			mcp.WithBoolean("errors_only",
+				mcp.Description("是否只返回失败的调用"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("最多返回的记录数，默认50，最大500"),
+				mcp.DefaultNumber(50),
+			),
+		), auditLog.QueryTool)
+	}
 	return svr, nil
 }
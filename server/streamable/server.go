@@ -0,0 +1,240 @@
+package streamable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// 协议相关常量
+const (
+	DefaultEndpoint = "/mcp"           // 默认的MCP端点路径
+	SessionHeader   = "Mcp-Session-Id" // 保存会话ID的HTTP头
+
+	defaultIdleTimeout = time.Hour // 会话默认空闲超时时间
+	maxBodySize        = 4 << 20   // 单个请求体的最大字节数
+	notificationBuffer = 100       // 每个会话缓存的通知个数
+)
+
+// session 一个streamable HTTP客户端会话
+// 每次调用都是独立的POST请求，不依赖长连接，代理超时不会中断会话
+type session struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+	lastSeen      atomic.Int64
+}
+
+// SessionID 返回会话ID
+func (s *session) SessionID() string {
+	return s.id
+}
+
+// NotificationChannel 返回通知通道，服务器不提供GET流，缓冲区满后通知被丢弃
+func (s *session) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// Initialize 标记会话已完成初始化
+func (s *session) Initialize() {
+	s.initialized.Store(true)
+}
+
+// Initialized 会话是否已完成初始化
+func (s *session) Initialized() bool {
+	return s.initialized.Load()
+}
+
+// touch 记录会话最近一次使用的时间
+func (s *session) touch() {
+	s.lastSeen.Store(time.Now().UnixNano())
+}
+
+// Option Server的配置选项
+type Option func(*Server)
+
+// WithIdleTimeout 设置会话空闲超时时间，超时的会话被清理，客户端需重新初始化
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.idleTimeout = timeout
+		}
+	}
+}
+
+// Server 实现MCP streamable HTTP传输的HTTP处理器
+// POST 发送JSON-RPC消息并直接在响应体中返回结果，DELETE 结束会话
+type Server struct {
+	server      *server.MCPServer
+	idleTimeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewServer 创建streamable HTTP服务器
+func NewServer(svr *server.MCPServer, opts ...Option) *Server {
+	s := &Server{
+		server:      svr,
+		idleTimeout: defaultIdleTimeout,
+		sessions:    make(map[string]*session),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeHTTP 按请求方法分发
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.handlePost(w, r)
+	case http.MethodDelete:
+		s.handleDelete(w, r)
+	default:
+		// 不提供服务端推送的GET流
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+	}
+}
+
+// rpcMessage 用于识别JSON-RPC消息的方法
+type rpcMessage struct {
+	Method string `json:"method"`
+}
+
+// handlePost 处理客户端发送的JSON-RPC消息
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "读取请求体失败", http.StatusBadRequest)
+		return
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		http.Error(w, "不支持JSON-RPC批量请求", http.StatusBadRequest)
+		return
+	}
+	var message rpcMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		writeJSON(w, http.StatusBadRequest, mcp.NewJSONRPCError(nil, mcp.PARSE_ERROR, "解析JSON-RPC消息失败", nil))
+		return
+	}
+
+	var sess *session
+	if message.Method == string(mcp.MethodInitialize) {
+		sess, err = s.newSession(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("创建会话失败: %v", err), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		id := r.Header.Get(SessionHeader)
+		if id == "" {
+			http.Error(w, "缺少 "+SessionHeader+" 请求头，请先发送initialize请求", http.StatusBadRequest)
+			return
+		}
+		var ok bool
+		if sess, ok = s.session(id); !ok {
+			// 会话已过期或服务器已重启，客户端收到404后重新初始化
+			http.Error(w, "会话不存在或已过期，请重新初始化", http.StatusNotFound)
+			return
+		}
+	}
+	sess.touch()
+
+	ctx := s.server.WithContext(r.Context(), sess)
+	response := s.server.HandleMessage(ctx, body)
+	w.Header().Set(SessionHeader, sess.id)
+	if response == nil {
+		// 通知和响应消息没有返回值
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleDelete 客户端主动结束会话
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		http.Error(w, "缺少 "+SessionHeader+" 请求头", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "会话不存在或已过期", http.StatusNotFound)
+		return
+	}
+	s.server.UnregisterSession(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newSession 为initialize请求创建并注册新会话，同时清理空闲超时的会话
+func (s *Server) newSession(r *http.Request) (*session, error) {
+	s.removeIdleSessions()
+
+	sess := &session{
+		id:            uuid.New().String(),
+		notifications: make(chan mcp.JSONRPCNotification, notificationBuffer),
+	}
+	sess.touch()
+	if err := s.server.RegisterSession(r.Context(), sess); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+	return sess, nil
+}
+
+// session 查找会话
+func (s *Server) session(id string) (*session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	return sess, ok
+}
+
+// removeIdleSessions 清理超过空闲时间的会话
+func (s *Server) removeIdleSessions() {
+	deadline := time.Now().Add(-s.idleTimeout).UnixNano()
+
+	s.mu.Lock()
+	var expired []string
+	for id, sess := range s.sessions {
+		if sess.lastSeen.Load() < deadline {
+			expired = append(expired, id)
+			delete(s.sessions, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range expired {
+		s.server.UnregisterSession(id)
+	}
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Println("写入streamable HTTP响应失败:", err)
+	}
+}
@@ -0,0 +1,149 @@
+package streamable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const initializeBody = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+
+// newTestServer 创建注册了一个echo工具的streamable HTTP服务器
+func newTestServer(t *testing.T, opts ...Option) (*Server, *httptest.Server) {
+	t.Helper()
+	svr := server.NewMCPServer("test", "1.0")
+	svr.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text, _ := request.Params.Arguments["text"].(string)
+		return mcp.NewToolResultText(text), nil
+	})
+	s := NewServer(svr, opts...)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// do 发送请求，sessionID为空时不携带会话头
+func do(t *testing.T, method, url, sessionID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(SessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// initialize 建立会话并返回会话ID
+func initialize(t *testing.T, url string) string {
+	t.Helper()
+	resp := do(t, http.MethodPost, url, "", initializeBody)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("initialize 状态码 = %d", resp.StatusCode)
+	}
+	id := resp.Header.Get(SessionHeader)
+	if id == "" {
+		t.Fatal("initialize 响应缺少会话ID")
+	}
+	do(t, http.MethodPost, url, id, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	return id
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	first := initialize(t, ts.URL)
+	if second := initialize(t, ts.URL); second == first {
+		t.Error("每次initialize应该分配新的会话ID")
+	}
+
+	resp := do(t, http.MethodPost, ts.URL, first, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("tools/call 状态码 = %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get(SessionHeader); got != first {
+		t.Errorf("响应的会话ID = %q, want %q", got, first)
+	}
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	result, err := mcp.ParseCallToolResult(&response.Result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, ok := mcp.AsTextContent(result.Content[0]); !ok || text.Text != "hi" {
+		t.Errorf("tools/call 结果 = %s", response.Result)
+	}
+
+	if resp := do(t, http.MethodDelete, ts.URL, first, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE 状态码 = %d, want 204", resp.StatusCode)
+	}
+	if resp := do(t, http.MethodPost, ts.URL, first, `{"jsonrpc":"2.0","id":3,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("删除后的会话状态码 = %d, want 404", resp.StatusCode)
+	}
+	if resp := do(t, http.MethodDelete, ts.URL, first, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("重复DELETE状态码 = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	tests := []struct {
+		name      string
+		method    string
+		sessionID string
+		body      string
+		want      int
+	}{
+		{"未知会话", http.MethodPost, "unknown", `{"jsonrpc":"2.0","id":1,"method":"ping"}`, http.StatusNotFound},
+		{"缺少会话头", http.MethodPost, "", `{"jsonrpc":"2.0","id":1,"method":"ping"}`, http.StatusBadRequest},
+		{"批量请求", http.MethodPost, "", `[` + initializeBody + `]`, http.StatusBadRequest},
+		{"无效JSON", http.MethodPost, "", `{`, http.StatusBadRequest},
+		{"DELETE缺少会话头", http.MethodDelete, "", "", http.StatusBadRequest},
+		{"不支持GET", http.MethodGet, "", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := do(t, tt.method, ts.URL, tt.sessionID, tt.body); resp.StatusCode != tt.want {
+				t.Errorf("状态码 = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdleSessionsAreRemoved(t *testing.T) {
+	s, ts := newTestServer(t, WithIdleTimeout(50*time.Millisecond))
+
+	idle := initialize(t, ts.URL)
+	time.Sleep(100 * time.Millisecond)
+
+	// 新会话初始化时清理空闲超时的会话
+	active := initialize(t, ts.URL)
+	if _, ok := s.session(idle); ok {
+		t.Error("空闲超时的会话应该被清理")
+	}
+	if resp := do(t, http.MethodPost, ts.URL, idle, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("过期会话状态码 = %d, want 404", resp.StatusCode)
+	}
+	if resp := do(t, http.MethodPost, ts.URL, active, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("活跃会话状态码 = %d, want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,198 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 服务器创建的span所属的instrumentation名称
+const instrumentationName = "mcp-devops/server"
+
+// maxMessageSize 提取追踪上下文时读取的最大请求体字节数，与streamable HTTP端点的限制一致
+const maxMessageSize = 4 << 20
+
+// Enabled 是否配置了OTLP导出地址，未配置时不创建导出器，span全部为空操作
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup 按OTEL_*环境变量创建OTLP/HTTP导出器并注册全局TracerProvider，返回的函数在退出时刷新并关闭导出器
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	if !Enabled() {
+		// 传播器始终注册，未启用导出时也能把上游的追踪上下文透传给下游
+		otel.SetTextMapPropagator(propagator())
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("创建OTLP导出器失败: %v", err)
+	}
+	return SetupWithExporter(ctx, serviceName, exporter)
+}
+
+// SetupWithExporter 使用指定的导出器注册全局TracerProvider，测试时可传入内存导出器
+func SetupWithExporter(ctx context.Context, serviceName string, exporter sdktrace.SpanExporter) (func(context.Context) error, error) {
+	// OTEL_SERVICE_NAME 和 OTEL_RESOURCE_ATTRIBUTES 优先于默认的服务名
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建追踪资源失败: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator())
+	return provider.Shutdown, nil
+}
+
+// propagator W3C trace context和baggage传播器
+func propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Start 创建子span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartClient 创建调用外部系统的子span
+func StartClient(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// End 记录错误并结束span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Middleware 为每次工具调用创建服务端span，父span来自客户端在请求中携带的追踪上下文
+func Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := otel.Tracer(instrumentationName).Start(ctx, "mcp.tool "+request.Params.Name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("mcp.tool", request.Params.Name)),
+		)
+		defer span.End()
+
+		result, err := next(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if result != nil && result.IsError {
+			span.SetStatus(codes.Error, "工具返回错误结果")
+		}
+		return result, err
+	}
+}
+
+// rpcMeta 只解析JSON-RPC请求中的 params._meta
+type rpcMeta struct {
+	Params struct {
+		Meta map[string]interface{} `json:"_meta"`
+	} `json:"params"`
+}
+
+// HTTPMiddleware 从HTTP头或JSON-RPC请求的 params._meta 中提取W3C追踪上下文，_meta优先
+// SSE和streamable HTTP端点都用请求的上下文处理消息，提取的上下文会传递到工具处理函数
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		if r.Method == http.MethodPost && r.Body != nil {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, "读取请求体失败", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var message rpcMeta
+			if json.Unmarshal(body, &message) == nil && len(message.Params.Meta) > 0 {
+				ctx = propagator.Extract(ctx, metaCarrier(message.Params.Meta))
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// metaCarrier 把 params._meta 适配为TextMapCarrier，只读取字符串值
+type metaCarrier map[string]interface{}
+
+// Get 返回键对应的值
+func (c metaCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+// Set 设置键值
+func (c metaCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys 返回所有键
+func (c metaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// KubernetesTransport 返回为每个Kubernetes API请求创建子span的RoundTripper包装函数，用于rest.Config.Wrap
+func KubernetesTransport(cluster string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := StartClient(req.Context(), "kubernetes "+req.Method,
+				attribute.String("k8s.cluster", cluster),
+				attribute.String("http.request.method", req.Method),
+				attribute.String("url.path", req.URL.Path),
+			)
+			resp, err := rt.RoundTrip(req.WithContext(ctx))
+			if err == nil {
+				span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+				if resp.StatusCode >= 500 {
+					span.SetStatus(codes.Error, resp.Status)
+				}
+			}
+			End(span, err)
+			return resp, err
+		})
+	}
+}
+
+// roundTripperFunc 把函数适配为http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip 执行请求
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	headerTraceID = "0af7651916cd43dd8448eb211c80319c"
+	metaTraceID   = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+// setupInMemory 注册写入内存导出器的TracerProvider，测试结束后恢复全局设置
+func setupInMemory(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := SetupWithExporter(context.Background(), "mcp-devops-test", exporter)
+	if err != nil {
+		t.Fatalf("SetupWithExporter: %v", err)
+	}
+	t.Cleanup(func() { shutdown(context.Background()) })
+	return exporter
+}
+
+// newTracedHandler 创建工具调用经过Middleware、HTTP请求经过HTTPMiddleware的处理器
+// get_pod 工具通过KubernetesTransport向apiServer发送一次请求
+func newTracedHandler(apiServer string) http.Handler {
+	svr := server.NewMCPServer("test", "1.0", server.WithToolHandlerMiddleware(Middleware))
+	client := &http.Client{Transport: KubernetesTransport("prod")(http.DefaultTransport)}
+	svr.AddTool(mcp.NewTool("get_pod"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiServer+"/api/v1/namespaces/default/pods/web", nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	return HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(svr.HandleMessage(r.Context(), body))
+	}))
+}
+
+func TestToolCallSpans(t *testing.T) {
+	tests := []struct {
+		name        string
+		meta        string
+		wantTraceID string
+	}{
+		{"使用HTTP头中的追踪上下文", "", headerTraceID},
+		{"_meta优先于HTTP头", `,"_meta":{"traceparent":"00-` + metaTraceID + `-00f067aa0ba902b7-01"}`, metaTraceID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := setupInMemory(t)
+
+			apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer apiServer.Close()
+
+			body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_pod","arguments":{}` + tt.meta + `}}`
+			req := httptest.NewRequest(http.MethodPost, "/message", strings.NewReader(body))
+			req.Header.Set("traceparent", "00-"+headerTraceID+"-b7ad6b7169203331-01")
+			rec := httptest.NewRecorder()
+			newTracedHandler(apiServer.URL).ServeHTTP(rec, req)
+			if !strings.Contains(rec.Body.String(), `"ok"`) {
+				t.Fatalf("工具调用结果 = %s", rec.Body.String())
+			}
+
+			// 内存导出器关闭时会清空span，先刷新批量导出器再读取
+			if err := otel.GetTracerProvider().(*sdktrace.TracerProvider).ForceFlush(context.Background()); err != nil {
+				t.Fatalf("ForceFlush: %v", err)
+			}
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("span数量 = %d, want 2", len(spans))
+			}
+			// 子span先结束，因此先导出
+			kube, tool := spans[0], spans[1]
+
+			if tool.Name != "mcp.tool get_pod" || tool.SpanKind.String() != "server" {
+				t.Errorf("工具span = %s (%s)", tool.Name, tool.SpanKind)
+			}
+			if got := tool.SpanContext.TraceID().String(); got != tt.wantTraceID {
+				t.Errorf("工具span的TraceID = %s, want %s", got, tt.wantTraceID)
+			}
+			if !tool.Parent.IsRemote() || tool.Parent.TraceID().String() != tt.wantTraceID {
+				t.Errorf("工具span的父span应该来自客户端: %+v", tool.Parent)
+			}
+			if !hasAttribute(tool.Attributes, attribute.String("mcp.tool", "get_pod")) {
+				t.Errorf("工具span属性 = %v", tool.Attributes)
+			}
+			if !hasAttribute(tool.Resource.Attributes(), attribute.String("service.name", "mcp-devops-test")) {
+				t.Errorf("资源属性 = %v", tool.Resource.Attributes())
+			}
+
+			if kube.Name != "kubernetes GET" || kube.SpanKind.String() != "client" {
+				t.Errorf("Kubernetes span = %s (%s)", kube.Name, kube.SpanKind)
+			}
+			if kube.Parent.SpanID() != tool.SpanContext.SpanID() || kube.SpanContext.TraceID() != tool.SpanContext.TraceID() {
+				t.Error("Kubernetes span应该是工具span的子span")
+			}
+			for _, want := range []attribute.KeyValue{
+				attribute.String("k8s.cluster", "prod"),
+				attribute.String("http.request.method", http.MethodGet),
+				attribute.String("url.path", "/api/v1/namespaces/default/pods/web"),
+				attribute.Int("http.response.status_code", http.StatusOK),
+			} {
+				if !hasAttribute(kube.Attributes, want) {
+					t.Errorf("Kubernetes span缺少属性 %s=%s: %v", want.Key, want.Value.Emit(), kube.Attributes)
+				}
+			}
+		})
+	}
+}
+
+// hasAttribute attrs中是否包含want
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}